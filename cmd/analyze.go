@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/northcutted/dock-docs/pkg/config"
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/results"
+)
+
+var analyzeOut string
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze the images in dock-docs.yaml and save the results",
+	Long: `Runs the image analysis for every image and comparison section in the
+config file and saves the raw results as JSON, without rendering any
+documentation.
+
+Render the documentation later (for example in a job without access to a
+container runtime) with:
+
+  dock-docs --from-results results.json
+
+Suppressions, policies, drift detection, the framework catalogue and the
+lifecycle dataset are applied at render time, so they can be changed
+without re-analyzing.`,
+	Example: `  # Analyze and save results
+  dock-docs analyze --out results.json
+
+  # Render from the saved results
+  dock-docs --from-results results.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if analysisTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, analysisTimeout)
+			defer cancel()
+		}
+
+		cfgPath := configFile
+		if cfgPath == "" {
+			cfgPath = "dock-docs.yaml"
+		}
+		return runAnalyze(ctx, cfgPath, analyzeOut)
+	},
+}
+
+func init() {
+	analyzeCmd.Flags().StringVarP(&analyzeOut, "out", "o", "dock-docs-results.json", "Path to the results file")
+	analyzeCmd.Flags().StringVar(&configFile, "config", "", "Path to config file (default: dock-docs.yaml)")
+	analyzeCmd.Flags().BoolVar(&ignoreErrors, "ignore-errors", false, "Save partial results when an image fails to analyze")
+	analyzeCmd.Flags().BoolVar(&perPlatform, "per-platform", false, "Analyze every platform of a multi-arch image separately")
+	analyzeCmd.Flags().BoolVar(&compareBase, "compare-base", false, "Also analyze the base image of the final Dockerfile stage")
+	analyzeCmd.Flags().StringVar(&pullPolicy, "pull", "", "Image pull policy: always, if-not-present or never (default: runtime.pull, or if-not-present)")
+	analyzeCmd.Flags().StringVar(&pullPlatform, "platform", "", "Platform to pull images for, e.g. linux/arm64 (default: runtime.platform)")
+	analyzeCmd.Flags().BoolVar(&noCache, "no-cache", false, "Always re-run analysis tools instead of using cached results")
+	analyzeCmd.Flags().DurationVar(&analysisTimeout, "timeout", 10*time.Minute, "Overall timeout for all analysis operations (e.g. 5m, 30s)")
+
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+// runAnalyze analyzes every image referenced by the config at path and
+// writes the results to out. Images shared by several sections are analyzed
+// once.
+func runAnalyze(ctx context.Context, path, out string) error {
+	slog.Info("using config file", "path", path)
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	cfg.ResolveRelativePaths(filepath.Dir(path))
+
+	run, err := newYAMLRun(ctx, cfg, nil)
+	if err != nil {
+		return err
+	}
+	defer run.release()
+
+	saved := results.New()
+	for _, section := range cfg.Sections {
+		switch section.Type {
+		case config.SectionTypeImage:
+			// Sections with reports are imported at render time.
+			if section.Tag == "" || len(section.Reports) > 0 {
+				continue
+			}
+			if saved.Images[section.Tag] == nil {
+				stats, err := run.analyzeImage(ctx, section)
+				if err != nil {
+					return err
+				}
+				if stats != nil {
+					saved.Add(section.Tag, stats)
+				}
+			}
+			if err := run.saveBase(ctx, section, saved); err != nil {
+				return err
+			}
+
+		case config.SectionTypeComparison:
+			var entries []config.ImageEntry
+			for _, entry := range section.ResolvedImages() {
+				if len(entry.Reports) == 0 && saved.Images[entry.Tag] == nil {
+					entries = append(entries, entry)
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			statsList, err := run.analyzeComparison(ctx, section, entries)
+			if err != nil {
+				return err
+			}
+			for _, stats := range statsList {
+				saved.Add(stats.ImageTag, stats)
+			}
+		}
+	}
+
+	if err := saved.Write(out); err != nil {
+		return err
+	}
+	slog.Info("wrote analysis results", "path", out, "images", len(saved.Images))
+	fmt.Fprintf(stdout, "Saved results for %d image(s) to %s\n", len(saved.Images), out)
+	return nil
+}
+
+// saveBase adds the analysis of the base image of an image section to saved,
+// when base image comparison is enabled for the section, so the comparison
+// can be rendered from the results.
+func (r *yamlRun) saveBase(ctx context.Context, section config.Section, saved *results.File) error {
+	var doc *parser.Documentation
+	if section.BaseImage == "" && (compareBase || section.CompareBase) {
+		dPath := section.Source
+		if dPath == "" {
+			dPath = "Dockerfile"
+		}
+		var err error
+		if doc, err = parser.Parse(dPath); err != nil {
+			return fmt.Errorf("failed to parse Dockerfile %s: %w", dPath, err)
+		}
+	}
+	ref := baseRef(section, doc)
+	if ref == "" || saved.Images[ref] != nil {
+		return nil
+	}
+	stats, err := r.analyzeImage(ctx, baseSection(section, ref))
+	if err != nil {
+		return fmt.Errorf("base image %s: %w", ref, err)
+	}
+	if stats != nil {
+		saved.Add(ref, stats)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Test file for the analyze command (runAnalyze) and rendering from saved
+// results (--from-results).
+//
+// Globals mutated: dryRun, noMoji, ignoreErrors, fromResults, imageTag,
+// dockerfile, compareBase, verbose, stdout (via captureOutput).
+// All tests use defer resetFlags()() for cleanup.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/config"
+	"github.com/northcutted/dock-docs/pkg/results"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// writeResults saves stats for the given images to a results file in dir.
+func writeResults(t *testing.T, dir string, stats ...*types.ImageStats) string {
+	t.Helper()
+	f := results.New()
+	for _, s := range stats {
+		f.Add(s.ImageTag, s)
+	}
+	path := filepath.Join(dir, "results.json")
+	if err := f.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAnalyzeCmd_Verbose(t *testing.T) {
+	defer resetFlags()()
+
+	if err := analyzeCmd.ParseFlags([]string{"--verbose", "--log-format", "json"}); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if !verbose {
+		t.Error("--verbose should be accepted by the analyze command")
+	}
+}
+
+func TestRunAnalyze_NoImages(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(cfgPath, []byte("sections:\n  - type: image\n    marker: main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(tmpDir, "results.json")
+
+	output := captureOutput(func() {
+		if err := runAnalyze(context.Background(), cfgPath, out); err != nil {
+			t.Fatalf("runAnalyze() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Saved results for 0 image(s)") {
+		t.Errorf("unexpected output: %q", output)
+	}
+	saved, err := results.Load(out)
+	if err != nil {
+		t.Fatalf("results.Load() error: %v", err)
+	}
+	if len(saved.Images) != 0 {
+		t.Errorf("expected no images, got %d", len(saved.Images))
+	}
+}
+
+func TestRunAnalyze_AnalysisFails(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(cfgPath, []byte("sections:\n  - type: image\n    marker: main\n    tag: fake:1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(tmpDir, "results.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := runAnalyze(ctx, cfgPath, out); err == nil {
+		t.Fatal("expected error when analysis fails")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("results file should not be written when analysis fails")
+	}
+}
+
+func TestRunYAMLMode_FromResults(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	df := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(df, []byte("FROM alpine\nENV SAVED=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir,
+		&types.ImageStats{ImageTag: "app:1", OSDistro: "Alpine Linux 3.20", TotalPackages: 17},
+		&types.ImageStats{ImageTag: "app:2", OSDistro: "Alpine Linux 3.21", TotalPackages: 19},
+	)
+
+	// The runtime settings cannot be applied (there is no docker binary to
+	// resolve the context with), which proves they are not used.
+	t.Setenv("PATH", t.TempDir())
+	yamlContent := fmt.Sprintf(`output: %s
+runtime:
+  context: remote
+sections:
+  - type: image
+    marker: main
+    source: %s
+    tag: app:1
+    template:
+      name: json
+  - type: comparison
+    marker: versions
+    images:
+      - tag: app:1
+      - tag: app:2
+    template:
+      name: json
+`, filepath.Join(tmpDir, "README.md"), df)
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+
+	// A cancelled context proves no analysis is attempted.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output := captureOutput(func() {
+		if err := runYAMLMode(ctx, cfgPath); err != nil {
+			t.Fatalf("runYAMLMode() error: %v", err)
+		}
+	})
+	for _, want := range []string{"Alpine Linux 3.20", "Alpine Linux 3.21", "SAVED"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunYAMLMode_FromResults_MissingImage(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	df := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(df, []byte("FROM alpine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir, &types.ImageStats{ImageTag: "app:1"})
+
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	yamlContent := fmt.Sprintf("sections:\n  - type: image\n    marker: main\n    source: %s\n    tag: other:1\n    template:\n      name: json\n", df)
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+
+	var err error
+	captureOutput(func() { err = runYAMLMode(context.Background(), cfgPath) })
+	if err == nil || !strings.Contains(err.Error(), "no saved results for image other:1") {
+		t.Fatalf("runYAMLMode() error = %v, want missing results error", err)
+	}
+
+	ignoreErrors = true
+	captureOutput(func() { err = runYAMLMode(context.Background(), cfgPath) })
+	if err != nil {
+		t.Errorf("runYAMLMode(--ignore-errors) error = %v", err)
+	}
+}
+
+func TestRunYAMLMode_FromResults_MissingComparisonImage(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	fromResults = writeResults(t, tmpDir, &types.ImageStats{ImageTag: "app:1", OSDistro: "Alpine Linux 3.20"})
+
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	yamlContent := "sections:\n  - type: comparison\n    marker: versions\n    images:\n      - tag: app:1\n      - tag: app:2\n    template:\n      name: json\n"
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+
+	var err error
+	captureOutput(func() { err = runYAMLMode(context.Background(), cfgPath) })
+	if err == nil || !strings.Contains(err.Error(), "no saved results for image app:2") {
+		t.Fatalf("runYAMLMode() error = %v, want missing results error", err)
+	}
+
+	ignoreErrors = true
+	output := captureOutput(func() { err = runYAMLMode(context.Background(), cfgPath) })
+	if err != nil {
+		t.Fatalf("runYAMLMode(--ignore-errors) error = %v", err)
+	}
+	if !strings.Contains(output, "Alpine Linux 3.20") {
+		t.Errorf("expected the saved image in output, got:\n%s", output)
+	}
+}
+
+func TestRunCLIMode_FromResults(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	dockerfile = filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM alpine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir, &types.ImageStats{ImageTag: "app:1", OSDistro: "Alpine Linux 3.20"})
+	imageTag = "app:1"
+	dryRun = true
+
+	output := captureOutput(func() {
+		if err := runCLIMode(context.Background()); err != nil {
+			t.Fatalf("runCLIMode() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Alpine Linux 3.20") {
+		t.Errorf("expected saved distro in output, got:\n%s", output)
+	}
+}
+
+func TestRunYAMLMode_FromResults_CompareBase(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	df := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(df, []byte("FROM golang:1.22 AS build\nFROM alpine:3.19\nCOPY --from=build /app /app"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir,
+		&types.ImageStats{ImageTag: "app:1", SizeBytes: 20 << 20, TotalLayers: 3, Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4", Type: "apk"}, {Name: "app", Version: "1.0.0", Type: "go-module"},
+		}},
+		&types.ImageStats{ImageTag: "alpine:3.19", SizeBytes: 8 << 20, TotalLayers: 1, Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4", Type: "apk"},
+		}},
+	)
+
+	readme := filepath.Join(tmpDir, "README.md")
+	if err := os.WriteFile(readme, []byte("<!-- BEGIN: dock-docs:main -->\n<!-- END: dock-docs:main -->"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	yamlContent := fmt.Sprintf("output: %s\nsections:\n  - type: image\n    marker: main\n    source: %s\n    tag: app:1\n    compareBase: true\n", readme, df)
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+
+	output := captureOutput(func() {
+		if err := runYAMLMode(context.Background(), cfgPath); err != nil {
+			t.Fatalf("runYAMLMode() error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"### Base Image: `alpine:3.19`",
+		"| **Inherited** from base | 8.00 MB | 1 | 1 |",
+		"| **Introduced** by this image | 12.00 MB | 2 | 1 |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunCLIMode_FromResults_CompareBase(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	dockerfile = filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM alpine:3.19"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir, &types.ImageStats{ImageTag: "app:1", SizeBytes: 10 << 20})
+	imageTag = "app:1"
+	compareBase = true
+	dryRun = true
+
+	var err error
+	captureOutput(func() { err = runCLIMode(context.Background()) })
+	if err == nil || !strings.Contains(err.Error(), "base image alpine:3.19") {
+		t.Fatalf("runCLIMode() error = %v, want missing base image error", err)
+	}
+
+	ignoreErrors = true
+	output := captureOutput(func() {
+		if err := runCLIMode(context.Background()); err != nil {
+			t.Fatalf("runCLIMode(--ignore-errors) error: %v", err)
+		}
+	})
+	if strings.Contains(output, "### Base Image") {
+		t.Errorf("expected no base image section without base results, got:\n%s", output)
+	}
+}
+
+func TestRunYAMLMode_FromResults_Lifecycle(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	df := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(df, []byte("FROM alpine:3.15"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromResults = writeResults(t, tmpDir, &types.ImageStats{ImageTag: "app:1", OSDistro: "Acme OS 1.4"})
+
+	// The dataset file adds a distribution unknown to the built-in one.
+	dataset := "- name: Acme OS\n  kind: distro\n  match: [\"Acme OS\"]\n  releases:\n    - {cycle: \"1\", eol: 2024-01-31}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "lifecycle.yaml"), []byte(dataset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readme := filepath.Join(tmpDir, "README.md")
+	if err := os.WriteFile(readme, []byte("<!-- BEGIN: dock-docs:main -->\n<!-- END: dock-docs:main -->"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	yamlContent := fmt.Sprintf("output: %s\nlifecycle:\n  file: lifecycle.yaml\npolicy:\n  denyEOL: true\nsections:\n  - type: image\n    marker: main\n    source: %s\n    tag: app:1\n", readme, df)
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+	noMoji = true
+
+	var err error
+	output := captureOutput(func() { err = runYAMLMode(context.Background(), cfgPath) })
+	if err == nil || !strings.Contains(err.Error(), "end-of-life software: Acme OS 1") {
+		t.Errorf("runYAMLMode() error = %v, want end-of-life policy violation", err)
+	}
+	if want := "> [WARN] **End of life:** Acme OS 1 — EOL since 2024-01-31"; !strings.Contains(output, want) {
+		t.Errorf("expected %q in output, got:\n%s", want, output)
+	}
+}
+
+func TestSaveBase(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	df := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(df, []byte("ARG DEBIAN=12\nFROM debian:${DEBIAN}-slim"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Lookups in saved results stand in for the analysis.
+	available := results.New()
+	available.Add("debian:12-slim", &types.ImageStats{ImageTag: "debian:12-slim"})
+	available.Add("ubuntu:24.04", &types.ImageStats{ImageTag: "ubuntu:24.04"})
+	run := &yamlRun{cfg: &config.Config{}, results: available}
+
+	tests := []struct {
+		name    string
+		section config.Section
+		flag    bool
+		want    string // saved base image; "" for none
+	}{
+		{"disabled", config.Section{Source: df, Tag: "app:1"}, false, ""},
+		{"section setting", config.Section{Source: df, Tag: "app:1", CompareBase: true}, false, "debian:12-slim"},
+		{"flag", config.Section{Source: df, Tag: "app:1"}, true, "debian:12-slim"},
+		{"override", config.Section{Source: df, Tag: "app:1", BaseImage: "ubuntu:24.04"}, false, "ubuntu:24.04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compareBase = tt.flag
+			saved := results.New()
+			if err := run.saveBase(context.Background(), tt.section, saved); err != nil {
+				t.Fatalf("saveBase() error: %v", err)
+			}
+			var got []string
+			for image := range saved.Images {
+				got = append(got, image)
+			}
+			if tt.want == "" && len(got) != 0 || tt.want != "" && (len(got) != 1 || got[0] != tt.want) {
+				t.Errorf("saved images = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/northcutted/dock-docs/pkg/cache"
+	"github.com/northcutted/dock-docs/pkg/config"
+)
+
+var (
+	cacheDir      string
+	cachePruneAll bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local analysis cache",
+	Long: `dock-docs caches the results of syft, grype and dive on disk, keyed by the
+image ID and the version of each tool (plus the grype database version for
+vulnerability scans). Unchanged images are not re-scanned until the cached
+results expire (24h by default, configurable via cache.ttl in dock-docs.yaml).
+
+Use --no-cache on the main command to bypass the cache for a single run.`,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries from the analysis cache",
+	Example: `  # Remove expired entries
+  dock-docs cache prune
+
+  # Remove every cached result
+  dock-docs cache prune --all
+
+  # Prune the cache configured in another config file
+  dock-docs cache prune --config ci/dock-docs.yaml`,
+	RunE: runCachePrune,
+}
+
+func init() {
+	cachePruneCmd.Flags().StringVar(&cacheDir, "dir", "", "Cache directory (default: cache.dir from dock-docs.yaml, or the user cache directory)")
+	cachePruneCmd.Flags().BoolVar(&cachePruneAll, "all", false, "Remove all entries, not only expired ones")
+	cachePruneCmd.Flags().StringVar(&configFile, "config", "", "Path to config file (default: dock-docs.yaml)")
+
+	cacheCmd.AddCommand(cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// loadCacheConfig returns the cache section of the config file at path (or
+// of dock-docs.yaml if path is empty), with a relative cache.dir resolved
+// against the file's directory. It returns nil if path is empty and there is
+// no dock-docs.yaml, or if the file has no cache section.
+var loadCacheConfig = func(path string) (*config.CacheConfig, error) {
+	if path == "" {
+		path = "dock-docs.yaml"
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil
+		}
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ResolveRelativePaths(filepath.Dir(path))
+	return cfg.Cache, nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCacheConfig(configFile)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		cfg = &config.CacheConfig{}
+	}
+	dir := cfg.Dir
+	if cacheDir != "" {
+		dir = cacheDir
+	}
+
+	store, err := cache.New(dir, cfg.TTL)
+	if err != nil {
+		return err
+	}
+	removed, err := store.Prune(cachePruneAll)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Removed %d cache entries from %s\n", removed, store.Dir)
+	return nil
+}
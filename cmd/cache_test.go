@@ -0,0 +1,116 @@
+// Test file for the cache command (runCachePrune) and cache wiring.
+//
+// Globals mutated: cacheDir, cachePruneAll, loadCacheConfig, noCache,
+// stdout (via captureOutput).
+// All tests use defer resetFlags()() for cleanup.
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/config"
+)
+
+func TestRunCachePrune(t *testing.T) {
+	defer resetFlags()()
+
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(old, []byte(`{"createdAt":"2000-01-01T00:00:00Z","stats":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fresh := filepath.Join(dir, "fresh.json")
+	entry := `{"createdAt":"` + time.Now().Format(time.RFC3339) + `","stats":{}}`
+	if err := os.WriteFile(fresh, []byte(entry), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loadCacheConfig = func(string) (*config.CacheConfig, error) { return &config.CacheConfig{Dir: dir}, nil }
+
+	output := captureOutput(func() {
+		if err := runCachePrune(cachePruneCmd, nil); err != nil {
+			t.Fatalf("runCachePrune() error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Removed 1 cache entries") {
+		t.Errorf("unexpected output: %q", output)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("fresh entry should not be pruned")
+	}
+
+	// --dir overrides the config and --all removes everything.
+	cacheDir = dir
+	cachePruneAll = true
+	loadCacheConfig = func(string) (*config.CacheConfig, error) { return nil, nil }
+	captureOutput(func() {
+		if err := runCachePrune(cachePruneCmd, nil); err != nil {
+			t.Fatalf("runCachePrune(--all) error: %v", err)
+		}
+	})
+	if _, err := os.Stat(fresh); !os.IsNotExist(err) {
+		t.Error("expected --all to remove every entry")
+	}
+}
+
+func TestLoadCacheConfig(t *testing.T) {
+	defer resetFlags()()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dock-docs.yaml")
+	if err := os.WriteFile(path, []byte(`output: README.md
+cache:
+  dir: .cache
+sections:
+  - type: image
+    source: Dockerfile
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadCacheConfig(path)
+	if err != nil {
+		t.Fatalf("loadCacheConfig() error: %v", err)
+	}
+	if want := filepath.Join(dir, ".cache"); cfg == nil || cfg.Dir != want {
+		t.Errorf("cache config = %+v, want dir %s relative to the config file", cfg, want)
+	}
+
+	// A broken or missing config given with --config is an error.
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("cache: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{invalid, filepath.Join(dir, "missing.yaml")} {
+		if _, err := loadCacheConfig(p); err == nil {
+			t.Errorf("loadCacheConfig(%s) expected error", filepath.Base(p))
+		}
+	}
+
+	// Without --config, a missing dock-docs.yaml means the defaults.
+	t.Chdir(t.TempDir())
+	if cfg, err := loadCacheConfig(""); err != nil || cfg != nil {
+		t.Errorf("loadCacheConfig(\"\") = (%+v, %v), want (nil, nil)", cfg, err)
+	}
+}
+
+func TestNewCacheStore(t *testing.T) {
+	defer resetFlags()()
+
+	dir := t.TempDir()
+	store, err := newCacheStore(&config.CacheConfig{Dir: dir, TTL: time.Hour})
+	if err != nil || store == nil {
+		t.Fatalf("newCacheStore() = (%v, %v)", store, err)
+	}
+	if store.Dir != dir || store.TTL != time.Hour {
+		t.Errorf("store = %+v, want dir %s and ttl 1h", store, dir)
+	}
+
+	noCache = true
+	if store, err := newCacheStore(nil); err != nil || store != nil {
+		t.Errorf("newCacheStore(--no-cache) = (%v, %v), want (nil, nil)", store, err)
+	}
+}
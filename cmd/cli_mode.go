@@ -5,16 +5,68 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/analysis"
+	"github.com/northcutted/dock-docs/pkg/catalog"
 	"github.com/northcutted/dock-docs/pkg/injector"
+	"github.com/northcutted/dock-docs/pkg/lifecycle"
 	"github.com/northcutted/dock-docs/pkg/parser"
 	"github.com/northcutted/dock-docs/pkg/renderer"
-	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/results"
 	"github.com/northcutted/dock-docs/pkg/templates"
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
+// classify applies the framework catalogue to stats and its platforms.
+func classify(cat *catalog.Catalog, stats *types.ImageStats) {
+	if stats == nil {
+		return
+	}
+	cat.Classify(stats)
+	for _, ps := range stats.Platforms {
+		cat.Classify(ps)
+	}
+}
+
+// checkLifecycle records the support status of the distribution and runtimes
+// of stats and its platforms, and logs those past or near their end of life.
+// stats must be classified first, as runtimes come from the tech stack.
+func checkLifecycle(db *lifecycle.Database, stats *types.ImageStats, image string) {
+	if stats == nil {
+		return
+	}
+	now := time.Now()
+	db.Check(stats, now)
+	for _, ps := range stats.Platforms {
+		db.Check(ps, now)
+	}
+	for _, e := range stats.EOLWarnings() {
+		slog.Warn("end-of-life software", "image", image, "finding", e.Message())
+	}
+}
+
+// compareWithBaseImage records how stats compares with the base image of
+// doc when --compare-base is set. analyze returns the base image's analysis.
+func compareWithBaseImage(doc *parser.Documentation, stats *types.ImageStats, analyze func(ref string) (*types.ImageStats, error)) error {
+	if !compareBase || stats == nil {
+		return nil
+	}
+	if doc.BaseImage == "" {
+		slog.Info("no base image to compare with", "image", stats.ImageTag)
+		return nil
+	}
+	base, err := analyze(doc.BaseImage)
+	if err != nil {
+		slog.Warn("base image analysis failed", "image", doc.BaseImage, "error", err)
+		if !ignoreErrors {
+			return fmt.Errorf("base image %s: %w", doc.BaseImage, err)
+		}
+	}
+	recordBase(stats, base, doc.BaseImage)
+	return nil
+}
+
 func runCLIMode(ctx context.Context) error {
 	// 1. Parse Dockerfile
 	doc, err := parser.Parse(dockerfile)
@@ -24,22 +76,54 @@ func runCLIMode(ctx context.Context) error {
 
 	// 2. Dynamic Analysis (if requested)
 	var stats *types.ImageStats
-	if imageTag != "" {
-		slog.Info("analyzing image", "image", imageTag)
-		runners := []analysis.Runner{
-			&runner.RuntimeRunner{},
-			&runner.ManifestRunner{},
-			&runner.SyftRunner{},
-			&runner.GrypeRunner{},
-			&runner.DiveRunner{},
+	if imageTag != "" && fromResults != "" {
+		saved, err := results.Load(fromResults)
+		if err != nil {
+			return err
 		}
-		stats, err = analysis.AnalyzeImage(ctx, imageTag, runners, verbose)
+		if stats, err = saved.Lookup(imageTag); err != nil {
+			if !ignoreErrors {
+				return err
+			}
+			slog.Warn("saved results unavailable", "image", imageTag, "error", err)
+		}
+		if err := compareWithBaseImage(doc, stats, saved.Lookup); err != nil {
+			return err
+		}
+		classify(catalog.Default(), stats)
+		checkLifecycle(lifecycle.Default(), stats, imageTag)
+	} else if imageTag != "" {
+		release, err := configureRuntime(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer release()
+		store, err := newCacheStore(nil)
+		if err != nil {
+			return err
+		}
+		factory := runnerFactory(nil, nil, store, newLimiter(nil))
+
+		slog.Info("analyzing image", "image", imageTag)
+		stats, err = analysis.AnalyzeImage(ctx, imageTag, factory(), verbose)
 		if err != nil {
 			slog.Warn("analysis failed", "error", err)
 			if !ignoreErrors {
 				return fmt.Errorf("analysis failed: %w", err)
 			}
 		}
+		if perPlatform && stats != nil {
+			analyzePlatforms(ctx, nil, stats, factory)
+		}
+		err = compareWithBaseImage(doc, stats, func(ref string) (*types.ImageStats, error) {
+			slog.Info("analyzing base image", "image", ref)
+			return analysis.AnalyzeImage(ctx, ref, factory(), verbose)
+		})
+		if err != nil {
+			return err
+		}
+		classify(catalog.Default(), stats)
+		checkLifecycle(lifecycle.Default(), stats, imageTag)
 	}
 
 	// 3. Resolve template selection: CLI flag > default
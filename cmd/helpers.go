@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/northcutted/dock-docs/pkg/installer"
+	"github.com/northcutted/dock-docs/pkg/runner"
 )
 
 // checkToolStatus returns a string indicating the status of required tools.
@@ -14,13 +14,11 @@ func checkToolStatus() string {
 	var status strings.Builder
 	status.WriteString("\nPrerequisites:\n")
 
-	// Check for Docker or Podman
-	if _, err := exec.LookPath("docker"); err == nil {
-		status.WriteString("  [OK] docker\n")
-	} else if _, err := exec.LookPath("podman"); err == nil {
-		status.WriteString("  [OK] podman\n")
+	// Check for a container runtime
+	if b, err := runner.CurrentBackend(); err == nil {
+		fmt.Fprintf(&status, "  [OK] %s\n", b.Name())
 	} else {
-		status.WriteString("  [MISSING] docker or podman (required for dynamic analysis)\n")
+		fmt.Fprintf(&status, "  [MISSING] container runtime: %s (required for dynamic analysis)\n", strings.Join(runner.Backends, ", "))
 	}
 
 	for _, tool := range tools {
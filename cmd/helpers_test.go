@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/runner"
 )
 
 // resetFlags saves the current values of all package-level mutable globals
@@ -31,6 +34,13 @@ func resetFlags() func() {
 	savedValidateTemplate := validateTemplate
 	savedDebugTemplate := debugTemplate
 	savedAnalysisTimeout := analysisTimeout
+	savedPerPlatform := perPlatform
+	savedCompareBase := compareBase
+	savedNoCache := noCache
+	savedFromResults := fromResults
+	savedPullPolicy := pullPolicy
+	savedPullPlatform := pullPlatform
+	savedLogFormat := logFormat
 	savedStdout := stdout
 	savedLogOutput := logOutput
 
@@ -41,6 +51,14 @@ func resetFlags() func() {
 	savedLoadToolConfig := loadToolConfig
 	savedResolveToolOverrides := resolveToolOverrides
 
+	// Cache flags (cache.go)
+	savedCacheDir := cacheDir
+	savedCachePruneAll := cachePruneAll
+	savedLoadCacheConfig := loadCacheConfig
+
+	// Analyze flags (analyze.go)
+	savedAnalyzeOut := analyzeOut
+
 	// Version vars (version.go)
 	savedVersion := Version
 	savedCommit := Commit
@@ -62,6 +80,13 @@ func resetFlags() func() {
 		validateTemplate = savedValidateTemplate
 		debugTemplate = savedDebugTemplate
 		analysisTimeout = savedAnalysisTimeout
+		perPlatform = savedPerPlatform
+		compareBase = savedCompareBase
+		noCache = savedNoCache
+		fromResults = savedFromResults
+		pullPolicy = savedPullPolicy
+		pullPlatform = savedPullPlatform
+		logFormat = savedLogFormat
 		stdout = savedStdout
 		logOutput = savedLogOutput
 
@@ -71,9 +96,21 @@ func resetFlags() func() {
 		loadToolConfig = savedLoadToolConfig
 		resolveToolOverrides = savedResolveToolOverrides
 
+		cacheDir = savedCacheDir
+		cachePruneAll = savedCachePruneAll
+		loadCacheConfig = savedLoadCacheConfig
+
+		analyzeOut = savedAnalyzeOut
+
 		Version = savedVersion
 		Commit = savedCommit
 		Date = savedDate
+
+		// The container runtime is selected by configureRuntime.
+		runner.SetBackend(nil)
+		_ = runner.SetConnection(context.Background(), runner.Connection{})
+		_ = runner.SetPullOptions(runner.PullOptions{})
+		_, _ = runner.SetCredentials(runner.Credentials{})
 	}
 }
 
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/events"
+)
+
+// Log formats accepted by --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// isTerminal reports whether w is an interactive terminal. It is swappable
+// so tests can exercise the progress display.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// activeProgress is the progress display of the current command, if any.
+var activeProgress *progress
+
+// logEvents returns an event handler that logs every event at level, as a
+// JSON line with --log-format json.
+func logEvents(level slog.Level) events.Handler {
+	return func(e events.Event) {
+		attrs := []any{"event", string(e.Kind)}
+		if e.Image != "" {
+			attrs = append(attrs, "image", e.Image)
+		}
+		if e.Runner != "" {
+			attrs = append(attrs, "runner", e.Runner)
+		}
+		if e.Status != "" {
+			attrs = append(attrs, "status", e.Status)
+		}
+		if e.Runners > 0 {
+			attrs = append(attrs, "runners", e.Runners)
+		}
+		if e.Duration > 0 {
+			attrs = append(attrs, "duration", e.Duration)
+		}
+		if e.Error != "" {
+			attrs = append(attrs, "error", e.Error)
+		}
+		slog.Log(context.Background(), level, strings.ReplaceAll(string(e.Kind), "_", " "), attrs...)
+	}
+}
+
+// progress renders analysis events as a status line at the bottom of a
+// terminal, e.g.:
+//
+//	1/3 images | 7/15 runners | running grype app:1 (1m12s) +2 | 4 cached
+//
+// Log records are written through it, so they are printed above the line.
+type progress struct {
+	mu      sync.Mutex
+	out     io.Writer
+	width   int
+	line    string
+	stop    chan struct{}
+	stopped bool
+
+	images, finished int
+	runners, done    int
+	pending          map[string]int       // runners still to finish, by image
+	running          map[string]time.Time // start time, by "runner image"
+	pulling          map[string]bool
+	hits             int
+}
+
+// newProgress returns a progress display writing to out. It redraws every
+// second so the elapsed times stay current, until Close is called.
+func newProgress(out io.Writer) *progress {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width < 20 {
+		width = 80
+	}
+	p := &progress{
+		out:     out,
+		width:   width,
+		stop:    make(chan struct{}),
+		pending: make(map[string]int),
+		running: make(map[string]time.Time),
+		pulling: make(map[string]bool),
+	}
+	go p.tick()
+	return p
+}
+
+// tick redraws the line every second.
+func (p *progress) tick() {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			p.mu.Lock()
+			if p.line != "" {
+				p.draw()
+			}
+			p.mu.Unlock()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Handle updates the display with an event.
+func (p *progress) Handle(e events.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := e.Runner + " " + e.Image
+	switch e.Kind {
+	case events.AnalysisStarted:
+		p.images++
+		p.runners += e.Runners
+		p.pending[e.Image] += e.Runners
+	case events.AnalysisFinished:
+		// Runners that never ran (e.g., the pull failed) count as done.
+		p.finished++
+		p.done += p.pending[e.Image]
+		delete(p.pending, e.Image)
+	case events.RunnerStarted:
+		p.running[key] = e.Time
+	case events.RunnerFinished:
+		delete(p.running, key)
+		if p.pending[e.Image] > 0 {
+			p.pending[e.Image]--
+			p.done++
+		}
+	case events.PullStarted:
+		p.pulling[e.Image] = true
+	case events.ImagePulled:
+		delete(p.pulling, e.Image)
+	case events.CacheHit:
+		p.hits++
+	}
+	if p.stopped {
+		return
+	}
+	p.draw()
+}
+
+// Write prints b above the status line.
+func (p *progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.out.Write(b)
+	if p.line != "" {
+		fmt.Fprint(p.out, p.line)
+	}
+	return n, err
+}
+
+// Close stops the display and erases the status line.
+func (p *progress) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	close(p.stop)
+	p.clear()
+	p.line = ""
+}
+
+// clear erases the status line, if one is shown.
+func (p *progress) clear() {
+	if p.line != "" {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+// draw replaces the status line with the current state.
+func (p *progress) draw() {
+	p.clear()
+	p.line = p.render(time.Now())
+	fmt.Fprint(p.out, p.line)
+}
+
+// render returns the status line, truncated to the terminal width.
+func (p *progress) render(now time.Time) string {
+	parts := []string{
+		fmt.Sprintf("%d/%d images", p.finished, p.images),
+		fmt.Sprintf("%d/%d runners", p.done, p.runners),
+	}
+	if len(p.running) > 0 {
+		// Show the longest-running runner, which is what the run waits for.
+		var oldest string
+		var since time.Time
+		for k, t := range p.running {
+			if oldest == "" || t.Before(since) || t.Equal(since) && k < oldest {
+				oldest, since = k, t
+			}
+		}
+		part := fmt.Sprintf("running %s (%s)", oldest, now.Sub(since).Round(time.Second))
+		if n := len(p.running) - 1; n > 0 {
+			part += fmt.Sprintf(" +%d", n)
+		}
+		parts = append(parts, part)
+	}
+	if len(p.pulling) > 0 {
+		parts = append(parts, fmt.Sprintf("pulling %d", len(p.pulling)))
+	}
+	if p.hits > 0 {
+		parts = append(parts, fmt.Sprintf("%d cached", p.hits))
+	}
+	line := []rune(strings.Join(parts, " | "))
+	if len(line) >= p.width {
+		line = append(line[:p.width-2], '…')
+	}
+	return string(line)
+}
@@ -0,0 +1,133 @@
+// Test file for analysis event reporting (--log-format and the terminal
+// progress display).
+//
+// Globals mutated: logFormat, logOutput, isTerminal, activeProgress.
+// All tests use defer resetFlags()() for cleanup.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/events"
+)
+
+func TestInitLogger_JSON(t *testing.T) {
+	defer resetFlags()()
+	logFormat = logFormatJSON
+
+	var buf bytes.Buffer
+	logOutput = &buf
+	handle := initLogger()
+	handle(events.Event{Kind: events.RunnerFinished, Image: "app:1", Runner: "grype", Status: "ok", Duration: 1500 * time.Millisecond})
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("event is not a JSON line: %v\n%s", err, buf.String())
+	}
+	for k, want := range map[string]any{"msg": "runner finished", "event": "runner_finished", "image": "app:1", "runner": "grype", "status": "ok"} {
+		if line[k] != want {
+			t.Errorf("%s = %v, want %v", k, line[k], want)
+		}
+	}
+}
+
+func TestInitLogger_TextEventsAreDebug(t *testing.T) {
+	defer resetFlags()()
+
+	var buf bytes.Buffer
+	logOutput = &buf
+	initLogger()(events.Event{Kind: events.CacheHit, Image: "app:1", Runner: "syft"})
+	if buf.Len() != 0 {
+		t.Errorf("events should only be logged with --verbose, got %q", buf.String())
+	}
+
+	verbose = true
+	initLogger()(events.Event{Kind: events.CacheHit, Image: "app:1", Runner: "syft"})
+	if !strings.Contains(buf.String(), "cache hit") {
+		t.Errorf("expected cache hit in verbose log, got %q", buf.String())
+	}
+}
+
+func TestInitLogger_Progress(t *testing.T) {
+	defer resetFlags()()
+	savedIsTerminal := isTerminal
+	defer func() { isTerminal = savedIsTerminal; activeProgress = nil }()
+	isTerminal = func(io.Writer) bool { return true }
+
+	var buf bytes.Buffer
+	logOutput = &buf
+	handle := initLogger()
+	if activeProgress == nil {
+		t.Fatal("expected a progress display on a terminal")
+	}
+	defer activeProgress.Close()
+
+	handle(events.Event{Kind: events.AnalysisStarted, Image: "app:1", Runners: 2})
+	if !strings.HasSuffix(buf.String(), "0/1 images | 0/2 runners") {
+		t.Errorf("unexpected status line: %q", buf.String())
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgress(&buf)
+	defer p.Close()
+	p.mu.Lock()
+	p.width = 120
+	p.mu.Unlock()
+
+	start := time.Now()
+	p.Handle(events.Event{Kind: events.AnalysisStarted, Image: "app:1", Runners: 3, Time: start})
+	p.Handle(events.Event{Kind: events.AnalysisStarted, Image: "app:2", Runners: 3, Time: start})
+	p.Handle(events.Event{Kind: events.PullStarted, Image: "app:2", Time: start})
+	p.Handle(events.Event{Kind: events.RunnerStarted, Image: "app:1", Runner: "grype", Time: start})
+	p.Handle(events.Event{Kind: events.RunnerStarted, Image: "app:1", Runner: "syft", Time: start.Add(time.Second)})
+	p.Handle(events.Event{Kind: events.CacheHit, Image: "app:1", Runner: "dive", Time: start})
+	p.Handle(events.Event{Kind: events.RunnerFinished, Image: "app:1", Runner: "dive", Status: "ok", Time: start})
+
+	p.mu.Lock()
+	got := p.render(start.Add(72 * time.Second))
+	p.mu.Unlock()
+	if want := "0/2 images | 1/6 runners | running grype app:1 (1m12s) +1 | pulling 1 | 1 cached"; got != want {
+		t.Errorf("render() = %q, want %q", got, want)
+	}
+
+	// A failed pull finishes the image's remaining runners.
+	p.Handle(events.Event{Kind: events.ImagePulled, Image: "app:2", Error: "denied"})
+	p.Handle(events.Event{Kind: events.AnalysisFinished, Image: "app:2", Error: "denied"})
+	p.mu.Lock()
+	got = p.render(start)
+	p.mu.Unlock()
+	if !strings.HasPrefix(got, "1/2 images | 4/6 runners") {
+		t.Errorf("render() = %q, want 1/2 images and 4/6 runners", got)
+	}
+
+	// Logs are printed above the status line.
+	buf.Reset()
+	if _, err := p.Write([]byte("level=INFO msg=hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "\r\033[Klevel=INFO msg=hello\n") || !strings.HasSuffix(out, p.line) {
+		t.Errorf("Write() output = %q", out)
+	}
+
+	p.mu.Lock()
+	p.width = 20
+	got = p.render(start)
+	p.mu.Unlock()
+	if n := len([]rune(got)); n != 19 || !strings.HasSuffix(got, "…") {
+		t.Errorf("render() = %q, want 19 runes ending in an ellipsis", got)
+	}
+
+	p.Close()
+	buf.Reset()
+	p.Handle(events.Event{Kind: events.CacheHit})
+	if buf.Len() != 0 {
+		t.Errorf("closed display should not draw, got %q", buf.String())
+	}
+}
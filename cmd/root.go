@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,6 +12,11 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/northcutted/dock-docs/pkg/drift"
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/policy"
+	"github.com/northcutted/dock-docs/pkg/runner"
 )
 
 // stdout is the writer used for normal program output. Tests can swap this
@@ -38,6 +44,13 @@ var (
 	validateTemplate string
 	debugTemplate    bool
 	analysisTimeout  time.Duration
+	perPlatform      bool
+	compareBase      bool
+	noCache          bool
+	fromResults      string
+	pullPolicy       string
+	pullPlatform     string
+	logFormat        = logFormatText
 )
 
 var rootCmd = &cobra.Command{
@@ -67,8 +80,12 @@ Modes:
 
   # CLI Mode: Output to specific file
   dock-docs -f ./Dockerfile -o DOCUMENTATION.md`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		initLogger()
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if logFormat != logFormatText && logFormat != logFormatJSON {
+			return fmt.Errorf("invalid --log-format %q (want %s or %s)", logFormat, logFormatText, logFormatJSON)
+		}
+		cmd.SetContext(events.WithHandler(cmd.Context(), initLogger()))
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Handle template developer tool flags first (these exit early)
@@ -107,27 +124,114 @@ Modes:
 	},
 }
 
+// Process exit codes. A failed security policy or drift check gets its own
+// code so CI can distinguish "the image is not acceptable" from "dock-docs
+// could not run", and so does each kind of analysis failure.
+const (
+	exitError             = 1
+	exitPolicyViolation   = 3
+	exitDrift             = 4
+	exitImageNotFound     = 5
+	exitAuthRequired      = 6
+	exitDaemonUnreachable = 7
+	exitToolMissing       = 8
+	exitTimeout           = 9
+	exitParse             = 10
+)
+
+// failureKinds maps the analysis failure kinds of pkg/runner to an exit code
+// and a remediation hint. When an error carries several kinds (e.g., one per
+// failed runner), the first listed wins. Timeouts are matched through
+// context.DeadlineExceeded, which also covers the overall --timeout.
+var failureKinds = []struct {
+	kind error
+	code int
+	hint string
+}{
+	{runner.ErrDaemonUnreachable, exitDaemonUnreachable,
+		"Start the container runtime (e.g., Docker Desktop or 'systemctl start docker'), check DOCKER_HOST or runtime.context, or select another runtime with runtime.backend or " + runner.RuntimeEnv + "."},
+	{runner.ErrToolMissing, exitToolMissing,
+		"Install the missing tool with 'dock-docs setup', or disable its runner under 'runners' in dock-docs.yaml."},
+	{runner.ErrAuthRequired, exitAuthRequired,
+		"Log in to the registry (e.g., 'docker login <registry>') or set runtime.auth in dock-docs.yaml."},
+	{runner.ErrImageNotFound, exitImageNotFound,
+		"Check the image name and tag, build the image first, or let dock-docs pull it (--pull if-not-present)."},
+	{context.DeadlineExceeded, exitTimeout,
+		"Raise --timeout or the runner's 'timeout' under 'runners', or set 'retries' for flaky registries."},
+	{runner.ErrParse, exitParse,
+		"The tool's output format may have changed: reinstall a supported version with 'dock-docs setup' and rerun with --verbose to see the raw output."},
+}
+
 // Execute runs the root cobra command and exits on error.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if activeProgress != nil {
+		activeProgress.Close()
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if h := hint(err); h != "" {
+			fmt.Fprintln(os.Stderr, "Hint:", h)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
-// initLogger configures the global slog logger based on the --verbose flag.
-// DEBUG level is enabled when verbose is true; otherwise INFO is the minimum.
-// Output goes to logOutput (defaults to stderr) so it doesn't interfere with
-// program content on stdout.
-func initLogger() {
+// exitCode maps an error returned by the root command to a process exit code.
+func exitCode(err error) int {
+	var violation *policy.ViolationError
+	if errors.As(err, &violation) {
+		return exitPolicyViolation
+	}
+	var driftErr *drift.Error
+	if errors.As(err, &driftErr) {
+		return exitDrift
+	}
+	for _, k := range failureKinds {
+		if errors.Is(err, k.kind) {
+			return k.code
+		}
+	}
+	return exitError
+}
+
+// hint returns the remediation hint for an analysis failure, or "" when err
+// is not one.
+func hint(err error) string {
+	for _, k := range failureKinds {
+		if errors.Is(err, k.kind) {
+			return k.hint
+		}
+	}
+	return ""
+}
+
+// initLogger configures the global slog logger based on the --verbose and
+// --log-format flags. DEBUG level is enabled when verbose is true; otherwise
+// INFO is the minimum. Output goes to logOutput (defaults to stderr) so it
+// doesn't interfere with program content on stdout.
+//
+// It returns the handler for analysis events: a live progress line when
+// logOutput is a terminal, INFO records (JSON lines) with --log-format json,
+// and DEBUG records otherwise.
+func initLogger() events.Handler {
 	level := slog.LevelInfo
 	if verbose {
 		level = slog.LevelDebug
 	}
-	handler := slog.NewTextHandler(logOutput, &slog.HandlerOptions{
-		Level: level,
-	})
-	slog.SetDefault(slog.New(handler))
+	opts := &slog.HandlerOptions{Level: level}
+
+	if logFormat == logFormatJSON {
+		slog.SetDefault(slog.New(slog.NewJSONHandler(logOutput, opts)))
+		return logEvents(slog.LevelInfo)
+	}
+	if isTerminal(logOutput) {
+		activeProgress = newProgress(logOutput)
+		slog.SetDefault(slog.New(slog.NewTextHandler(activeProgress, opts)))
+		return activeProgress.Handle
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(logOutput, opts)))
+	return logEvents(slog.LevelDebug)
 }
 
 func init() {
@@ -141,7 +245,7 @@ func init() {
 	rootCmd.Flags().StringVar(&configFile, "config", "", "Path to config file (default: dock-docs.yaml)")
 	rootCmd.Flags().BoolVar(&noMoji, "nomoji", false, "Disable emojis in the output")
 	rootCmd.Flags().BoolVar(&ignoreErrors, "ignore-errors", false, "Ignore analysis errors and continue (default false)")
-	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
 	rootCmd.Flags().StringVar(&badgeBaseURL, "badge-base-url", "https://img.shields.io/static/v1", "Base URL for badge generation (e.g. for self-hosted shields.io)")
 
 	// Template flags
@@ -150,7 +254,14 @@ func init() {
 	rootCmd.Flags().StringVar(&exportTemplate, "export-template", "", "Export a built-in template to stdout (e.g. 'default')")
 	rootCmd.Flags().StringVar(&validateTemplate, "validate-template", "", "Validate a custom template file for syntax errors")
 	rootCmd.Flags().BoolVar(&debugTemplate, "debug-template", false, "Print template resolution info during rendering")
+	rootCmd.Flags().BoolVar(&perPlatform, "per-platform", false, "Analyze every platform of a multi-arch image separately")
+	rootCmd.Flags().BoolVar(&compareBase, "compare-base", false, "Analyze the base image of the final Dockerfile stage and report inherited vs introduced content")
+	rootCmd.Flags().StringVar(&pullPolicy, "pull", "", "Image pull policy: always, if-not-present or never (default: runtime.pull, or if-not-present)")
+	rootCmd.Flags().StringVar(&pullPlatform, "platform", "", "Platform to pull images for, e.g. linux/arm64 (default: runtime.platform)")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Always re-run analysis tools instead of using cached results")
+	rootCmd.Flags().StringVar(&fromResults, "from-results", "", "Render from a results file written by 'dock-docs analyze' instead of analyzing images")
 	rootCmd.Flags().DurationVar(&analysisTimeout, "timeout", 10*time.Minute, "Overall timeout for all analysis operations (e.g. 5m, 30s)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, "Log format: text (with a live progress line on terminals) or json (one JSON object per line)")
 
 	// Add version flag as shortcut for "version" command
 	rootCmd.Version = Version
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/drift"
+	"github.com/northcutted/dock-docs/pkg/policy"
+	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestExitCode(t *testing.T) {
+	violation := &policy.ViolationError{Image: "app:latest", Report: &types.PolicyReport{}}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic error", errors.New("boom"), exitError},
+		{"policy violation", violation, exitPolicyViolation},
+		{"wrapped violation", fmt.Errorf("section main: %w", violation), exitPolicyViolation},
+		{"joined violations", errors.Join(violation, violation), exitPolicyViolation},
+		{"drift", fmt.Errorf("section main: %w", &drift.Error{Image: "app:latest"}), exitDrift},
+		{"image not found", fmt.Errorf("analysis failed: %w", &runner.Error{Kind: runner.ErrImageNotFound, Tool: "docker", Err: errors.New("no such image")}), exitImageNotFound},
+		{"auth required", &runner.Error{Kind: runner.ErrAuthRequired, Tool: "grype", Err: errors.New("unauthorized")}, exitAuthRequired},
+		{"daemon unreachable", &runner.Error{Kind: runner.ErrDaemonUnreachable, Tool: "docker", Err: errors.New("refused")}, exitDaemonUnreachable},
+		{"tool missing", &runner.Error{Kind: runner.ErrToolMissing, Tool: "syft", Err: errors.New("syft not found")}, exitToolMissing},
+		{"runner timeout", &runner.Error{Kind: runner.ErrTimeout, Tool: "grype", Err: errors.New("signal: killed")}, exitTimeout},
+		{"overall timeout", fmt.Errorf("analysis failed: %w", context.DeadlineExceeded), exitTimeout},
+		{"parse failure", &runner.Error{Kind: runner.ErrParse, Tool: "dive", Err: errors.New("invalid character")}, exitParse},
+		{"policy wins over analysis failures", errors.Join(&runner.Error{Kind: runner.ErrParse, Tool: "dive", Err: errors.New("x")}, violation), exitPolicyViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+			if got := hint(tt.err); tt.want > exitDrift && got == "" || tt.want == exitError && got != "" {
+				t.Errorf("hint() = %q for exit code %d", got, tt.want)
+			}
+		})
+	}
+}
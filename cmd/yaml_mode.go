@@ -2,20 +2,33 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/northcutted/dock-docs/pkg/analysis"
+	"github.com/northcutted/dock-docs/pkg/baseimage"
+	"github.com/northcutted/dock-docs/pkg/cache"
+	"github.com/northcutted/dock-docs/pkg/catalog"
 	"github.com/northcutted/dock-docs/pkg/config"
+	"github.com/northcutted/dock-docs/pkg/drift"
 	"github.com/northcutted/dock-docs/pkg/injector"
+	"github.com/northcutted/dock-docs/pkg/license"
+	"github.com/northcutted/dock-docs/pkg/lifecycle"
 	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/policy"
 	"github.com/northcutted/dock-docs/pkg/renderer"
+	"github.com/northcutted/dock-docs/pkg/results"
 	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/suppression"
 	"github.com/northcutted/dock-docs/pkg/templates"
 	"github.com/northcutted/dock-docs/pkg/types"
 )
@@ -33,6 +46,585 @@ func newRunners() []analysis.Runner {
 	}
 }
 
+// runnerFactory returns a constructor for the built-in runners followed by
+// one PluginRunner per configured plugin, tuned by settings (keyed by runner
+// name; disabled runners are recorded as skipped). Like newRunners, every call yields
+// fresh instances so concurrent analyses never share runner state. When store
+// is non-nil, cacheable runners are wrapped to reuse results from it. When
+// limiter is non-nil, every runner is scheduled through it, so all analyses
+// using the factory share its limits.
+func runnerFactory(plugins []config.PluginConfig, settings map[string]config.RunnerConfig, store *cache.Store, limiter *analysis.Limiter) func() []analysis.Runner {
+	return func() []analysis.Runner {
+		all := newRunners()
+		for _, p := range plugins {
+			all = append(all, &runner.PluginRunner{
+				PluginName: p.Name,
+				Command:    p.Command,
+				Args:       p.Args,
+				Options:    p.Options,
+				Timeout:    p.Timeout,
+			})
+		}
+
+		var runners []analysis.Runner
+		for _, r := range all {
+			s := settings[r.Name()]
+			if !s.IsEnabled() {
+				runners = append(runners, analysis.Skip(r, "disabled in config"))
+				continue
+			}
+			configureRunner(r, s)
+			if store != nil {
+				r = store.Wrap(r)
+			}
+			// The limiter wraps the cache so a cache hit only holds a slot
+			// briefly, and retries wrap the limiter so backoffs hold none.
+			r = limiter.Wrap(r)
+			r = analysis.RetryPolicy{Retries: s.Retries, Backoff: s.Backoff}.Wrap(r)
+			runners = append(runners, r)
+		}
+		return runners
+	}
+}
+
+// configureRunner applies the timeout and extra arguments of s to r.
+func configureRunner(r analysis.Runner, s config.RunnerConfig) {
+	switch r := r.(type) {
+	case *runner.RuntimeRunner:
+		r.Timeout = s.Timeout
+	case *runner.ManifestRunner:
+		r.Timeout = s.Timeout
+	case *runner.SyftRunner:
+		r.Timeout, r.Args = s.Timeout, s.Args
+	case *runner.GrypeRunner:
+		r.Timeout, r.Args = s.Timeout, s.Args
+	case *runner.DiveRunner:
+		r.Timeout, r.Args = s.Timeout, s.Args
+	case *runner.PluginRunner:
+		if s.Timeout > 0 {
+			r.Timeout = s.Timeout
+		}
+		r.Args = slices.Concat(r.Args, s.Args)
+	}
+}
+
+// newCacheStore returns the analysis cache described by cfg (which may be
+// nil for defaults), or nil when caching is disabled with --no-cache.
+func newCacheStore(cfg *config.CacheConfig) (*cache.Store, error) {
+	if noCache {
+		return nil, nil
+	}
+	if cfg == nil {
+		cfg = &config.CacheConfig{}
+	}
+	store, err := cache.New(cfg.Dir, cfg.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analysis cache: %w", err)
+	}
+	return store, nil
+}
+
+// newLimiter returns the runner worker pool described by cfg (which may be
+// nil for defaults).
+func newLimiter(cfg *config.ConcurrencyConfig) *analysis.Limiter {
+	if cfg == nil {
+		cfg = &config.ConcurrencyConfig{}
+	}
+	return analysis.NewLimiter(cfg.Max, cfg.Runners)
+}
+
+// configureRuntime selects the container runtime backend from the
+// DOCK_DOCS_RUNTIME environment variable or, when it is unset, the config
+// (which may be nil). Without either, the first installed runtime is used.
+// The connection, pull and credential settings of the config (with --pull
+// and --platform taking precedence) are then applied to every runner. The
+// returned function removes temporary credential files.
+func configureRuntime(ctx context.Context, cfg *config.RuntimeConfig) (func(), error) {
+	if cfg == nil {
+		cfg = &config.RuntimeConfig{}
+	}
+	name := os.Getenv(runner.RuntimeEnv)
+	if name == "" {
+		name = cfg.Backend
+	}
+	if name == "" {
+		runner.SetBackend(nil)
+	} else {
+		b, err := runner.NewBackend(name)
+		if err != nil {
+			return nil, err
+		}
+		slog.Debug("using container runtime", "runtime", b.Name())
+		runner.SetBackend(b)
+	}
+
+	err := runner.SetConnection(ctx, runner.Connection{
+		Context:          cfg.Context,
+		Host:             cfg.Host,
+		TLSVerify:        cfg.TLSVerify,
+		CertPath:         cfg.CertPath,
+		PodmanConnection: cfg.PodmanConnection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure container runtime connection: %w", err)
+	}
+
+	opts := runner.PullOptions{Policy: cfg.Pull, Platform: cfg.Platform}
+	if pullPolicy != "" {
+		opts.Policy = pullPolicy
+	}
+	if pullPlatform != "" {
+		opts.Platform = pullPlatform
+	}
+	if err := runner.SetPullOptions(opts); err != nil {
+		return nil, err
+	}
+
+	return runner.SetCredentials(registryCredentials(cfg.Auth))
+}
+
+// Default environment variables holding the registry username and password.
+const (
+	registryUsernameEnv = "DOCK_DOCS_REGISTRY_USERNAME"
+	registryPasswordEnv = "DOCK_DOCS_REGISTRY_PASSWORD"
+)
+
+// registryCredentials reads the registry credentials described by auth
+// (which may be nil) from the environment.
+func registryCredentials(auth *config.RegistryAuthConfig) runner.Credentials {
+	if auth == nil {
+		auth = &config.RegistryAuthConfig{}
+	}
+	userEnv, passEnv := auth.UsernameEnv, auth.PasswordEnv
+	if userEnv == "" {
+		userEnv = registryUsernameEnv
+	}
+	if passEnv == "" {
+		passEnv = registryPasswordEnv
+	}
+	return runner.Credentials{
+		DockerConfig: auth.DockerConfig,
+		Registry:     auth.Registry,
+		Username:     os.Getenv(userEnv),
+		Password:     os.Getenv(passEnv),
+	}
+}
+
+// yamlRun carries the state shared by every section of a single YAML Mode
+// invocation.
+type yamlRun struct {
+	cfg        *config.Config
+	renderOpts renderer.RenderOptions
+	// store and limiter are shared by the runners of every section.
+	store   *cache.Store
+	limiter *analysis.Limiter
+	// analyzers holds one sectionAnalyzer per distinct runner setting.
+	analyzersMu sync.Mutex
+	analyzers   map[string]*sectionAnalyzer
+	// suppressions are the unexpired suppression rules applied to every
+	// analysis result before rendering.
+	suppressions []suppression.Rule
+	// catalog classifies packages into tiers and builds the tech stack.
+	catalog *catalog.Catalog
+	// lifecycle reports distributions and runtimes past their end of life.
+	lifecycle *lifecycle.Database
+	// results, when set, replaces image analysis with previously saved
+	// results (--from-results), so no container runtime or scanner is used.
+	results *results.File
+	// release removes temporary registry credential files.
+	release func()
+
+	mu         sync.Mutex
+	violations []error // policy violations, reported after all output is written
+}
+
+// newCatalog returns the built-in framework catalogue extended with the
+// frameworks declared in the config.
+func newCatalog(frameworks []config.FrameworkConfig) (*catalog.Catalog, error) {
+	entries := make([]catalog.Entry, len(frameworks))
+	for i, f := range frameworks {
+		entries[i] = catalog.Entry{
+			Name:  f.Name,
+			Tier:  f.Tier,
+			Match: f.Match,
+			Types: f.Types,
+		}
+	}
+	cat, err := catalog.New(entries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid framework catalogue: %w", err)
+	}
+	return cat, nil
+}
+
+// newLifecycle returns the built-in lifecycle dataset extended with the one
+// from lc.File, with lc's warning period.
+func newLifecycle(lc *config.LifecycleConfig) (*lifecycle.Database, error) {
+	if lc == nil {
+		return lifecycle.Default(), nil
+	}
+	var extra []lifecycle.Product
+	if lc.File != "" {
+		var err error
+		if extra, err = lifecycle.Load(lc.File); err != nil {
+			return nil, err
+		}
+	}
+	db, err := lifecycle.New(extra)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lifecycle dataset: %w", err)
+	}
+	if lc.WarnDays > 0 {
+		db.WarnWithin = time.Duration(lc.WarnDays) * 24 * time.Hour
+	}
+	return db, nil
+}
+
+// loadSuppressions builds the suppression rules declared in the config, both
+// inline and from OpenVEX documents. Expired rules are dropped with a warning
+// so the findings they covered show up again.
+func loadSuppressions(sc *config.SuppressionConfig, source string, now time.Time) ([]suppression.Rule, error) {
+	if sc == nil {
+		return nil, nil
+	}
+
+	var rules []suppression.Rule
+	for _, r := range sc.Ignore {
+		rule := suppression.Rule{
+			ID:            r.ID,
+			Package:       r.Package,
+			Versions:      r.Versions,
+			Justification: r.Justification,
+			Status:        r.Status,
+			Expires:       r.Expires.Time,
+			Source:        source,
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid suppression: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	for _, path := range sc.VEX {
+		vexRules, err := suppression.LoadOpenVEX(path)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, vexRules...)
+	}
+
+	active, expired := suppression.Partition(rules, now)
+	for _, r := range expired {
+		slog.Warn("suppression expired; finding will be reported again",
+			"id", r.ID, "expires", r.Expires.Format("2006-01-02"), "source", r.Source)
+	}
+	return active, nil
+}
+
+// flagLicenses marks licenses on the configured denylist so templates can
+// highlight them.
+func (r *yamlRun) flagLicenses(stats *types.ImageStats) {
+	if r.cfg.Licenses == nil {
+		return
+	}
+	if n := license.Flag(stats, r.cfg.Licenses.Deny); n > 0 {
+		slog.Warn("denied licenses found", "image", stats.ImageTag, "licenses", n)
+	}
+}
+
+// toPolicy converts a policy config into the form evaluated by pkg/policy.
+func toPolicy(pc *config.PolicyConfig) policy.Policy {
+	return policy.Policy{
+		MaxCritical:       pc.MaxCritical,
+		MaxHigh:           pc.MaxHigh,
+		OnlyFixable:       pc.OnlyFixable,
+		MinEfficiency:     pc.MinEfficiency,
+		MaxSizeBytes:      int64(pc.MaxSizeMB * 1024 * 1024),
+		ForbiddenPackages: pc.ForbiddenPackages,
+		DenyEOL:           pc.DenyEOL,
+		AllowUnscanned:    pc.AllowUnscanned,
+	}
+}
+
+// checkPolicy evaluates the section's security policy against stats and
+// attaches the report so templates can render it. Failures are recorded and
+// returned once every section has been written.
+func (r *yamlRun) checkPolicy(section config.Section, image string, stats *types.ImageStats) {
+	pc := r.cfg.ResolvePolicy(section)
+	if pc == nil || stats == nil {
+		return
+	}
+	p := toPolicy(pc)
+	if p.IsZero() {
+		return
+	}
+
+	stats.Policy = policy.Evaluate(stats, p)
+	if stats.Policy.Passed {
+		slog.Info("policy check passed", "image", image)
+		return
+	}
+
+	err := &policy.ViolationError{Image: image, Report: stats.Policy}
+	slog.Warn("policy check failed", "image", image, "violations", len(stats.Policy.Violations()))
+	r.mu.Lock()
+	r.violations = append(r.violations, err)
+	r.mu.Unlock()
+}
+
+// checkDrift compares the Dockerfile documentation with the image config and
+// records a failure when findings match drift.failOn.
+func (r *yamlRun) checkDrift(image string, doc *parser.Documentation, stats *types.ImageStats) {
+	dc := r.cfg.Drift
+	if dc == nil || doc == nil || stats == nil || stats.Config == nil {
+		return
+	}
+
+	stats.Drift = drift.Detect(doc, stats.Config, dc.Ignore)
+	for _, f := range stats.Drift {
+		slog.Warn("Dockerfile drift", "image", image, "finding", f.Message())
+	}
+
+	if failing := drift.Failing(stats.Drift, dc.FailOn); len(failing) > 0 {
+		r.mu.Lock()
+		r.violations = append(r.violations, &drift.Error{Image: image, Findings: failing})
+		r.mu.Unlock()
+	}
+}
+
+// baseRef returns the base image an image section is compared with: the
+// section's baseImage, or else the final-stage FROM of its Dockerfile doc.
+// It returns "" when base image comparison is not enabled for the section.
+func baseRef(section config.Section, doc *parser.Documentation) string {
+	switch {
+	case section.BaseImage != "":
+		return section.BaseImage
+	case !compareBase && !section.CompareBase:
+		return ""
+	case doc == nil || doc.BaseImage == "":
+		slog.Info("no base image to compare with", "image", section.Tag)
+		return ""
+	}
+	return doc.BaseImage
+}
+
+// baseSection returns the section that analyzes the base image ref of
+// section, with the same runner settings so the two analyses are comparable.
+func baseSection(section config.Section, ref string) config.Section {
+	return config.Section{Type: config.SectionTypeImage, Tag: ref, Runners: section.Runners}
+}
+
+// compareWithBase analyzes the base image of an image section and records
+// what stats inherits from it and what its own layers introduce.
+func (r *yamlRun) compareWithBase(ctx context.Context, section config.Section, doc *parser.Documentation, stats *types.ImageStats) error {
+	if stats == nil {
+		return nil
+	}
+	ref := baseRef(section, doc)
+	if ref == "" {
+		return nil
+	}
+	base, err := r.analyzeImage(ctx, baseSection(section, ref))
+	if err != nil {
+		return fmt.Errorf("base image %s: %w", ref, err)
+	}
+	recordBase(stats, base, ref)
+	return nil
+}
+
+// recordBase compares stats with the analysis of its base image ref, which
+// may be nil, and warns when the base image is stale.
+func recordBase(stats, base *types.ImageStats, ref string) {
+	if base == nil {
+		return
+	}
+	stats.Base = baseimage.Compare(stats, base, ref, time.Now())
+	if stats.Base.Stale {
+		slog.Warn("base image is stale", "image", stats.ImageTag, "base", ref, "recommendations", stats.Base.Recommendations)
+	}
+}
+
+// comparisonDocs parses the Dockerfile of every comparison entry that has one,
+// keyed by image tag. It is only needed for drift detection.
+func (r *yamlRun) comparisonDocs(entries []config.ImageEntry) map[string]*parser.Documentation {
+	if r.cfg.Drift == nil {
+		return nil
+	}
+	docs := make(map[string]*parser.Documentation, len(entries))
+	for _, entry := range entries {
+		if entry.Source == "" {
+			continue
+		}
+		doc, err := parser.Parse(entry.Source)
+		if err != nil {
+			slog.Warn("skipping drift check, failed to parse Dockerfile", "image", entry.Tag, "source", entry.Source, "error", err)
+			continue
+		}
+		docs[entry.Tag] = doc
+	}
+	return docs
+}
+
+// postProcess applies the config-driven steps that run after analysis:
+// package classification, end-of-life detection, suppressions, license flags
+// and the policy gate. The policy is evaluated last so it sees the final,
+// suppressed results.
+func (r *yamlRun) postProcess(section config.Section, image string, stats *types.ImageStats) {
+	if stats == nil {
+		return
+	}
+	r.catalog.Classify(stats)
+	r.applySuppressions(stats)
+	for _, ps := range stats.Platforms {
+		r.catalog.Classify(ps)
+		r.applySuppressions(ps)
+	}
+	checkLifecycle(r.lifecycle, stats, image)
+	r.flagLicenses(stats)
+	r.checkPolicy(section, image, stats)
+}
+
+// analyzeImage returns the raw analysis of an image section: imported from
+// the section's reports, looked up in the saved results, or produced by
+// running the analysis runners, in that order of preference. Errors are only
+// returned when --ignore-errors is not set; otherwise the (possibly nil or
+// partial) stats are returned.
+func (r *yamlRun) analyzeImage(ctx context.Context, section config.Section) (*types.ImageStats, error) {
+	if len(section.Reports) > 0 {
+		return importReports(section.Tag, section.Reports)
+	}
+
+	if r.results != nil {
+		stats, err := r.results.Lookup(section.Tag)
+		if err != nil && !ignoreErrors {
+			return nil, err
+		}
+		if err != nil {
+			slog.Warn("saved results unavailable", "image", section.Tag, "error", err)
+		}
+		return stats, nil
+	}
+
+	slog.Info("analyzing image", "image", section.Tag)
+	a := r.analyzer(section)
+	stats, err := a.registry.AnalyzeImage(ctx, section.Tag, a.newRunners, verbose)
+	if err != nil {
+		slog.Warn("analysis failed", "image", section.Tag, "error", err)
+		if !ignoreErrors {
+			return nil, fmt.Errorf("analysis failed for %s: %w", section.Tag, err)
+		}
+	}
+	if (perPlatform || section.PerPlatform) && stats != nil {
+		analyzePlatforms(ctx, a.registry, stats, a.newRunners)
+	}
+	return stats, nil
+}
+
+// importReports imports an image's analysis from existing reports.
+func importReports(image string, reports []string) (*types.ImageStats, error) {
+	slog.Info("importing reports", "image", image, "reports", reports)
+	stats, err := analysis.ImportReports(image, reports)
+	if err != nil {
+		slog.Warn("report import failed", "image", image, "error", err)
+		if !ignoreErrors {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// analyzeComparison returns the raw analysis of every image in a comparison
+// section, in entry order. Entries with reports are imported; the others are
+// analyzed together (or looked up in the saved results). Like
+// AnalyzeComparison, images that cannot be analyzed are logged and left out;
+// reports that cannot be imported and images missing from the saved results
+// are errors unless --ignore-errors is set.
+func (r *yamlRun) analyzeComparison(ctx context.Context, section config.Section, entries []config.ImageEntry) ([]*types.ImageStats, error) {
+	var tags []string
+	for _, entry := range entries {
+		if len(entry.Reports) == 0 {
+			tags = append(tags, entry.Tag)
+		}
+	}
+	analyzed := make(map[string]*types.ImageStats, len(tags))
+	if len(tags) > 0 {
+		statsList, err := r.analyzeTags(ctx, section, tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, stats := range statsList {
+			analyzed[stats.ImageTag] = stats
+		}
+	}
+
+	var statsList []*types.ImageStats
+	for _, entry := range entries {
+		if len(entry.Reports) == 0 {
+			if stats := analyzed[entry.Tag]; stats != nil {
+				statsList = append(statsList, stats)
+			}
+			continue
+		}
+		stats, err := importReports(entry.Tag, entry.Reports)
+		if err != nil {
+			return nil, err
+		}
+		if stats != nil {
+			statsList = append(statsList, stats)
+		}
+	}
+	return statsList, nil
+}
+
+// analyzeTags analyzes the tags of a comparison section, or looks them up in
+// the saved results.
+func (r *yamlRun) analyzeTags(ctx context.Context, section config.Section, tags []string) ([]*types.ImageStats, error) {
+	if r.results != nil {
+		var statsList []*types.ImageStats
+		for _, tag := range tags {
+			stats, err := r.results.Lookup(tag)
+			if err != nil {
+				if !ignoreErrors {
+					return nil, err
+				}
+				slog.Warn("saved results unavailable", "image", tag, "error", err)
+				continue
+			}
+			statsList = append(statsList, stats)
+		}
+		return statsList, nil
+	}
+
+	slog.Info("analyzing comparison", "images", tags)
+	a := r.analyzer(section)
+	statsList, err := a.registry.AnalyzeComparison(ctx, tags, a.newRunners, verbose)
+	if err != nil {
+		return nil, fmt.Errorf("comparison analysis failed: %w", err)
+	}
+	if perPlatform || section.PerPlatform {
+		for _, stats := range statsList {
+			analyzePlatforms(ctx, a.registry, stats, a.newRunners)
+		}
+	}
+	return statsList, nil
+}
+
+// analyzePlatforms adds per-platform results to stats, analyzing each
+// platform through registry (which may be nil). Per-platform data is
+// supplementary, so failures are logged rather than returned.
+func analyzePlatforms(ctx context.Context, registry *analysis.Registry, stats *types.ImageStats, newRunners func() []analysis.Runner) {
+	slog.Info("analyzing platforms", "image", stats.ImageTag)
+	if err := registry.AnalyzePlatforms(ctx, stats, newRunners, verbose); err != nil {
+		slog.Warn("per-platform analysis failed", "image", stats.ImageTag, "error", err)
+	}
+}
+
+// applySuppressions moves accepted risks out of the active vulnerability list.
+func (r *yamlRun) applySuppressions(stats *types.ImageStats) {
+	if n := suppression.Apply(stats, r.suppressions); n > 0 {
+		slog.Info("suppressed vulnerabilities", "image", stats.ImageTag, "count", n)
+	}
+}
+
 // sectionResult holds the rendered content and metadata for a processed section.
 type sectionResult struct {
 	index   int    // original section index (for resolveSectionOutput)
@@ -49,6 +641,71 @@ type indexedSection struct {
 	format  string
 }
 
+// newYAMLRun returns the analysis state for cfg. With saved results
+// (--from-results) no image is analyzed, so neither the container runtime
+// nor the analysis cache is set up. Rendering state (suppressions,
+// catalogue) is set by the caller.
+func newYAMLRun(ctx context.Context, cfg *config.Config, saved *results.File) (*yamlRun, error) {
+	run := &yamlRun{
+		release: func() {},
+		cfg:     cfg,
+		renderOpts: renderer.RenderOptions{
+			NoMoji:       noMoji,
+			BadgeBaseURL: cfg.BadgeBaseURL,
+		},
+		// A single limiter is shared by every section of the run.
+		limiter: newLimiter(cfg.Concurrency),
+		results: saved,
+	}
+	if saved != nil {
+		return run, nil
+	}
+
+	release, err := configureRuntime(ctx, cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	store, err := newCacheStore(cfg.Cache)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	run.release, run.store = release, store
+	return run, nil
+}
+
+// sectionAnalyzer analyzes images with the runners of one runner setting.
+type sectionAnalyzer struct {
+	newRunners func() []analysis.Runner
+	// registry analyzes each image once per run, whichever sections
+	// reference it.
+	registry *analysis.Registry
+}
+
+// analyzer returns the analyzer for the runner settings of section. Sections
+// with the same settings share an analyzer, so an image they have in common
+// is analyzed once; sections with different settings (e.g., dive disabled)
+// analyze it separately.
+func (r *yamlRun) analyzer(section config.Section) *sectionAnalyzer {
+	settings := r.cfg.ResolveRunners(section)
+	key, _ := json.Marshal(settings) // map keys are sorted, so equal settings encode equally
+
+	r.analyzersMu.Lock()
+	defer r.analyzersMu.Unlock()
+	if a, ok := r.analyzers[string(key)]; ok {
+		return a
+	}
+	if r.analyzers == nil {
+		r.analyzers = make(map[string]*sectionAnalyzer)
+	}
+	a := &sectionAnalyzer{
+		newRunners: runnerFactory(r.cfg.Plugins, settings, r.store, r.limiter),
+		registry:   analysis.NewRegistry(),
+	}
+	r.analyzers[string(key)] = a
+	return a
+}
+
 func runYAMLMode(ctx context.Context, path string) error {
 	slog.Info("using config file", "path", path)
 	cfg, err := config.Load(path)
@@ -62,11 +719,38 @@ func runYAMLMode(ctx context.Context, path string) error {
 	configDir := filepath.Dir(path)
 	cfg.ResolveRelativePaths(configDir)
 
-	renderOpts := renderer.RenderOptions{
-		NoMoji:       noMoji,
-		BadgeBaseURL: cfg.BadgeBaseURL,
+	rules, err := loadSuppressions(cfg.Suppressions, path, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cat, err := newCatalog(cfg.Frameworks)
+	if err != nil {
+		return err
+	}
+
+	lc, err := newLifecycle(cfg.Lifecycle)
+	if err != nil {
+		return err
+	}
+
+	var saved *results.File
+	if fromResults != "" {
+		slog.Info("rendering from saved results", "path", fromResults)
+		if saved, err = results.Load(fromResults); err != nil {
+			return err
+		}
 	}
 
+	run, err := newYAMLRun(ctx, cfg, saved)
+	if err != nil {
+		return err
+	}
+	defer run.release()
+	run.suppressions = rules
+	run.catalog = cat
+	run.lifecycle = lc
+
 	// Partition sections into direct-write (html/json) and markdown-inject groups.
 	// Direct-write sections write to independent files and can run in parallel.
 	// Markdown sections inject into a shared output file and must be sequential.
@@ -93,7 +777,7 @@ func runYAMLMode(ctx context.Context, path string) error {
 	g, gctx := errgroup.WithContext(ctx)
 	for _, is := range directWriteSections {
 		g.Go(func() error {
-			content, err := processSection(gctx, is.section, is.tmplSel, is.format, renderOpts)
+			content, err := run.processSection(gctx, is.section, is.tmplSel, is.format)
 			if err != nil {
 				return err
 			}
@@ -134,18 +818,21 @@ func runYAMLMode(ctx context.Context, path string) error {
 
 	// Process markdown sections sequentially (they share the output file).
 	if len(markdownSections) > 0 {
-		if err := processMarkdownSections(ctx, cfg.Output, markdownSections, renderOpts); err != nil {
+		if err := run.processMarkdownSections(ctx, markdownSections); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// Documentation is written even when the security gate fails so the
+	// policy report is visible; the failure is surfaced through the exit code.
+	return errors.Join(run.violations...)
 }
 
 // processMarkdownSections renders markdown sections sequentially and injects
 // them into the shared output file. Markdown sections cannot be parallelized
 // because they all inject into the same document.
-func processMarkdownSections(ctx context.Context, outputPath string, sections []indexedSection, renderOpts renderer.RenderOptions) error {
+func (r *yamlRun) processMarkdownSections(ctx context.Context, sections []indexedSection) error {
+	outputPath := r.cfg.Output
 	content, err := os.ReadFile(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to read output file %s: %w", outputPath, err)
@@ -153,7 +840,7 @@ func processMarkdownSections(ctx context.Context, outputPath string, sections []
 	fileContent := string(content)
 
 	for _, is := range sections {
-		sectionContent, err := processSection(ctx, is.section, is.tmplSel, is.format, renderOpts)
+		sectionContent, err := r.processSection(ctx, is.section, is.tmplSel, is.format)
 		if err != nil {
 			return err
 		}
@@ -183,9 +870,7 @@ func processMarkdownSections(ctx context.Context, outputPath string, sections []
 
 // processSection renders a single config section and returns the rendered content.
 // Returns empty string for sections that should be skipped (e.g., empty comparison, unknown type).
-func processSection(ctx context.Context, section config.Section, tmplSel renderer.TemplateSelection, format string, renderOpts renderer.RenderOptions) (string, error) {
-	runners := newRunners()
-
+func (r *yamlRun) processSection(ctx context.Context, section config.Section, tmplSel renderer.TemplateSelection, format string) (string, error) {
 	switch section.Type {
 	case config.SectionTypeImage:
 		// Parse Dockerfile
@@ -200,14 +885,19 @@ func processSection(ctx context.Context, section config.Section, tmplSel rendere
 
 		// Analyze Image (optional)
 		var stats *types.ImageStats
-		if section.Tag != "" {
-			slog.Info("analyzing image", "image", section.Tag)
-			stats, err = analysis.AnalyzeImage(ctx, section.Tag, runners, verbose)
+		if section.Tag != "" || len(section.Reports) > 0 {
+			stats, err = r.analyzeImage(ctx, section)
 			if err != nil {
-				slog.Warn("analysis failed", "image", section.Tag, "error", err)
-				if !ignoreErrors {
-					return "", fmt.Errorf("analysis failed for %s: %w", section.Tag, err)
-				}
+				return "", err
+			}
+			image := section.Tag
+			if image == "" && stats != nil {
+				image = stats.ImageTag
+			}
+			r.postProcess(section, image, stats)
+			r.checkDrift(image, doc, stats)
+			if err := r.compareWithBase(ctx, section, doc, stats); err != nil {
+				return "", err
 			}
 		}
 
@@ -215,7 +905,7 @@ func processSection(ctx context.Context, section config.Section, tmplSel rendere
 			slog.Debug("template resolved", "template", describeTemplate(tmplSel), "type", "image", "format", format)
 		}
 
-		content, err := renderer.RenderWithTemplate(doc, stats, renderOpts, tmplSel)
+		content, err := renderer.RenderWithTemplate(doc, stats, r.renderOpts, tmplSel)
 		if err != nil {
 			return "", fmt.Errorf("failed to render image section: %w", err)
 		}
@@ -226,24 +916,22 @@ func processSection(ctx context.Context, section config.Section, tmplSel rendere
 			return "", nil
 		}
 
-		// Extract tags from ImageEntry structs for analysis
 		resolvedImages := section.ResolvedImages()
-		tags := make([]string, len(resolvedImages))
-		for j, entry := range resolvedImages {
-			tags[j] = entry.Tag
-		}
-
-		slog.Info("analyzing comparison", "images", tags)
-		statsList, err := analysis.AnalyzeComparison(ctx, tags, newRunners, verbose)
+		statsList, err := r.analyzeComparison(ctx, section, resolvedImages)
 		if err != nil {
-			return "", fmt.Errorf("comparison analysis failed: %w", err)
+			return "", err
+		}
+		docs := r.comparisonDocs(resolvedImages)
+		for _, stats := range statsList {
+			r.postProcess(section, stats.ImageTag, stats)
+			r.checkDrift(stats.ImageTag, docs[stats.ImageTag], stats)
 		}
 
 		if debugTemplate {
 			slog.Debug("template resolved", "template", describeTemplate(tmplSel), "type", "comparison", "format", format)
 		}
 
-		content, err := renderer.RenderComparisonWithTemplate(statsList, renderOpts, tmplSel)
+		content, err := renderer.RenderComparisonWithTemplate(statsList, r.renderOpts, tmplSel)
 		if err != nil {
 			return "", fmt.Errorf("failed to render comparison section: %w", err)
 		}
@@ -7,11 +7,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/analysis"
+	"github.com/northcutted/dock-docs/pkg/config"
+	"github.com/northcutted/dock-docs/pkg/drift"
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/types"
 )
 
 func TestRunYAMLMode_ImageSection_DryRun(t *testing.T) {
@@ -449,3 +459,344 @@ sections:
 		t.Errorf("expected 'analyzing comparison' in log output, got:\n%s", logOut)
 	}
 }
+
+func TestRunnerFactory_AppendsPlugins(t *testing.T) {
+	factory := runnerFactory([]config.PluginConfig{
+		{Name: "licenses", Command: "license-check"},
+	}, nil, nil, nil)
+
+	first := factory()
+	second := factory()
+	if len(first) != len(newRunners())+1 {
+		t.Fatalf("expected built-in runners plus 1 plugin, got %d runners", len(first))
+	}
+	if got := first[len(first)-1].Name(); got != "licenses" {
+		t.Errorf("last runner = %q, want licenses", got)
+	}
+	if first[len(first)-1] == second[len(second)-1] {
+		t.Error("expected fresh plugin runner instances per call")
+	}
+}
+
+func TestRunnerFactory_Settings(t *testing.T) {
+	disabled := false
+	plugins := []config.PluginConfig{{Name: "licenses", Command: "license-check", Args: []string{"--fast"}, Timeout: time.Minute}}
+	factory := runnerFactory(plugins, map[string]config.RunnerConfig{
+		"dive":     {Enabled: &disabled},
+		"grype":    {Timeout: 10 * time.Minute, Args: []string{"--only-fixed"}},
+		"manifest": {Timeout: 5 * time.Second},
+		"licenses": {Args: []string{"--strict"}},
+		"syft":     {Retries: 2},
+	}, nil, nil)
+
+	byName := make(map[string]analysis.Runner)
+	for _, r := range factory() {
+		byName[r.Name()] = r
+	}
+	if _, skipped := analysis.SkipReason(byName["dive"]); !skipped {
+		t.Error("disabled runner dive should be skipped")
+	}
+	if g, ok := byName["grype"].(*runner.GrypeRunner); !ok || g.Timeout != 10*time.Minute || !slices.Equal(g.Args, []string{"--only-fixed"}) {
+		t.Errorf("grype = %+v, want timeout and args applied", byName["grype"])
+	}
+	if m, ok := byName["manifest"].(*runner.ManifestRunner); !ok || m.Timeout != 5*time.Second {
+		t.Errorf("manifest = %+v, want timeout applied", byName["manifest"])
+	}
+	if _, ok := byName["syft"].(*runner.SyftRunner); ok {
+		t.Error("syft should be wrapped with retries")
+	}
+	p, ok := byName["licenses"].(*runner.PluginRunner)
+	if !ok || !slices.Equal(p.Args, []string{"--fast", "--strict"}) || p.Timeout != time.Minute {
+		t.Errorf("licenses = %+v, want plugin args extended and timeout kept", byName["licenses"])
+	}
+	if !slices.Equal(plugins[0].Args, []string{"--fast"}) {
+		t.Errorf("plugin config args modified: %v", plugins[0].Args)
+	}
+}
+
+func TestYAMLRun_AnalyzerPerRunnerSettings(t *testing.T) {
+	disabled := false
+	run := &yamlRun{cfg: &config.Config{Runners: map[string]config.RunnerConfig{"grype": {Retries: 1}}}}
+	main := config.Section{Type: config.SectionTypeImage, Tag: "app:1"}
+	other := config.Section{Type: config.SectionTypeComparison}
+	noDive := config.Section{Type: config.SectionTypeImage, Runners: map[string]config.RunnerConfig{"dive": {Enabled: &disabled}}}
+
+	if run.analyzer(main) != run.analyzer(other) {
+		t.Error("sections with the same runner settings should share an analyzer")
+	}
+	if run.analyzer(main) == run.analyzer(noDive) {
+		t.Error("sections with different runner settings should not share an analyzer")
+	}
+}
+
+func TestConfigureRuntime(t *testing.T) {
+	defer resetFlags()()
+
+	// Install fake podman and nerdctl binaries so the selection is available.
+	dir := t.TempDir()
+	for _, name := range []string{"podman", "nerdctl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		name    string
+		env     string
+		cfg     *config.RuntimeConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "auto-detect", want: "podman"},
+		{name: "config", cfg: &config.RuntimeConfig{Backend: "nerdctl"}, want: "nerdctl"},
+		{name: "env overrides config", env: "podman", cfg: &config.RuntimeConfig{Backend: "nerdctl"}, want: "podman"},
+		{name: "remote host", cfg: &config.RuntimeConfig{Backend: "podman", Host: "tcp://build:2376"}, want: "podman"},
+		{name: "unknown backend", env: "lxc", wantErr: true},
+		{name: "missing docker context", cfg: &config.RuntimeConfig{Backend: "podman", Context: "remote"}, wantErr: true},
+		{name: "pull policy", cfg: &config.RuntimeConfig{Backend: "podman", Pull: "never", Platform: "linux/arm64"}, want: "podman"},
+		{name: "invalid platform", cfg: &config.RuntimeConfig{Backend: "podman", Platform: "arm64"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(runner.RuntimeEnv, tt.env)
+			release, err := configureRuntime(context.Background(), tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configureRuntime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			release()
+			b, err := runner.CurrentBackend()
+			if err != nil || b.Name() != tt.want {
+				t.Errorf("CurrentBackend() = (%v, %v), want %s", b, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	t.Setenv(registryUsernameEnv, "default-user")
+	t.Setenv(registryPasswordEnv, "default-pass")
+	t.Setenv("CI_USER", "ci-user")
+	t.Setenv("CI_TOKEN", "ci-token")
+
+	tests := []struct {
+		name string
+		auth *config.RegistryAuthConfig
+		want runner.Credentials
+	}{
+		{
+			name: "default variables",
+			want: runner.Credentials{Username: "default-user", Password: "default-pass"},
+		},
+		{
+			name: "configured variables",
+			auth: &config.RegistryAuthConfig{DockerConfig: "/ci/docker", Registry: "ghcr.io", UsernameEnv: "CI_USER", PasswordEnv: "CI_TOKEN"},
+			want: runner.Credentials{DockerConfig: "/ci/docker", Registry: "ghcr.io", Username: "ci-user", Password: "ci-token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registryCredentials(tt.auth); got != tt.want {
+				t.Errorf("registryCredentials() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureRuntime_PullFlags(t *testing.T) {
+	defer resetFlags()()
+	runner.SetBackend(nil)
+	fake := &runner.FakeBackend{Images: map[string]*types.ImageStats{"app:1": {}}, Local: map[string]bool{"app:1": true}}
+
+	// --pull overrides runtime.pull.
+	pullPolicy = "always"
+	pullPlatform = "linux/arm64"
+	release, err := configureRuntime(context.Background(), &config.RuntimeConfig{Pull: "never", Platform: "linux/amd64"})
+	if err != nil {
+		t.Fatalf("configureRuntime() error: %v", err)
+	}
+	defer release()
+	runner.SetBackend(fake)
+
+	if err := runner.EnsureImage(context.Background(), "app:1", false); err != nil {
+		t.Fatalf("EnsureImage() error: %v", err)
+	}
+	if got := fake.PullPlatforms(); len(got) != 1 || got[0] != "linux/arm64" {
+		t.Errorf("pull platforms = %v, want [linux/arm64]", got)
+	}
+
+	pullPolicy = "sometimes"
+	if _, err := configureRuntime(context.Background(), nil); err == nil {
+		t.Error("configureRuntime() expected error for an unknown --pull policy")
+	}
+}
+
+func TestLoadSuppressions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	vexPath := filepath.Join(t.TempDir(), "app.openvex.json")
+	vex := `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [
+		{"vulnerability": {"name": "CVE-3"}, "products": ["pkg:oci/app"], "status": "not_affected", "justification": "component_not_present"}
+	]}`
+	if err := os.WriteFile(vexPath, []byte(vex), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &config.SuppressionConfig{
+		Ignore: []config.SuppressionRule{
+			{ID: "CVE-1", Justification: "accepted", Expires: config.Date{Time: now.AddDate(0, 1, 0)}},
+			{ID: "CVE-2", Justification: "accepted", Expires: config.Date{Time: now.AddDate(0, -1, 0)}},
+		},
+		VEX: []string{vexPath},
+	}
+	rules, err := loadSuppressions(sc, "dock-docs.yaml", now)
+	if err != nil {
+		t.Fatalf("loadSuppressions() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].ID != "CVE-1" || rules[1].ID != "CVE-3" {
+		t.Fatalf("expected active rules [CVE-1 CVE-3], got %+v", rules)
+	}
+	if rules[0].Source != "dock-docs.yaml" || rules[1].Source != vexPath {
+		t.Errorf("unexpected rule sources: %q, %q", rules[0].Source, rules[1].Source)
+	}
+
+	if rules, err := loadSuppressions(nil, "dock-docs.yaml", now); err != nil || rules != nil {
+		t.Errorf("loadSuppressions(nil) = %v, %v; want nil, nil", rules, err)
+	}
+
+	bad := &config.SuppressionConfig{Ignore: []config.SuppressionRule{{ID: "CVE-1", Justification: "x", Versions: ">="}}}
+	if _, err := loadSuppressions(bad, "dock-docs.yaml", now); err == nil {
+		t.Error("expected error for invalid version constraint")
+	}
+	missing := &config.SuppressionConfig{VEX: []string{filepath.Join(t.TempDir(), "missing.json")}}
+	if _, err := loadSuppressions(missing, "dock-docs.yaml", now); err == nil {
+		t.Error("expected error for missing VEX document")
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	maxCritical := 0
+	run := &yamlRun{cfg: &config.Config{Policy: &config.PolicyConfig{MaxCritical: &maxCritical}}}
+	section := config.Section{Type: config.SectionTypeImage, Marker: "main", Tag: "app:latest"}
+
+	clean := &types.ImageStats{VulnScanTime: time.Now(), VulnSummary: map[string]int{}}
+	run.checkPolicy(section, "clean:latest", clean)
+	if clean.Policy == nil || !clean.Policy.Passed {
+		t.Fatalf("expected passing policy report, got %+v", clean.Policy)
+	}
+
+	vulnerable := &types.ImageStats{VulnScanTime: time.Now(), VulnSummary: map[string]int{"Critical": 2}}
+	run.checkPolicy(section, "app:latest", vulnerable)
+	if vulnerable.Policy == nil || vulnerable.Policy.Passed {
+		t.Fatalf("expected failing policy report, got %+v", vulnerable.Policy)
+	}
+	if len(run.violations) != 1 {
+		t.Fatalf("expected 1 recorded violation, got %d", len(run.violations))
+	}
+	if !strings.Contains(run.violations[0].Error(), "app:latest") {
+		t.Errorf("violation should name the image, got %q", run.violations[0].Error())
+	}
+
+	// Sections can opt out with an empty policy override.
+	relaxed := config.Section{Type: config.SectionTypeImage, Marker: "legacy", Policy: &config.PolicyConfig{}}
+	other := &types.ImageStats{VulnScanTime: time.Now(), VulnSummary: map[string]int{"Critical": 2}}
+	run.checkPolicy(relaxed, "legacy:latest", other)
+	if other.Policy != nil || len(run.violations) != 1 {
+		t.Errorf("empty section policy should disable the gate, got %+v", other.Policy)
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	doc := &parser.Documentation{Items: []parser.DocItem{
+		{Type: "ENV", Name: "APP_ENV", Value: "prod", Key: "APP_ENV", Literal: "prod"},
+		{Type: "EXPOSE", Name: "8080", Value: "8080", Key: "8080", Literal: "8080"},
+	}}
+	newStats := func() *types.ImageStats {
+		return &types.ImageStats{Config: &types.ImageConfig{
+			Env:          []string{"PATH=/usr/bin", "APP_ENV=prod"},
+			ExposedPorts: []string{"8080/tcp", "9090/tcp"},
+		}}
+	}
+
+	// Without a drift block nothing is checked.
+	run := &yamlRun{cfg: &config.Config{}}
+	stats := newStats()
+	run.checkDrift("app:latest", doc, stats)
+	if stats.Drift != nil {
+		t.Fatalf("expected no drift check without config, got %+v", stats.Drift)
+	}
+
+	run = &yamlRun{cfg: &config.Config{Drift: &config.DriftConfig{Ignore: []string{"PATH"}}}}
+	stats = newStats()
+	run.checkDrift("app:latest", doc, stats)
+	if len(stats.Drift) != 1 || stats.Drift[0].Name != "9090/tcp" || stats.Drift[0].Kind != types.DriftUndocumented {
+		t.Fatalf("unexpected drift findings: %+v", stats.Drift)
+	}
+	if len(run.violations) != 0 {
+		t.Errorf("drift should only warn without failOn, got %v", run.violations)
+	}
+
+	run.cfg.Drift.FailOn = []string{types.DriftUndocumented}
+	run.checkDrift("app:latest", doc, newStats())
+	var driftErr *drift.Error
+	if len(run.violations) != 1 || !errors.As(run.violations[0], &driftErr) {
+		t.Fatalf("expected a drift error, got %v", run.violations)
+	}
+}
+
+func TestRunYAMLMode_Reports(t *testing.T) {
+	defer resetFlags()()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "Dockerfile"), []byte("FROM alpine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sbom := `{"spdxVersion": "SPDX-2.3", "packages": [{"name": "musl", "versionInfo": "1.2.4", "licenseConcluded": "MIT"}]}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "sbom.spdx.json"), []byte(sbom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	trivy := `{"SchemaVersion": 2, "ArtifactName": "app:2", "Results": [{"Type": "alpine", "Vulnerabilities": [
+		{"VulnerabilityID": "CVE-2024-9999", "PkgName": "musl", "InstalledVersion": "1.2.4", "Severity": "CRITICAL"}]}]}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "trivy.json"), []byte(trivy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relative report paths resolve against the config directory.
+	yamlContent := `sections:
+  - type: image
+    marker: main
+    source: Dockerfile
+    tag: app:1
+    reports: [sbom.spdx.json]
+    template:
+      name: json
+  - type: comparison
+    marker: versions
+    images:
+      - reports: [trivy.json]
+    template:
+      name: json
+`
+	cfgPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun = true
+
+	// A cancelled context proves the images are not analyzed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output := captureOutput(func() {
+		if err := runYAMLMode(ctx, cfgPath); err != nil {
+			t.Fatalf("runYAMLMode() error: %v", err)
+		}
+	})
+	for _, want := range []string{`"musl"`, "CVE-2024-9999", "app:2"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
@@ -6,9 +6,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/license"
 	"github.com/northcutted/dock-docs/pkg/runner"
 	"github.com/northcutted/dock-docs/pkg/types"
 	"golang.org/x/sync/errgroup"
@@ -21,13 +26,86 @@ type Runner interface {
 	Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error)
 }
 
+// Skip returns r marked as skipped (e.g., disabled by configuration):
+// AnalyzeImage records it with the given reason instead of running it.
+func Skip(r Runner, reason string) Runner {
+	return &skippedRunner{Runner: r, reason: reason}
+}
+
+// SkipReason returns the reason r was skipped, and whether it was.
+func SkipReason(r Runner) (string, bool) {
+	if s, ok := r.(*skippedRunner); ok {
+		return s.reason, true
+	}
+	return "", false
+}
+
+// skippedRunner is a runner left out of the analysis.
+type skippedRunner struct {
+	Runner
+	reason string
+}
+
+// RunnerError is the failure of a single runner during an image analysis.
+type RunnerError struct {
+	Runner string
+	Err    error
+}
+
+func (e *RunnerError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Runner, e.Err)
+}
+
+func (e *RunnerError) Unwrap() error { return e.Err }
+
+// Error is returned by AnalyzeImage, along with the partial results, when
+// runners failed. errors.Is and errors.As see the error of every runner, so
+// callers can test for the kinds defined by pkg/runner (e.g.,
+// runner.ErrTimeout).
+type Error struct {
+	Image    string
+	Failures []*RunnerError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, f.Error())
+	}
+	return fmt.Sprintf("analysis failed for %d runners: %s", len(e.Failures), strings.Join(msgs, "; "))
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
 // AnalyzeComparison runs analysis on multiple images in parallel.
 // The newRunners factory is called once per goroutine so that each image
 // gets its own runner instances, avoiding data races on mutable state
 // (e.g., the binary field written by IsAvailable).
 // The provided context controls the overall deadline for the comparison;
-// individual runner timeouts are derived from this parent context.
+// individual runner timeouts are derived from this parent context. To bound
+// how many tool processes run at once, wrap the runners with a Limiter.
 func AnalyzeComparison(ctx context.Context, images []string, newRunners func() []Runner, verbose bool) ([]*types.ImageStats, error) {
+	return analyzeComparison(ctx, images, directAnalyzer(newRunners, verbose))
+}
+
+// analyzeFunc analyzes a single image.
+type analyzeFunc func(ctx context.Context, image string) (*types.ImageStats, error)
+
+// directAnalyzer returns an analyzeFunc running AnalyzeImage with fresh
+// runners from newRunners.
+func directAnalyzer(newRunners func() []Runner, verbose bool) analyzeFunc {
+	return func(ctx context.Context, image string) (*types.ImageStats, error) {
+		return AnalyzeImage(ctx, image, newRunners(), verbose)
+	}
+}
+
+func analyzeComparison(ctx context.Context, images []string, analyze analyzeFunc) ([]*types.ImageStats, error) {
 	var g errgroup.Group
 
 	// Create a slice to hold results
@@ -35,8 +113,7 @@ func AnalyzeComparison(ctx context.Context, images []string, newRunners func() [
 
 	for i, img := range images {
 		g.Go(func() error {
-			// Each goroutine gets fresh runner instances via the factory.
-			stats, err := AnalyzeImage(ctx, img, newRunners(), verbose)
+			stats, err := analyze(ctx, img)
 			if err != nil {
 				slog.Warn("comparison analysis failed", "image", img, "error", err)
 				if stats != nil {
@@ -68,6 +145,49 @@ func AnalyzeComparison(ctx context.Context, images []string, newRunners func() [
 // Runners are injected to allow easy testing/mocking or registration.
 var ensureImage = runner.EnsureImage
 
+// listPlatforms is swappable so tests can fake a manifest list.
+var listPlatforms = runner.ListPlatforms
+
+// AnalyzePlatforms analyzes every platform of a multi-arch image separately,
+// pinning each analysis to the platform's manifest digest, and stores the
+// results in stats.Platforms. Images that are not manifest lists are left
+// unchanged. Platforms whose analysis fails are logged and omitted.
+func AnalyzePlatforms(ctx context.Context, stats *types.ImageStats, newRunners func() []Runner, verbose bool) error {
+	return analyzePlatforms(ctx, stats, directAnalyzer(newRunners, verbose), verbose)
+}
+
+func analyzePlatforms(ctx context.Context, stats *types.ImageStats, analyze analyzeFunc, verbose bool) error {
+	platforms, err := listPlatforms(ctx, stats.ImageTag, verbose)
+	if err != nil {
+		return fmt.Errorf("failed to list platforms of %s: %w", stats.ImageTag, err)
+	}
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	var g errgroup.Group
+	results := make([]*types.ImageStats, len(platforms))
+	for i, p := range platforms {
+		g.Go(func() error {
+			ps, err := analyze(ctx, p.Ref(stats.ImageTag))
+			if err != nil {
+				slog.Warn("platform analysis failed", "image", stats.ImageTag, "platform", p.String(), "error", err)
+			}
+			results[i] = ps
+			return nil // Don't fail the group, partial success allowed
+		})
+	}
+	_ = g.Wait()
+
+	stats.Platforms = make(map[string]*types.ImageStats, len(platforms))
+	for i, p := range platforms {
+		if results[i] != nil {
+			stats.Platforms[p.String()] = results[i]
+		}
+	}
+	return nil
+}
+
 // AnalyzeImage runs all available runners against the given image and merges their results.
 // The provided context controls the overall deadline; individual runner timeouts
 // are derived from this parent context.
@@ -76,9 +196,13 @@ func AnalyzeImage(ctx context.Context, image string, runners []Runner, verbose b
 		return nil, fmt.Errorf("image tag is required")
 	}
 
+	events.Emit(ctx, events.Event{Kind: events.AnalysisStarted, Image: image, Runners: len(runners)})
+
 	// Ensure the image exists locally before analysis
 	if err := ensureImage(ctx, image, verbose); err != nil {
-		return nil, fmt.Errorf("failed to ensure image %s: %w", image, err)
+		err = fmt.Errorf("failed to ensure image %s: %w", image, err)
+		events.Emit(ctx, events.Event{Kind: events.AnalysisFinished, Image: image, Error: err.Error()})
+		return nil, err
 	}
 
 	finalStats := &types.ImageStats{
@@ -90,24 +214,42 @@ func AnalyzeImage(ctx context.Context, image string, runners []Runner, verbose b
 
 	var g errgroup.Group
 	var mu sync.Mutex
-	var errs []error
+	var errs []*RunnerError
+	statuses := make([]types.RunnerStatus, len(runners))
 
-	for _, r := range runners {
+	for i, r := range runners {
+		// The name is read before IsAvailable, which may refine it (e.g.,
+		// the runtime runner reports its backend afterwards).
+		statuses[i].Name = r.Name()
+		if reason, ok := SkipReason(r); ok {
+			statuses[i].Status, statuses[i].Message = types.RunnerSkipped, reason
+			emitFinished(ctx, image, statuses[i])
+			continue
+		}
 		if !r.IsAvailable() {
 			if verbose {
 				slog.Debug("tool not available, skipping", "runner", r.Name())
 			}
+			statuses[i].Status, statuses[i].Message = types.RunnerUnavailable, "not installed"
+			emitFinished(ctx, image, statuses[i])
 			continue
 		}
 
 		g.Go(func() error {
+			events.Emit(ctx, events.Event{Kind: events.RunnerStarted, Image: image, Runner: statuses[i].Name})
+			start := time.Now()
 			stats, err := r.Run(ctx, image, verbose)
+			statuses[i].Duration = time.Since(start).Round(time.Millisecond)
 			if err != nil {
+				statuses[i].Status, statuses[i].Message = types.RunnerFailed, err.Error()
+				emitFinished(ctx, image, statuses[i])
 				mu.Lock()
-				errs = append(errs, fmt.Errorf("%s failed: %w", r.Name(), err))
+				errs = append(errs, &RunnerError{Runner: r.Name(), Err: err})
 				mu.Unlock()
 				return nil // Don't fail the group; partial success is allowed
 			}
+			statuses[i].Status = types.RunnerOK
+			emitFinished(ctx, image, statuses[i])
 
 			mu.Lock()
 			mergeStats(finalStats, stats)
@@ -118,6 +260,7 @@ func AnalyzeImage(ctx context.Context, image string, runners []Runner, verbose b
 
 	// Wait for all goroutines (always returns nil since goroutines never return errors)
 	_ = g.Wait()
+	finalStats.Runners = statuses
 
 	// Log collected warnings
 	for _, err := range errs {
@@ -127,23 +270,136 @@ func AnalyzeImage(ctx context.Context, image string, runners []Runner, verbose b
 	// Final sort of vulnerabilities after merge
 	types.SortBySeverity(finalStats.Vulnerabilities)
 
+	// Licenses are aggregated after the merge so plugin-provided packages count too.
+	finalStats.LicenseSummary = license.Summarize(finalStats.Packages)
+
 	if len(errs) > 0 {
-		// Build a detailed error message listing failing runners
-		var errMsgs []string
-		for _, e := range errs {
-			errMsgs = append(errMsgs, e.Error())
+		err := &Error{Image: image, Failures: errs}
+		events.Emit(ctx, events.Event{Kind: events.AnalysisFinished, Image: image, Error: err.Error()})
+		return finalStats, err
+	}
+
+	events.Emit(ctx, events.Event{Kind: events.AnalysisFinished, Image: image})
+	return finalStats, nil
+}
+
+// emitFinished emits the RunnerFinished event of a runner.
+func emitFinished(ctx context.Context, image string, st types.RunnerStatus) {
+	e := events.Event{Kind: events.RunnerFinished, Image: image, Runner: st.Name, Status: st.Status, Duration: st.Duration}
+	if st.Status == types.RunnerFailed {
+		e.Error = st.Message
+	}
+	events.Emit(ctx, e)
+}
+
+// readReport is swappable so tests can supply reports without files.
+var readReport = runner.ReadReport
+
+// ImportReports builds the analysis of image from existing SBOM and scan
+// reports (SPDX, CycloneDX, syft, grype, trivy or SARIF JSON) instead of
+// running the analysis tools, so no container runtime is needed. Reports are
+// merged in order; packages and vulnerabilities found in several reports are
+// listed once. If image is empty, the image recorded in the reports is used.
+func ImportReports(image string, paths []string) (*types.ImageStats, error) {
+	finalStats := &types.ImageStats{
+		ImageTag:        image,
+		VulnSummary:     make(map[string]int),
+		Packages:        make([]types.PackageSummary, 0),
+		Vulnerabilities: make([]types.Vulnerability, 0),
+	}
+
+	covered := make(map[string]bool)
+	for _, path := range paths {
+		stats, format, err := readReport(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import report: %w", err)
+		}
+		slog.Debug("imported report", "path", path, "format", format, "packages", len(stats.Packages), "vulnerabilities", len(stats.Vulnerabilities))
+		if finalStats.ImageTag == "" {
+			finalStats.ImageTag = stats.ImageTag
 		}
-		return finalStats, fmt.Errorf("analysis failed for %d runners: %s", len(errs), strings.Join(errMsgs, "; "))
+		for _, r := range stats.Runners {
+			covered[r.Name] = true
+		}
+		mergeStats(finalStats, stats)
+	}
+
+	// Record which analyses the reports cover, so templates show n/a rather
+	// than a clean "0 vulnerabilities" when no scan report was given. Image
+	// efficiency (dive) is never part of a report.
+	for _, name := range []string{"syft", "grype", "dive"} {
+		st := types.RunnerStatus{Name: name, Status: types.RunnerOK}
+		if !covered[name] {
+			st.Status, st.Message = types.RunnerSkipped, "no report"
+		}
+		finalStats.Runners = append(finalStats.Runners, st)
 	}
 
+	finalStats.Packages = dedupe(finalStats.Packages, func(p types.PackageSummary) string {
+		return p.Name + "@" + p.Version
+	})
+	sort.SliceStable(finalStats.Packages, func(i, j int) bool {
+		return finalStats.Packages[i].Name < finalStats.Packages[j].Name
+	})
+	finalStats.TotalPackages = len(finalStats.Packages)
+
+	vulnKey := func(v types.Vulnerability) string {
+		return v.ID + "|" + v.Package + "@" + v.Version
+	}
+	// A finding triaged in one report (e.g., a dock-docs CycloneDX BOM) stays
+	// suppressed even if another report lists it as active.
+	finalStats.Suppressed = dedupe(finalStats.Suppressed, func(s types.SuppressedVulnerability) string {
+		return vulnKey(s.Vulnerability)
+	})
+	triaged := make(map[string]bool, len(finalStats.Suppressed))
+	for _, s := range finalStats.Suppressed {
+		triaged[vulnKey(s.Vulnerability)] = true
+	}
+	finalStats.Vulnerabilities = slices.DeleteFunc(dedupe(finalStats.Vulnerabilities, vulnKey), func(v types.Vulnerability) bool {
+		return triaged[vulnKey(v)]
+	})
+	finalStats.VulnSummary = make(map[string]int)
+	for _, v := range finalStats.Vulnerabilities {
+		finalStats.VulnSummary[v.Severity]++
+	}
+	types.SortBySeverity(finalStats.Vulnerabilities)
+
+	finalStats.LicenseSummary = license.Summarize(finalStats.Packages)
 	return finalStats, nil
 }
 
+// dedupe returns items with later duplicates (by key) removed.
+func dedupe[T any](items []T, key func(T) string) []T {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for _, item := range items {
+		k := key(item)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, item)
+	}
+	return out
+}
+
 func mergeStats(dest, src *types.ImageStats) {
 	if src == nil {
 		return
 	}
 
+	if src.RepoDigest != "" {
+		dest.RepoDigest = src.RepoDigest
+	}
+	if src.ImageID != "" {
+		dest.ImageID = src.ImageID
+	}
+	if !src.Created.IsZero() {
+		dest.Created = src.Created
+	}
+	if src.Config != nil {
+		dest.Config = src.Config
+	}
 	if src.Architecture != "" {
 		dest.Architecture = src.Architecture
 	}
@@ -177,6 +433,9 @@ func mergeStats(dest, src *types.ImageStats) {
 	if len(src.Vulnerabilities) > 0 {
 		dest.Vulnerabilities = append(dest.Vulnerabilities, src.Vulnerabilities...)
 	}
+	if len(src.Suppressed) > 0 {
+		dest.Suppressed = append(dest.Suppressed, src.Suppressed...)
+	}
 	if len(src.VulnSummary) > 0 {
 		for k, v := range src.VulnSummary {
 			dest.VulnSummary[k] += v
@@ -185,4 +444,10 @@ func mergeStats(dest, src *types.ImageStats) {
 	if !src.VulnScanTime.IsZero() {
 		dest.VulnScanTime = src.VulnScanTime
 	}
+	for plugin, fields := range src.Custom {
+		if dest.Custom == nil {
+			dest.Custom = make(map[string]map[string]any)
+		}
+		dest.Custom[plugin] = fields
+	}
 }
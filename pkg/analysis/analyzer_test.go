@@ -3,9 +3,16 @@ package analysis
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/runner"
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
@@ -232,6 +239,79 @@ func TestAnalyzeImage_MultipleRunners(t *testing.T) {
 	}
 }
 
+func TestAnalyzeImage_RunnerStatuses(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	runners := []Runner{
+		&MockRunner{name: "syft", available: true},
+		&MockRunner{name: "grype", available: true, shouldFail: true},
+		&MockRunner{name: "dive", available: false},
+		Skip(&MockRunner{name: "trivy", available: true}, "disabled in config"),
+	}
+	stats, err := AnalyzeImage(context.Background(), "test:latest", runners, false)
+	var analysisErr *Error
+	if !errors.As(err, &analysisErr) || len(analysisErr.Failures) != 1 || analysisErr.Failures[0].Runner != "grype" {
+		t.Errorf("Expected an *Error for the failed grype runner, got %v", err)
+	}
+	if stats == nil {
+		t.Fatal("Expected partial stats")
+	}
+
+	want := []types.RunnerStatus{
+		{Name: "syft", Status: types.RunnerOK},
+		{Name: "grype", Status: types.RunnerFailed, Message: "mock runner failed"},
+		{Name: "dive", Status: types.RunnerUnavailable, Message: "not installed"},
+		{Name: "trivy", Status: types.RunnerSkipped, Message: "disabled in config"},
+	}
+	if len(stats.Runners) != len(want) {
+		t.Fatalf("Expected %d runner statuses, got %+v", len(want), stats.Runners)
+	}
+	for i, w := range want {
+		got := stats.Runners[i]
+		got.Duration = 0
+		if got != w {
+			t.Errorf("Runners[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	if stats.Has("dive") || !stats.Has("syft") {
+		t.Errorf("Has() does not match the runner statuses: %+v", stats.Runners)
+	}
+}
+
+func TestAnalyzeImage_Events(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	var mu sync.Mutex
+	var got []string
+	ctx := events.WithHandler(context.Background(), func(e events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, fmt.Sprintf("%s %s %s", e.Kind, e.Runner, e.Status))
+	})
+	runners := []Runner{
+		&MockRunner{name: "syft", available: true},
+		&MockRunner{name: "dive", available: false},
+	}
+	if _, err := AnalyzeImage(ctx, "test:latest", runners, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"analysis_started  ",
+		"runner_finished dive unavailable",
+		"runner_started syft ",
+		"runner_finished syft ok",
+		"analysis_finished  ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
 func TestMergeStats_Comprehensive(t *testing.T) {
 	dest := &types.ImageStats{
 		ImageTag:    "test:latest",
@@ -243,6 +323,10 @@ func TestMergeStats_Comprehensive(t *testing.T) {
 	}
 
 	src := &types.ImageStats{
+		RepoDigest:             "test@sha256:abc",
+		ImageID:                "sha256:def",
+		Created:                time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Config:                 &types.ImageConfig{User: "app"},
 		Architecture:           "arm64",
 		OS:                     "linux",
 		OSDistro:               "alpine",
@@ -264,6 +348,12 @@ func TestMergeStats_Comprehensive(t *testing.T) {
 	mergeStats(dest, src)
 
 	// Verify all fields were merged
+	if dest.RepoDigest != "test@sha256:abc" || dest.ImageID != "sha256:def" || dest.Created.IsZero() {
+		t.Errorf("Expected digest, ID and creation time to be merged, got %q %q %v", dest.RepoDigest, dest.ImageID, dest.Created)
+	}
+	if dest.Config == nil || dest.Config.User != "app" {
+		t.Errorf("Expected Config to be merged, got %+v", dest.Config)
+	}
 	if dest.Architecture != "arm64" {
 		t.Errorf("Expected Architecture arm64, got %s", dest.Architecture)
 	}
@@ -355,3 +445,490 @@ func TestAnalyzeImage_VulnerabilitySorting(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeStats_Custom(t *testing.T) {
+	dest := &types.ImageStats{
+		Custom: map[string]map[string]any{"a": {"x": 1}},
+	}
+	mergeStats(dest, &types.ImageStats{
+		Custom: map[string]map[string]any{"b": {"y": 2}},
+	})
+
+	if dest.CustomField("a", "x") != 1 {
+		t.Errorf("expected existing custom field to be kept, got %v", dest.Custom)
+	}
+	if dest.CustomField("b", "y") != 2 {
+		t.Errorf("expected plugin custom field to be merged, got %v", dest.Custom)
+	}
+
+	empty := &types.ImageStats{}
+	mergeStats(empty, &types.ImageStats{Custom: map[string]map[string]any{"c": {"z": 3}}})
+	if empty.CustomField("c", "z") != 3 {
+		t.Errorf("expected custom map to be created, got %v", empty.Custom)
+	}
+}
+
+func TestAnalyzeImage_LicenseSummary(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	syft := &MockRunner{
+		name:      "syft",
+		available: true,
+		returnStats: &types.ImageStats{
+			Packages: []types.PackageSummary{
+				{Name: "musl", Licenses: []string{"MIT"}},
+				{Name: "busybox", Licenses: []string{"GPL-2.0-only"}},
+			},
+		},
+	}
+	plugin := &MockRunner{
+		name:      "internal",
+		available: true,
+		returnStats: &types.ImageStats{
+			Packages: []types.PackageSummary{{Name: "internal-sdk", Licenses: []string{"MIT"}}},
+		},
+	}
+
+	stats, err := AnalyzeImage(context.Background(), "test:latest", []Runner{syft, plugin}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stats.LicenseSummary) != 2 {
+		t.Fatalf("expected 2 licenses, got %+v", stats.LicenseSummary)
+	}
+	if top := stats.LicenseSummary[0]; top.License != "MIT" || top.Packages != 2 {
+		t.Errorf("expected MIT with 2 packages first, got %+v", top)
+	}
+}
+
+func TestAnalyzePlatforms(t *testing.T) {
+	oldEnsureImage, oldListPlatforms := ensureImage, listPlatforms
+	defer func() { ensureImage, listPlatforms = oldEnsureImage, oldListPlatforms }()
+	ensureImage = func(_ context.Context, image string, _ bool) error {
+		if strings.HasSuffix(image, "sha256:bad") {
+			return errors.New("pull failed")
+		}
+		return nil
+	}
+	listPlatforms = func(_ context.Context, image string, _ bool) ([]runner.Platform, error) {
+		if image == "single:latest" {
+			return nil, nil
+		}
+		return []runner.Platform{
+			{OS: "linux", Architecture: "amd64", Digest: "sha256:aaa"},
+			{OS: "linux", Architecture: "arm", Variant: "v7", Digest: "sha256:bad"},
+			{OS: "linux", Architecture: "arm64", Digest: "sha256:bbb"},
+		}, nil
+	}
+	newRunners := func() []Runner {
+		return []Runner{&MockRunner{name: "TestRunner", available: true, returnStats: &types.ImageStats{SizeBytes: 1024}}}
+	}
+
+	stats := &types.ImageStats{ImageTag: "localhost:5000/app:1.0"}
+	if err := AnalyzePlatforms(context.Background(), stats, newRunners, false); err != nil {
+		t.Fatalf("AnalyzePlatforms() error = %v", err)
+	}
+	if len(stats.Platforms) != 2 {
+		t.Fatalf("expected 2 platforms (failed one omitted), got %v", stats.Platforms)
+	}
+	if got := stats.Platforms["linux/arm64"].ImageTag; got != "localhost:5000/app@sha256:bbb" {
+		t.Errorf("linux/arm64 analyzed %q, want the digest-pinned reference", got)
+	}
+
+	single := &types.ImageStats{ImageTag: "single:latest"}
+	if err := AnalyzePlatforms(context.Background(), single, newRunners, false); err != nil {
+		t.Fatalf("AnalyzePlatforms(single) error = %v", err)
+	}
+	if single.Platforms != nil {
+		t.Errorf("single-platform image should have no platforms, got %v", single.Platforms)
+	}
+}
+
+func TestImportReports(t *testing.T) {
+	oldReadReport := readReport
+	defer func() { readReport = oldReadReport }()
+	reports := map[string]*types.ImageStats{
+		"sbom.json": {
+			ImageTag: "registry/app:1",
+			OSDistro: "alpine 3.19.1",
+			Packages: []types.PackageSummary{
+				{Name: "zlib", Version: "1.3", Licenses: []string{"Zlib"}},
+				{Name: "musl", Version: "1.2.4", Licenses: []string{"MIT"}},
+			},
+			TotalPackages: 2,
+			Runners:       []types.RunnerStatus{{Name: "syft", Status: types.RunnerOK}},
+		},
+		"grype.json": {
+			Packages: []types.PackageSummary{{Name: "musl", Version: "1.2.4"}},
+			Vulnerabilities: []types.Vulnerability{
+				{ID: "CVE-2", Severity: "Low", Package: "musl", Version: "1.2.4"},
+				{ID: "CVE-1", Severity: "High", Package: "zlib", Version: "1.3"},
+			},
+			VulnSummary: map[string]int{"Low": 1, "High": 1},
+			Runners:     []types.RunnerStatus{{Name: "grype", Status: types.RunnerOK}},
+		},
+		"trivy.json": {
+			Vulnerabilities: []types.Vulnerability{{ID: "CVE-1", Severity: "High", Package: "zlib", Version: "1.3"}},
+			VulnSummary:     map[string]int{"High": 1},
+		},
+		"bom.cdx.json": {
+			Suppressed: []types.SuppressedVulnerability{
+				{Vulnerability: types.Vulnerability{ID: "CVE-2", Severity: "Low", Package: "musl", Version: "1.2.4"}, Justification: "not reachable", Status: "not_affected"},
+			},
+		},
+	}
+	readReport = func(path string) (*types.ImageStats, string, error) {
+		stats, ok := reports[path]
+		if !ok {
+			return nil, "", errors.New("no such file")
+		}
+		return stats, "test", nil
+	}
+
+	stats, err := ImportReports("", []string{"sbom.json", "grype.json", "trivy.json"})
+	if err != nil {
+		t.Fatalf("ImportReports() error: %v", err)
+	}
+	if stats.ImageTag != "registry/app:1" {
+		t.Errorf("ImageTag = %q, want the image recorded in the reports", stats.ImageTag)
+	}
+	if stats.TotalPackages != 2 || stats.Packages[0].Name != "musl" {
+		t.Errorf("Packages = %+v, want musl and zlib once each", stats.Packages)
+	}
+	if len(stats.Vulnerabilities) != 2 || stats.Vulnerabilities[0].ID != "CVE-1" {
+		t.Errorf("Vulnerabilities = %+v, want CVE-1 then CVE-2", stats.Vulnerabilities)
+	}
+	if stats.VulnSummary["High"] != 1 || stats.VulnSummary["Low"] != 1 {
+		t.Errorf("VulnSummary = %v, want one High and one Low", stats.VulnSummary)
+	}
+	if len(stats.LicenseSummary) != 2 {
+		t.Errorf("LicenseSummary = %+v, want 2 licenses", stats.LicenseSummary)
+	}
+	if !stats.Has("syft") || !stats.Has("grype") || stats.Has("dive") {
+		t.Errorf("Runners = %+v, want syft and grype ok, dive skipped", stats.Runners)
+	}
+
+	// A finding triaged in one report is not active because another lists it.
+	stats, err = ImportReports("", []string{"grype.json", "bom.cdx.json"})
+	if err != nil {
+		t.Fatalf("ImportReports() error: %v", err)
+	}
+	if len(stats.Vulnerabilities) != 1 || stats.Vulnerabilities[0].ID != "CVE-1" || stats.VulnSummary["Low"] != 0 {
+		t.Errorf("Vulnerabilities = %+v (summary %v), want only CVE-1", stats.Vulnerabilities, stats.VulnSummary)
+	}
+	if len(stats.Suppressed) != 1 || stats.Suppressed[0].ID != "CVE-2" {
+		t.Errorf("Suppressed = %+v, want CVE-2", stats.Suppressed)
+	}
+
+	stats, err = ImportReports("app:1", []string{"sbom.json"})
+	if err != nil || stats.ImageTag != "app:1" {
+		t.Errorf("ImportReports(app:1) = (%q, %v), want the configured tag", stats.ImageTag, err)
+	}
+	// Without a scan report, vulnerabilities are n/a rather than zero.
+	if st := stats.RunnerStatus("grype"); st == nil || st.Status != types.RunnerSkipped || st.Message != "no report" {
+		t.Errorf("grype status = %+v, want skipped with no report", st)
+	}
+
+	if _, err := ImportReports("app:1", []string{"missing.json"}); err == nil {
+		t.Error("expected error for an unreadable report")
+	}
+}
+
+// blockingRunner records how many instances of itself run at once.
+type blockingRunner struct {
+	name    string
+	running *atomic.Int32
+	peak    *atomic.Int32
+	total   *atomic.Int32
+	peakAll *atomic.Int32
+}
+
+func (b *blockingRunner) Name() string      { return b.name }
+func (b *blockingRunner) IsAvailable() bool { return true }
+
+func (b *blockingRunner) Run(_ context.Context, image string, _ bool) (*types.ImageStats, error) {
+	storeMax(b.peak, b.running.Add(1))
+	storeMax(b.peakAll, b.total.Add(1))
+	time.Sleep(5 * time.Millisecond)
+	b.running.Add(-1)
+	b.total.Add(-1)
+	return &types.ImageStats{ImageTag: image}, nil
+}
+
+func storeMax(peak *atomic.Int32, v int32) {
+	for {
+		cur := peak.Load()
+		if v <= cur || peak.CompareAndSwap(cur, v) {
+			return
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	var total, peakAll, grypeRunning, grypePeak, syftRunning, syftPeak atomic.Int32
+	limiter := NewLimiter(3, map[string]int{"grype": 2})
+	newRunners := func() []Runner {
+		return []Runner{
+			limiter.Wrap(&blockingRunner{name: "grype", running: &grypeRunning, peak: &grypePeak, total: &total, peakAll: &peakAll}),
+			limiter.Wrap(&blockingRunner{name: "syft", running: &syftRunning, peak: &syftPeak, total: &total, peakAll: &peakAll}),
+		}
+	}
+
+	images := []string{"a:1", "b:1", "c:1", "d:1", "e:1", "f:1"}
+	results, err := AnalyzeComparison(context.Background(), images, newRunners, false)
+	if err != nil {
+		t.Fatalf("AnalyzeComparison() error = %v", err)
+	}
+	if len(results) != len(images) {
+		t.Fatalf("got %d results, want %d", len(results), len(images))
+	}
+	if got := peakAll.Load(); got > 3 {
+		t.Errorf("peak concurrent runners = %d, want at most 3", got)
+	}
+	if got := grypePeak.Load(); got > 2 {
+		t.Errorf("peak concurrent grype runners = %d, want at most 2", got)
+	}
+	if syftPeak.Load() == 0 {
+		t.Error("syft never ran")
+	}
+}
+
+func TestLimiter_ContextCanceled(t *testing.T) {
+	limiter := NewLimiter(1, nil)
+	release, err := limiter.acquire(context.Background(), "grype")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := limiter.Wrap(&MockRunner{name: "syft", available: true})
+	if _, err := r.Run(ctx, "app:1", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if r.Name() != "syft" || !r.IsAvailable() {
+		t.Error("wrapped runner should keep the name and availability of the runner")
+	}
+}
+
+// backendRunner mimics the runtime runner, which is named "runtime" until it
+// resolves its backend.
+type backendRunner struct {
+	MockRunner
+	resolved bool
+}
+
+func (b *backendRunner) Name() string {
+	if b.resolved {
+		return "docker"
+	}
+	return "runtime"
+}
+
+func (b *backendRunner) IsAvailable() bool {
+	b.resolved = true
+	return true
+}
+
+func TestLimiter_RuntimeName(t *testing.T) {
+	limiter := NewLimiter(2, map[string]int{"runtime": 1})
+	release, err := limiter.acquire(context.Background(), "runtime")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	r := limiter.Wrap(&backendRunner{})
+	if !r.IsAvailable() || r.Name() != "docker" {
+		t.Fatalf("Name() = %q, want the resolved backend", r.Name())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Run(ctx, "app:1", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want the runtime limit to apply after the backend is resolved", err)
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var limiter *Limiter
+	r := &MockRunner{name: "syft"}
+	if got := limiter.Wrap(r); got != Runner(r) {
+		t.Error("nil Limiter should return the runner unchanged")
+	}
+}
+
+// countingRunner counts its runs per image.
+type countingRunner struct {
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (c *countingRunner) Name() string      { return "counting" }
+func (c *countingRunner) IsAvailable() bool { return true }
+
+func (c *countingRunner) Run(_ context.Context, image string, _ bool) (*types.ImageStats, error) {
+	c.mu.Lock()
+	c.runs[image]++
+	c.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	return &types.ImageStats{
+		ImageTag:        image,
+		RepoDigest:      "docker.io/library/app@sha256:abc",
+		Vulnerabilities: []types.Vulnerability{{ID: "CVE-1", Severity: "High"}},
+	}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	counter := &countingRunner{runs: make(map[string]int)}
+	newRunners := func() []Runner { return []Runner{counter} }
+	reg := NewRegistry()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := reg.AnalyzeImage(ctx, "app:1", newRunners, false); err != nil {
+				t.Errorf("AnalyzeImage() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := reg.AnalyzeComparison(ctx, []string{"app:1", "app:2"}, newRunners, false); err != nil {
+				t.Errorf("AnalyzeComparison() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter.runs["app:1"] != 1 || counter.runs["app:2"] != 1 {
+		t.Errorf("runs = %v, want each image analyzed once", counter.runs)
+	}
+
+	// Callers get independent copies.
+	first, _ := reg.AnalyzeImage(ctx, "app:1", newRunners, false)
+	first.Vulnerabilities = nil
+	second, _ := reg.AnalyzeImage(ctx, "app:1", newRunners, false)
+	if len(second.Vulnerabilities) != 1 {
+		t.Errorf("second copy has %d vulnerabilities, want 1", len(second.Vulnerabilities))
+	}
+
+	// A digest reference reuses the analysis of the tag.
+	byDigest, err := reg.AnalyzeImage(ctx, "app@sha256:abc", newRunners, false)
+	if err != nil {
+		t.Fatalf("AnalyzeImage() by digest error = %v", err)
+	}
+	if counter.runs["app@sha256:abc"] != 0 {
+		t.Error("expected the digest reference to reuse the tag's analysis")
+	}
+	if byDigest.ImageTag != "app@sha256:abc" {
+		t.Errorf("ImageTag = %q, want the requested reference", byDigest.ImageTag)
+	}
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	oldEnsureImage := ensureImage
+	defer func() { ensureImage = oldEnsureImage }()
+	ensureImage = func(_ context.Context, image string, verbose bool) error { return nil }
+
+	counter := &countingRunner{runs: make(map[string]int)}
+	var reg *Registry
+	for range 2 {
+		if _, err := reg.AnalyzeImage(context.Background(), "app:1", func() []Runner { return []Runner{counter} }, false); err != nil {
+			t.Fatalf("AnalyzeImage() error = %v", err)
+		}
+	}
+	if counter.runs["app:1"] != 2 {
+		t.Errorf("runs = %d, want 2 without a registry", counter.runs["app:1"])
+	}
+}
+
+// flakyRunner fails with errs in order, then succeeds.
+type flakyRunner struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyRunner) Name() string      { return "flaky" }
+func (f *flakyRunner) IsAvailable() bool { return true }
+
+func (f *flakyRunner) Run(_ context.Context, image string, _ bool) (*types.ImageStats, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return &types.ImageStats{ImageTag: image}, nil
+}
+
+func TestRetryPolicy(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+	var backoffs []time.Duration
+	sleep = func(_ context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return nil
+	}
+
+	rateLimited := errors.New("429 Too Many Requests")
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		errs      []error
+		wantErr   bool
+		wantCalls int
+		wantWaits []time.Duration
+	}{
+		{"recovers", RetryPolicy{Retries: 3, Backoff: time.Second}, []error{rateLimited, rateLimited}, false, 3, []time.Duration{time.Second, 2 * time.Second}},
+		{"default backoff", RetryPolicy{Retries: 1}, []error{rateLimited}, false, 2, []time.Duration{DefaultRetryBackoff}},
+		{"out of retries", RetryPolicy{Retries: 1, Backoff: time.Second}, []error{rateLimited, rateLimited}, true, 2, []time.Duration{time.Second}},
+		{"permanent failure", RetryPolicy{Retries: 3}, []error{errors.New("manifest unknown")}, true, 1, nil},
+		{"no retries", RetryPolicy{}, []error{rateLimited}, true, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoffs = nil
+			f := &flakyRunner{errs: tt.errs}
+			_, err := tt.policy.Wrap(f).Run(context.Background(), "app:1", false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", f.calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(backoffs, tt.wantWaits) {
+				t.Errorf("backoffs = %v, want %v", backoffs, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	timeout := &runner.Error{Kind: runner.ErrTimeout, Tool: "grype", Err: errors.New("signal: killed")}
+	err := error(&Error{Image: "app:1", Failures: []*RunnerError{
+		{Runner: "syft", Err: errors.New("boom")},
+		{Runner: "grype", Err: timeout},
+	}})
+
+	if want := "analysis failed for 2 runners: syft failed: boom; grype failed: grype: timed out: signal: killed"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, runner.ErrTimeout) || !runner.IsTransient(err) {
+		t.Errorf("errors.Is(%v, ErrTimeout) = false, want true", err)
+	}
+	var re *runner.Error
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &re) || re.Tool != "grype" {
+		t.Errorf("errors.As() did not find the runner error in %v", err)
+	}
+}
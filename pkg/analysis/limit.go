@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// DefaultMaxConcurrency is the number of runners allowed to execute at once
+// when no limit is configured.
+var DefaultMaxConcurrency = runtime.NumCPU()
+
+// Limiter is a worker pool shared by every analysis of a run. It bounds how
+// many runners execute at once, overall and per runner name (e.g., at most
+// two grype scans), so comparing many images does not start every scanner
+// process at the same time. A Limiter is safe for concurrent use.
+type Limiter struct {
+	total     chan struct{}
+	perRunner map[string]chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing max runners to execute at once
+// (DefaultMaxConcurrency when max is not positive) and at most
+// perRunner[name] instances of the runner with that name. Runners without a
+// per-runner limit are only bound by max.
+func NewLimiter(max int, perRunner map[string]int) *Limiter {
+	if max <= 0 {
+		max = DefaultMaxConcurrency
+	}
+	l := &Limiter{
+		total:     make(chan struct{}, max),
+		perRunner: make(map[string]chan struct{}, len(perRunner)),
+	}
+	for name, n := range perRunner {
+		if n > 0 {
+			l.perRunner[name] = make(chan struct{}, n)
+		}
+	}
+	return l
+}
+
+// Wrap returns r with its Run calls scheduled through the limiter. A nil
+// Limiter returns r unchanged. The per-runner limit is looked up by the name
+// r has when wrapped, so the runtime runner is limited as "runtime" (its
+// name in the runners settings) whichever backend it later resolves to.
+func (l *Limiter) Wrap(r Runner) Runner {
+	if l == nil {
+		return r
+	}
+	return &limitedRunner{Runner: r, limiter: l, name: r.Name()}
+}
+
+// acquire waits for a slot for the named runner. The per-runner slot is
+// taken first so runners queued behind their own limit do not hold a slot
+// other runners could use. The returned function releases both slots.
+func (l *Limiter) acquire(ctx context.Context, name string) (func(), error) {
+	sem := l.perRunner[name]
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	select {
+	case l.total <- struct{}{}:
+	case <-ctx.Done():
+		if sem != nil {
+			<-sem
+		}
+		return nil, ctx.Err()
+	}
+	return func() {
+		<-l.total
+		if sem != nil {
+			<-sem
+		}
+	}, nil
+}
+
+// limitedRunner runs the wrapped runner once the limiter has a free slot.
+type limitedRunner struct {
+	Runner
+	limiter *Limiter
+	name    string // name of the per-runner limit
+}
+
+// Run waits for a slot, or for ctx to be done, then runs the wrapped runner.
+func (r *limitedRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	release, err := r.limiter.acquire(ctx, r.name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return r.Runner.Run(ctx, image, verbose)
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Registry memoises image analyses for the duration of a run, so an image
+// referenced by several sections is analyzed once. Concurrent requests for
+// an image wait for the analysis already in flight. Every caller receives
+// its own copy of the result, so sections can annotate it (suppressions,
+// drift, per-platform results) independently.
+//
+// A nil Registry analyzes every request directly.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+}
+
+// registryEntry is an analysis result, valid once done is closed.
+type registryEntry struct {
+	done  chan struct{}
+	stats *types.ImageStats
+	err   error
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+// AnalyzeImage is the memoised form of AnalyzeImage. Runners are only
+// created when the image has not been analyzed yet. Failed analyses are
+// memoised too, along with their partial results.
+func (r *Registry) AnalyzeImage(ctx context.Context, image string, newRunners func() []Runner, verbose bool) (*types.ImageStats, error) {
+	return r.analyzer(directAnalyzer(newRunners, verbose))(ctx, image)
+}
+
+// AnalyzeComparison is AnalyzeComparison with every image analyzed through
+// the registry.
+func (r *Registry) AnalyzeComparison(ctx context.Context, images []string, newRunners func() []Runner, verbose bool) ([]*types.ImageStats, error) {
+	return analyzeComparison(ctx, images, r.analyzer(directAnalyzer(newRunners, verbose)))
+}
+
+// AnalyzePlatforms is AnalyzePlatforms with every platform analyzed through
+// the registry.
+func (r *Registry) AnalyzePlatforms(ctx context.Context, stats *types.ImageStats, newRunners func() []Runner, verbose bool) error {
+	return analyzePlatforms(ctx, stats, r.analyzer(directAnalyzer(newRunners, verbose)), verbose)
+}
+
+// analyzer wraps analyze with the registry.
+func (r *Registry) analyzer(analyze analyzeFunc) analyzeFunc {
+	if r == nil {
+		return analyze
+	}
+	return func(ctx context.Context, image string) (*types.ImageStats, error) {
+		e, owner := r.entry(image)
+		if owner {
+			e.stats, e.err = analyze(ctx, image)
+			r.register(e)
+			close(e.done)
+		} else {
+			slog.Debug("waiting for analysis of the same image", "image", image)
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		stats, err := clone(e.stats, e.err)
+		if stats != nil {
+			// The analysis may have been requested under another reference.
+			stats.ImageTag = image
+		}
+		return stats, err
+	}
+}
+
+// entry returns the entry for image, creating it when the image has not
+// been requested yet. owner reports whether the caller created it and must
+// run the analysis.
+func (r *Registry) entry(image string) (e *registryEntry, owner bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[image]; ok {
+		return e, false
+	}
+	e = &registryEntry{done: make(chan struct{})}
+	r.entries[image] = e
+	return e, true
+}
+
+// register makes a finished analysis available under the image's digest
+// reference too (e.g., "nginx@sha256:..."), so a section referencing the
+// image by digest reuses the analysis of a section referencing it by tag.
+func (r *Registry) register(e *registryEntry) {
+	if e.stats == nil || e.stats.RepoDigest == "" {
+		return
+	}
+	digest := e.stats.RepoDigest
+	if i := strings.LastIndex(digest, "@"); i >= 0 {
+		digest = digest[i:]
+	}
+	ref := e.stats.ImageTag
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	} else if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[ref+digest]; !ok {
+		r.entries[ref+digest] = e
+	}
+}
+
+// clone returns a deep copy of stats (which may be nil) along with err.
+func clone(stats *types.ImageStats, err error) (*types.ImageStats, error) {
+	if stats == nil {
+		return nil, err
+	}
+	c, cerr := stats.Clone()
+	if cerr != nil {
+		return nil, cerr
+	}
+	return c, err
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// DefaultRetryBackoff is the delay before the first retry when a
+// RetryPolicy does not set one.
+const DefaultRetryBackoff = 2 * time.Second
+
+// isTransient is swappable so tests can control which errors are retried.
+var isTransient = runner.IsTransient
+
+// sleep waits for d or until ctx is done. It is swappable so tests do not
+// wait for backoffs.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryPolicy retries runners that fail with transient errors (see
+// runner.IsTransient), such as registry rate limits.
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt.
+	Retries int
+	// Backoff is the delay before the first retry (default:
+	// DefaultRetryBackoff). It doubles with every further retry.
+	Backoff time.Duration
+}
+
+// Wrap returns r retried according to the policy, or r itself when the
+// policy allows no retries.
+func (p RetryPolicy) Wrap(r Runner) Runner {
+	if p.Retries <= 0 {
+		return r
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRetryBackoff
+	}
+	return &retryingRunner{Runner: r, policy: p}
+}
+
+// retryingRunner re-runs the wrapped runner after transient failures.
+type retryingRunner struct {
+	Runner
+	policy RetryPolicy
+}
+
+// Run runs the wrapped runner until it succeeds, fails permanently, runs
+// out of retries or ctx is done. The last error is returned.
+func (r *retryingRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	backoff := r.policy.Backoff
+	for attempt := 0; ; attempt++ {
+		stats, err := r.Runner.Run(ctx, image, verbose)
+		if err == nil || attempt == r.policy.Retries || ctx.Err() != nil || !isTransient(err) {
+			return stats, err
+		}
+		slog.Warn("runner failed, retrying", "runner", r.Name(), "image", image, "attempt", attempt+1, "backoff", backoff, "error", err)
+		if serr := sleep(ctx, backoff); serr != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,144 @@
+// Package baseimage compares an image with the base image it is built from,
+// so its size, packages and vulnerabilities can be attributed to the base
+// image or to the image's own layers, and stale base images can be reported.
+package baseimage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// DigestLabel is the OCI annotation in which build tools record the digest
+// of the base image an image was built from.
+const DigestLabel = "org.opencontainers.image.base.digest"
+
+// MaxAge is the age after which a base image is considered stale, even when
+// the image was built from its current digest. It is a variable so callers
+// and tests can change it.
+var MaxAge = 90 * 24 * time.Hour
+
+// Compare splits the content of target into what it inherits from base (the
+// analysis of the base image ref) and what its own layers introduce.
+//
+// Packages are attributed to the base image by name and type, so packages
+// the base image has since upgraded still count as inherited; a version
+// mismatch marks the base image as stale instead. Vulnerabilities are
+// attributed through their package. now is the time the age of the base
+// image is measured against.
+func Compare(target, base *types.ImageStats, ref string, now time.Time) *types.BaseImageReport {
+	if target == nil || base == nil {
+		return nil
+	}
+	report := &types.BaseImageReport{
+		Image:      ref,
+		RepoDigest: base.RepoDigest,
+		Created:    base.Created,
+	}
+
+	baseVersions := make(map[string]string, len(base.Packages))
+	baseNames := make(map[string]bool, len(base.Packages))
+	for _, p := range base.Packages {
+		baseVersions[packageKey(p.Name, p.Type)] = p.Version
+		baseNames[p.Name] = true
+	}
+	// Scanners do not always report the type of a vulnerable package.
+	inherited := func(name, typ string) bool {
+		if typ == "" {
+			return baseNames[name]
+		}
+		_, ok := baseVersions[packageKey(name, typ)]
+		return ok
+	}
+
+	outdated := 0
+	for _, p := range target.Packages {
+		version, ok := baseVersions[packageKey(p.Name, p.Type)]
+		if !ok {
+			report.Introduced.Packages++
+			report.IntroducedPackages = append(report.IntroducedPackages, p)
+			continue
+		}
+		report.Inherited.Packages++
+		if version != p.Version {
+			outdated++
+		}
+	}
+
+	fixable := 0
+	for _, v := range target.Vulnerabilities {
+		part := &report.Introduced
+		if inherited(v.Package, v.PackageType) {
+			part = &report.Inherited
+			if v.Fixable() {
+				fixable++
+			}
+		}
+		if part.VulnSummary == nil {
+			part.VulnSummary = make(map[string]int)
+		}
+		part.VulnSummary[v.Severity]++
+	}
+
+	// Layers are shared, so the base image's size and layers are a prefix of
+	// the target's. A rebuilt base image may be larger than the one the
+	// target was built from; clamp rather than report negative content.
+	report.Inherited.SizeBytes = min(base.SizeBytes, target.SizeBytes)
+	report.Introduced.SizeBytes = target.SizeBytes - report.Inherited.SizeBytes
+	report.Inherited.Layers = min(base.TotalLayers, target.TotalLayers)
+	report.Introduced.Layers = target.TotalLayers - report.Inherited.Layers
+
+	if built := builtFrom(target); built != "" && digestOf(base.RepoDigest) != "" && built != digestOf(base.RepoDigest) {
+		report.Stale = true
+		report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+			"Rebuild on the current %s: the image was built from %s, the base image is now %s",
+			ref, shortDigest(built), shortDigest(digestOf(base.RepoDigest))))
+	} else if outdated > 0 {
+		report.Stale = true
+		report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+			"Rebuild on the current %s: %d inherited packages differ from the base image's",
+			ref, outdated))
+	}
+	if fixable > 0 {
+		report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+			"%d inherited vulnerabilities have fixes: update %s or upgrade the affected packages",
+			fixable, ref))
+	}
+	if !base.Created.IsZero() && now.Sub(base.Created) > MaxAge {
+		report.Stale = true
+		report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+			"%s was built %d days ago: check for a newer tag",
+			ref, int(now.Sub(base.Created).Hours()/24)))
+	}
+	return report
+}
+
+// packageKey identifies a package independently of its version.
+func packageKey(name, typ string) string {
+	return typ + "/" + name
+}
+
+// builtFrom returns the base image digest recorded in the image's labels.
+func builtFrom(stats *types.ImageStats) string {
+	if stats.Config == nil {
+		return ""
+	}
+	return stats.Config.Labels[DigestLabel]
+}
+
+// digestOf returns the digest of a pinned reference ("nginx@sha256:...").
+func digestOf(ref string) string {
+	_, digest, _ := strings.Cut(ref, "@")
+	return digest
+}
+
+// shortDigest abbreviates a digest for messages ("sha256:0123456789ab").
+func shortDigest(digest string) string {
+	algo, hex, ok := strings.Cut(digest, ":")
+	if ok && len(hex) > 12 {
+		return algo + ":" + hex[:12]
+	}
+	return digest
+}
@@ -0,0 +1,137 @@
+package baseimage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestCompare(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	base := &types.ImageStats{
+		RepoDigest:  "alpine@sha256:1111111111111111111111111111",
+		Created:     now.AddDate(0, 0, -10),
+		SizeBytes:   8 << 20,
+		TotalLayers: 1,
+		Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4-r2", Type: "apk"},
+			{Name: "openssl", Version: "3.1.4-r0", Type: "apk"},
+		},
+	}
+	target := &types.ImageStats{
+		SizeBytes:   20 << 20,
+		TotalLayers: 4,
+		Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4-r2", Type: "apk"},
+			{Name: "openssl", Version: "3.1.4-r0", Type: "apk"},
+			{Name: "curl", Version: "8.5.0-r0", Type: "apk"},
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+		},
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-1", Severity: "High", Package: "openssl", PackageType: "apk", FixState: types.FixStateFixed, FixedInVersions: []string{"3.1.5-r0"}},
+			{ID: "CVE-2", Severity: "Low", Package: "musl"},
+			{ID: "CVE-3", Severity: "Critical", Package: "curl", PackageType: "apk"},
+			{ID: "CVE-4", Severity: "High", Package: "express", PackageType: "npm"},
+		},
+	}
+
+	got := Compare(target, base, "alpine:3.19", now)
+	want := &types.BaseImageReport{
+		Image:      "alpine:3.19",
+		RepoDigest: base.RepoDigest,
+		Created:    base.Created,
+		Inherited: types.ContentSummary{
+			SizeBytes: 8 << 20, Layers: 1, Packages: 2,
+			VulnSummary: map[string]int{"High": 1, "Low": 1},
+		},
+		Introduced: types.ContentSummary{
+			SizeBytes: 12 << 20, Layers: 3, Packages: 2,
+			VulnSummary: map[string]int{"Critical": 1, "High": 1},
+		},
+		IntroducedPackages: target.Packages[2:],
+		Recommendations: []string{
+			"1 inherited vulnerabilities have fixes: update alpine:3.19 or upgrade the affected packages",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := Compare(nil, base, "alpine:3.19", now); got != nil {
+		t.Errorf("Compare() without target = %+v, want nil", got)
+	}
+}
+
+func TestCompare_Stale(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		target *types.ImageStats
+		base   *types.ImageStats
+		want   string // substring of the first recommendation; "" when fresh
+	}{
+		{
+			name: "built from current digest",
+			target: &types.ImageStats{Config: &types.ImageConfig{
+				Labels: map[string]string{DigestLabel: "sha256:aaaaaaaaaaaaaaaaaaaa"},
+			}},
+			base: &types.ImageStats{RepoDigest: "debian@sha256:aaaaaaaaaaaaaaaaaaaa", Created: now.AddDate(0, 0, -5)},
+		},
+		{
+			name: "built from old digest",
+			target: &types.ImageStats{Config: &types.ImageConfig{
+				Labels: map[string]string{DigestLabel: "sha256:aaaaaaaaaaaaaaaaaaaa"},
+			}},
+			base: &types.ImageStats{RepoDigest: "debian@sha256:bbbbbbbbbbbbbbbbbbbb"},
+			want: "built from sha256:aaaaaaaaaaaa, the base image is now sha256:bbbbbbbbbbbb",
+		},
+		{
+			name: "outdated packages",
+			target: &types.ImageStats{Packages: []types.PackageSummary{
+				{Name: "libc6", Version: "2.36-9", Type: "deb"},
+			}},
+			base: &types.ImageStats{Packages: []types.PackageSummary{
+				{Name: "libc6", Version: "2.36-9+deb12u4", Type: "deb"},
+			}},
+			want: "1 inherited packages differ",
+		},
+		{
+			name:   "old base image",
+			target: &types.ImageStats{},
+			base:   &types.ImageStats{Created: now.Add(-MaxAge - 24*time.Hour)},
+			want:   "was built 91 days ago",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.target, tt.base, "debian:12", now)
+			if got.Stale != (tt.want != "") {
+				t.Errorf("Stale = %v, want %v", got.Stale, tt.want != "")
+			}
+			if tt.want == "" {
+				if len(got.Recommendations) > 0 {
+					t.Errorf("Recommendations = %q, want none", got.Recommendations)
+				}
+				return
+			}
+			if len(got.Recommendations) == 0 || !strings.Contains(got.Recommendations[0], tt.want) {
+				t.Errorf("Recommendations = %q, want first to contain %q", got.Recommendations, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare_LargerBase(t *testing.T) {
+	target := &types.ImageStats{SizeBytes: 100, TotalLayers: 2}
+	base := &types.ImageStats{SizeBytes: 150, TotalLayers: 3}
+	got := Compare(target, base, "x", time.Now())
+	if got.Introduced.SizeBytes != 0 || got.Inherited.SizeBytes != 100 {
+		t.Errorf("sizes = %d inherited, %d introduced; want 100, 0", got.Inherited.SizeBytes, got.Introduced.SizeBytes)
+	}
+	if got.Introduced.Layers != 0 || got.Inherited.Layers != 2 {
+		t.Errorf("layers = %d inherited, %d introduced; want 2, 0", got.Inherited.Layers, got.Introduced.Layers)
+	}
+}
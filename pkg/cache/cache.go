@@ -0,0 +1,288 @@
+// Package cache stores per-runner analysis results on disk, keyed by the
+// image ID and the version of the tool that produced them, so repeated runs
+// against unchanged images skip the expensive syft, grype and dive scans.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/runner"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// DefaultTTL is how long cached results are used when no TTL is configured.
+// It bounds how stale vulnerability data can get for tools that do not
+// report a database version.
+const DefaultTTL = 24 * time.Hour
+
+// entryExt is the file extension of cache entries.
+const entryExt = ".json"
+
+// imageID resolves the content-addressed ID of a local image. It is
+// swappable so tests do not need a container runtime.
+var imageID = func(ctx context.Context, image string) (string, error) {
+	return runner.ImageID(ctx, image, false)
+}
+
+// Runner mirrors analysis.Runner so the cache can wrap runners without
+// depending on the analysis package.
+type Runner interface {
+	Name() string
+	IsAvailable() bool
+	Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error)
+}
+
+// Keyer is implemented by runners whose results can be cached. CacheKey
+// identifies the tool build (and any data it depends on, such as a
+// vulnerability database) so upgrades invalidate old entries.
+type Keyer interface {
+	CacheKey(ctx context.Context) (string, error)
+}
+
+// ArgsKeyer is implemented by cacheable runners whose output also depends on
+// their configured arguments. ArgsKey is appended to the memoized tool key
+// on every lookup, so runners of the same tool configured with different
+// arguments never share entries.
+type ArgsKeyer interface {
+	ArgsKey() string
+}
+
+// entry is the on-disk representation of a cached result.
+type entry struct {
+	CreatedAt time.Time         `json:"createdAt"`
+	Runner    string            `json:"runner"`
+	Image     string            `json:"image"`
+	Stats     *types.ImageStats `json:"stats"`
+}
+
+// Store is a directory of cached runner results.
+type Store struct {
+	// Dir is the cache directory; it is created on first write.
+	Dir string
+	// TTL is the maximum age of a usable entry. Zero means DefaultTTL.
+	TTL time.Duration
+
+	// imageIDs and toolKeys memoize lookups for the lifetime of the store,
+	// so each image is inspected and each tool is queried only once per run.
+	// Tool keys identify the tool build only; per-runner arguments are
+	// appended in key.
+	imageIDs sync.Map // image -> string
+	toolKeys sync.Map // runner name -> string
+}
+
+// New returns a store rooted at dir. An empty dir selects DefaultDir.
+func New(dir string, ttl time.Duration) (*Store, error) {
+	if dir == "" {
+		d, err := DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+	return &Store{Dir: dir, TTL: ttl}, nil
+}
+
+// DefaultDir returns the per-user cache directory for dock-docs.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(base, "dock-docs"), nil
+}
+
+// Key derives a cache key from its parts.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *Store) ttl() time.Duration {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return DefaultTTL
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.Dir, key+entryExt)
+}
+
+// Get returns the cached stats for key. Missing, unreadable and expired
+// entries are reported as misses.
+func (s *Store) Get(key string) (*types.ImageStats, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Stats == nil {
+		slog.Debug("ignoring corrupt cache entry", "key", key, "error", err)
+		return nil, false
+	}
+	if time.Since(e.CreatedAt) > s.ttl() {
+		return nil, false
+	}
+	return e.Stats, true
+}
+
+// Put stores stats under key. The entry is written to a temporary file and
+// renamed into place so concurrent readers never see a partial entry.
+func (s *Store) Put(key, runnerName, image string, stats *types.ImageStats) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Runner: runnerName, Image: image, Stats: stats})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Prune removes expired and corrupt entries, or every entry when all is
+// true, and returns the number of files removed. A missing cache directory
+// is not an error.
+func (s *Store) Prune(all bool) (int, error) {
+	files, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || (!strings.HasSuffix(name, entryExt) && !strings.HasSuffix(name, ".tmp")) {
+			continue
+		}
+		p := filepath.Join(s.Dir, name)
+		if !all && strings.HasSuffix(name, entryExt) && !s.expired(p) {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// expired reports whether the entry at p is past the TTL or unreadable.
+func (s *Store) expired(p string) bool {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return true
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return true
+	}
+	return time.Since(e.CreatedAt) > s.ttl()
+}
+
+// Wrap returns r with caching enabled if it implements Keyer; other runners
+// (plugins, the runtime inspector) are returned unchanged.
+func (s *Store) Wrap(r Runner) Runner {
+	if _, ok := r.(Keyer); !ok {
+		return r
+	}
+	return &cachedRunner{Runner: r, store: s}
+}
+
+// key returns the cache key for running r against image. The image is
+// identified by its ID rather than its tag, so a re-pushed tag misses.
+func (s *Store) key(ctx context.Context, r Runner, image string) (string, error) {
+	id, err := memo(&s.imageIDs, image, func() (string, error) { return imageID(ctx, image) })
+	if err != nil {
+		return "", err
+	}
+	tool, err := memo(&s.toolKeys, r.Name(), func() (string, error) { return r.(Keyer).CacheKey(ctx) })
+	if err != nil {
+		return "", err
+	}
+	if a, ok := r.(ArgsKeyer); ok {
+		tool += a.ArgsKey()
+	}
+	return Key(r.Name(), id, tool), nil
+}
+
+// memo returns the cached value for k, computing and storing it on a miss.
+// Errors are not cached.
+func memo(m *sync.Map, k string, compute func() (string, error)) (string, error) {
+	if v, ok := m.Load(k); ok {
+		return v.(string), nil
+	}
+	v, err := compute()
+	if err != nil {
+		return "", err
+	}
+	m.Store(k, v)
+	return v, nil
+}
+
+// cachedRunner serves results from the store and records fresh ones.
+type cachedRunner struct {
+	Runner
+	store *Store
+}
+
+// Run returns the cached result when present. If the cache key cannot be
+// determined (e.g., the image is not local), the runner is invoked directly.
+func (c *cachedRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	key, err := c.store.key(ctx, c.Runner, image)
+	if err != nil {
+		slog.Debug("analysis cache disabled for runner", "runner", c.Name(), "image", image, "error", err)
+		return c.Runner.Run(ctx, image, verbose)
+	}
+
+	if stats, ok := c.store.Get(key); ok {
+		slog.Debug("analysis cache hit", "runner", c.Name(), "image", image)
+		events.Emit(ctx, events.Event{Kind: events.CacheHit, Image: image, Runner: c.Name()})
+		return stats, nil
+	}
+
+	stats, err := c.Runner.Run(ctx, image, verbose)
+	if err != nil {
+		return stats, err
+	}
+	if err := c.store.Put(key, c.Name(), image, stats); err != nil {
+		slog.Warn("failed to cache analysis result", "runner", c.Name(), "image", image, "error", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// fakeRunner counts invocations and returns fixed stats.
+type fakeRunner struct {
+	name    string
+	version string
+	calls   int
+	err     error
+}
+
+func (f *fakeRunner) Name() string      { return f.name }
+func (f *fakeRunner) IsAvailable() bool { return true }
+func (f *fakeRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &types.ImageStats{TotalPackages: 42}, nil
+}
+
+// keyedRunner is a fakeRunner that supports caching.
+type keyedRunner struct{ fakeRunner }
+
+func (k *keyedRunner) CacheKey(ctx context.Context) (string, error) { return k.version, nil }
+
+// argsRunner is a keyedRunner configured with extra arguments.
+type argsRunner struct {
+	keyedRunner
+	args string
+}
+
+func (a *argsRunner) ArgsKey() string { return a.args }
+
+func stubImageID(t *testing.T, ids map[string]string) {
+	t.Helper()
+	saved := imageID
+	t.Cleanup(func() { imageID = saved })
+	imageID = func(ctx context.Context, image string) (string, error) {
+		if id, ok := ids[image]; ok {
+			return id, nil
+		}
+		return "", errors.New("no such image")
+	}
+}
+
+func TestStore_GetPut(t *testing.T) {
+	s := &Store{Dir: t.TempDir(), TTL: time.Hour}
+	if _, ok := s.Get("missing"); ok {
+		t.Fatal("expected miss for missing key")
+	}
+
+	if err := s.Put("k", "syft", "app:1", &types.ImageStats{TotalPackages: 3}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := s.Get("k")
+	if !ok || got.TotalPackages != 3 {
+		t.Fatalf("Get() = (%+v, %v), want TotalPackages 3", got, ok)
+	}
+
+	// Entries older than the TTL are misses.
+	writeEntry(t, s, "old", time.Now().Add(-2*time.Hour))
+	if _, ok := s.Get("old"); ok {
+		t.Error("expected expired entry to miss")
+	}
+
+	if err := os.WriteFile(s.path("corrupt"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("corrupt"); ok {
+		t.Error("expected corrupt entry to miss")
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	s := &Store{Dir: t.TempDir(), TTL: time.Hour}
+	writeEntry(t, s, "fresh", time.Now())
+	writeEntry(t, s, "old", time.Now().Add(-2*time.Hour))
+	if err := os.WriteFile(filepath.Join(s.Dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := s.Prune(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune(false) = (%d, %v), want (1, nil)", removed, err)
+	}
+	if _, ok := s.Get("fresh"); !ok {
+		t.Error("fresh entry should survive pruning")
+	}
+
+	removed, err = s.Prune(true)
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune(true) = (%d, %v), want (1, nil)", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, "notes.txt")); err != nil {
+		t.Error("Prune should only remove cache entries")
+	}
+
+	missing := &Store{Dir: filepath.Join(t.TempDir(), "nope")}
+	if removed, err := missing.Prune(true); err != nil || removed != 0 {
+		t.Errorf("Prune(missing dir) = (%d, %v), want (0, nil)", removed, err)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	stubImageID(t, map[string]string{"app:1": "sha256:aaa", "app:latest": "sha256:aaa"})
+	s := &Store{Dir: t.TempDir()}
+
+	plain := &fakeRunner{name: "plugin"}
+	if s.Wrap(plain) != Runner(plain) {
+		t.Error("runners without CacheKey should not be wrapped")
+	}
+
+	r := &keyedRunner{fakeRunner{name: "syft", version: "1.0"}}
+	wrapped := s.Wrap(r)
+	var hits []events.Event
+	ctx := events.WithHandler(context.Background(), func(e events.Event) { hits = append(hits, e) })
+	for _, image := range []string{"app:1", "app:latest"} {
+		stats, err := wrapped.Run(ctx, image, false)
+		if err != nil || stats.TotalPackages != 42 {
+			t.Fatalf("Run(%s) = (%+v, %v)", image, stats, err)
+		}
+	}
+	if r.calls != 1 {
+		t.Errorf("runner called %d times, want 1 (same image ID)", r.calls)
+	}
+	if len(hits) != 1 || hits[0].Kind != events.CacheHit || hits[0].Image != "app:latest" || hits[0].Runner != "syft" {
+		t.Errorf("events = %+v, want one cache hit for app:latest", hits)
+	}
+
+	// A new tool version misses.
+	upgraded := &keyedRunner{fakeRunner{name: "syft", version: "2.0"}}
+	if _, err := (&Store{Dir: s.Dir}).Wrap(upgraded).Run(context.Background(), "app:1", false); err != nil {
+		t.Fatal(err)
+	}
+	if upgraded.calls != 1 {
+		t.Errorf("upgraded runner called %d times, want 1", upgraded.calls)
+	}
+
+	// Unresolvable images run uncached; failures are not stored.
+	unknown := &keyedRunner{fakeRunner{name: "grype", version: "1.0"}}
+	w := s.Wrap(unknown)
+	w.Run(context.Background(), "remote:1", false)
+	w.Run(context.Background(), "remote:1", false)
+	if unknown.calls != 2 {
+		t.Errorf("uncacheable runner called %d times, want 2", unknown.calls)
+	}
+
+	failing := &keyedRunner{fakeRunner{name: "dive", version: "1.0", err: errors.New("boom")}}
+	w = s.Wrap(failing)
+	w.Run(context.Background(), "app:1", false)
+	w.Run(context.Background(), "app:1", false)
+	if failing.calls != 2 {
+		t.Errorf("failing runner called %d times, want 2", failing.calls)
+	}
+}
+
+// TestWrap_Args tests that runners of the same tool configured with
+// different arguments (as in two config sections) sharing one store do not
+// share cache entries.
+func TestWrap_Args(t *testing.T) {
+	stubImageID(t, map[string]string{"app:1": "sha256:aaa"})
+	s := &Store{Dir: t.TempDir()}
+
+	first := &argsRunner{keyedRunner{fakeRunner{name: "grype", version: "1.0"}}, ""}
+	second := &argsRunner{keyedRunner{fakeRunner{name: "grype", version: "1.0"}}, "\nargs: --only-fixed"}
+	again := &argsRunner{keyedRunner{fakeRunner{name: "grype", version: "1.0"}}, "\nargs: --only-fixed"}
+	for _, r := range []*argsRunner{first, second, again} {
+		if _, err := s.Wrap(r).Run(context.Background(), "app:1", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("runner calls = (%d, %d), want (1, 1) for different args", first.calls, second.calls)
+	}
+	if again.calls != 0 {
+		t.Errorf("runner with the same args called %d times, want 0 (cache hit)", again.calls)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("Key should separate parts")
+	}
+	if Key("x") != Key("x") {
+		t.Error("Key should be deterministic")
+	}
+}
+
+func writeEntry(t *testing.T, s *Store, key string, created time.Time) {
+	t.Helper()
+	data, err := json.Marshal(entry{CreatedAt: created, Stats: &types.ImageStats{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.path(key), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
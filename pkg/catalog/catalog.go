@@ -0,0 +1,164 @@
+// Package catalog classifies installed packages into runtime, framework,
+// library and OS tiers using a curated catalogue of well-known runtimes and
+// frameworks, so templates can show a concise "tech stack" summary instead of
+// every package in the image.
+package catalog
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+//go:embed catalog.yaml
+var builtinCatalog []byte
+
+// osPackageTypes are syft package types managed by a distribution's package
+// manager. Packages of these types that are not in the catalogue are
+// classified as OS packages; everything else is a library.
+var osPackageTypes = map[string]bool{
+	"apk":          true,
+	"deb":          true,
+	"rpm":          true,
+	"alpm":         true,
+	"portage":      true,
+	"linux-kernel": true,
+}
+
+// Entry maps package names to a runtime or framework.
+type Entry struct {
+	// Name is the display name (e.g., "Spring Boot").
+	Name string `yaml:"name"`
+	// Tier is types.TierRuntime or types.TierFramework.
+	Tier string `yaml:"tier"`
+	// Match lists package name globs (path.Match syntax, case-insensitive).
+	Match []string `yaml:"match"`
+	// Types optionally restricts the entry to syft package types.
+	Types []string `yaml:"types,omitempty"`
+}
+
+// Validate checks an entry for structural errors.
+func (e Entry) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("framework entry: name is required")
+	}
+	if e.Tier != types.TierRuntime && e.Tier != types.TierFramework {
+		return fmt.Errorf("framework %q: tier must be %q or %q", e.Name, types.TierRuntime, types.TierFramework)
+	}
+	if len(e.Match) == 0 {
+		return fmt.Errorf("framework %q: at least one match pattern is required", e.Name)
+	}
+	for _, pattern := range e.Match {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("framework %q: invalid pattern %q", e.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the entry applies to the package.
+func (e Entry) matches(pkg types.PackageSummary) bool {
+	if len(e.Types) > 0 && !slices.Contains(e.Types, pkg.Type) {
+		return false
+	}
+	name := strings.ToLower(pkg.Name)
+	for _, pattern := range e.Match {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog is an ordered list of entries; the first matching entry wins.
+type Catalog struct {
+	entries []Entry
+}
+
+// builtinEntries parses the embedded catalogue once. The file is fixed at
+// build time and validated by tests, so a parse failure is a programming error.
+var builtinEntries = sync.OnceValue(func() []Entry {
+	var entries []Entry
+	if err := yaml.Unmarshal(builtinCatalog, &entries); err != nil {
+		panic(fmt.Sprintf("invalid built-in framework catalogue: %v", err))
+	}
+	return entries
+})
+
+// Default returns the built-in catalogue.
+func Default() *Catalog {
+	return &Catalog{entries: slices.Clone(builtinEntries())}
+}
+
+// New returns the built-in catalogue extended with extra entries. Extra
+// entries are evaluated first, so they can override built-in classifications.
+func New(extra []Entry) (*Catalog, error) {
+	for _, e := range extra {
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Catalog{entries: append(slices.Clone(extra), builtinEntries()...)}, nil
+}
+
+// Entries returns the catalogue entries in evaluation order.
+func (c *Catalog) Entries() []Entry {
+	return c.entries
+}
+
+// Lookup returns the first entry that matches the package.
+func (c *Catalog) Lookup(pkg types.PackageSummary) (Entry, bool) {
+	for _, e := range c.entries {
+		if e.matches(pkg) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Classify sets the tier (and framework name, if any) of every package in
+// stats and rebuilds stats.TechStack from the runtimes and frameworks found.
+func (c *Catalog) Classify(stats *types.ImageStats) {
+	if stats == nil {
+		return
+	}
+
+	stack := make(map[string]types.TechStackEntry)
+	for i := range stats.Packages {
+		pkg := &stats.Packages[i]
+		pkg.Framework = ""
+		if e, ok := c.Lookup(*pkg); ok {
+			pkg.Tier = e.Tier
+			pkg.Framework = e.Name
+			if _, seen := stack[e.Name]; !seen {
+				stack[e.Name] = types.TechStackEntry{Name: e.Name, Version: pkg.Version, Tier: e.Tier}
+			}
+			continue
+		}
+		if osPackageTypes[pkg.Type] {
+			pkg.Tier = types.TierOS
+		} else {
+			pkg.Tier = types.TierLibrary
+		}
+	}
+
+	stats.TechStack = make([]types.TechStackEntry, 0, len(stack))
+	for _, e := range stack {
+		stats.TechStack = append(stats.TechStack, e)
+	}
+	sort.Slice(stats.TechStack, func(i, j int) bool {
+		a, b := stats.TechStack[i], stats.TechStack[j]
+		if a.Tier != b.Tier {
+			return a.Tier == types.TierRuntime
+		}
+		return a.Name < b.Name
+	})
+}
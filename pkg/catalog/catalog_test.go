@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestBuiltinCatalogValid(t *testing.T) {
+	entries := Default().Entries()
+	if len(entries) == 0 {
+		t.Fatal("built-in catalogue is empty")
+	}
+	for _, e := range entries {
+		if err := e.Validate(); err != nil {
+			t.Errorf("built-in entry invalid: %v", err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cat := Default()
+	tests := []struct {
+		pkg      types.PackageSummary
+		wantName string
+		wantOK   bool
+	}{
+		{types.PackageSummary{Name: "openjdk17-jre", Type: "apk"}, "OpenJDK", true},
+		{types.PackageSummary{Name: "nodejs", Type: "deb"}, "Node.js", true},
+		{types.PackageSummary{Name: "stdlib", Type: "go-module"}, "Go", true},
+		{types.PackageSummary{Name: "spring-boot-starter-web", Type: "java-archive"}, "Spring Boot", true},
+		{types.PackageSummary{Name: "Django", Type: "python"}, "Django", true},
+		{types.PackageSummary{Name: "express", Type: "npm"}, "Express", true},
+		// Types restrict matches: an npm package called "django" is not Django.
+		{types.PackageSummary{Name: "django", Type: "npm"}, "", false},
+		{types.PackageSummary{Name: "musl", Type: "apk"}, "", false},
+	}
+	for _, tt := range tests {
+		e, ok := cat.Lookup(tt.pkg)
+		if ok != tt.wantOK || e.Name != tt.wantName {
+			t.Errorf("Lookup(%s/%s) = (%q, %v), want (%q, %v)", tt.pkg.Type, tt.pkg.Name, e.Name, ok, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	stats := &types.ImageStats{
+		Packages: []types.PackageSummary{
+			{Name: "spring-core", Version: "6.1.2", Type: "java-archive"},
+			{Name: "musl", Version: "1.2.4", Type: "apk"},
+			{Name: "openjdk17-jre", Version: "17.0.9", Type: "apk"},
+			{Name: "jackson-databind", Version: "2.16.0", Type: "java-archive"},
+			{Name: "spring-web", Version: "6.1.2", Type: "java-archive"},
+		},
+	}
+	Default().Classify(stats)
+
+	wantTiers := map[string]string{
+		"spring-core":      types.TierFramework,
+		"musl":             types.TierOS,
+		"openjdk17-jre":    types.TierRuntime,
+		"jackson-databind": types.TierLibrary,
+		"spring-web":       types.TierFramework,
+	}
+	for _, p := range stats.Packages {
+		if p.Tier != wantTiers[p.Name] {
+			t.Errorf("%s tier = %q, want %q", p.Name, p.Tier, wantTiers[p.Name])
+		}
+	}
+	if stats.Packages[0].Framework != "Spring Framework" {
+		t.Errorf("spring-core framework = %q, want Spring Framework", stats.Packages[0].Framework)
+	}
+
+	want := []types.TechStackEntry{
+		{Name: "OpenJDK", Version: "17.0.9", Tier: types.TierRuntime},
+		{Name: "Spring Framework", Version: "6.1.2", Tier: types.TierFramework},
+	}
+	if len(stats.TechStack) != len(want) {
+		t.Fatalf("TechStack = %+v, want %+v", stats.TechStack, want)
+	}
+	for i := range want {
+		if stats.TechStack[i] != want[i] {
+			t.Errorf("TechStack[%d] = %+v, want %+v", i, stats.TechStack[i], want[i])
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	cat, err := New([]Entry{
+		{Name: "Acme Platform", Tier: types.TierFramework, Match: []string{"acme-*"}},
+		{Name: "Patched Node", Tier: types.TierRuntime, Match: []string{"nodejs"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if e, ok := cat.Lookup(types.PackageSummary{Name: "acme-core", Type: "npm"}); !ok || e.Name != "Acme Platform" {
+		t.Errorf("Lookup(acme-core) = (%q, %v), want Acme Platform", e.Name, ok)
+	}
+	if e, _ := cat.Lookup(types.PackageSummary{Name: "nodejs", Type: "apk"}); e.Name != "Patched Node" {
+		t.Errorf("configured entry should override built-in, got %q", e.Name)
+	}
+
+	tests := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{Tier: types.TierRuntime, Match: []string{"x"}}, "name is required"},
+		{Entry{Name: "X", Tier: types.TierLibrary, Match: []string{"x"}}, "tier must be"},
+		{Entry{Name: "X", Tier: types.TierRuntime}, "match pattern is required"},
+		{Entry{Name: "X", Tier: types.TierRuntime, Match: []string{"[x"}}, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		if _, err := New([]Entry{tt.entry}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("New(%+v) error = %v, want %q", tt.entry, err, tt.want)
+		}
+	}
+}
@@ -5,7 +5,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -33,6 +37,9 @@ type ImageEntry struct {
 	Source string `yaml:"source,omitempty"`
 	// Tag is the Docker image tag to analyze.
 	Tag string `yaml:"tag"`
+	// Reports lists existing SBOM or scan reports (SPDX, CycloneDX, syft,
+	// grype, trivy or SARIF JSON) to import instead of analyzing the image.
+	Reports []string `yaml:"reports,omitempty"`
 }
 
 // Section describes a single output section in a dock-docs configuration file.
@@ -42,11 +49,28 @@ type Section struct {
 	// Image section specific
 	Source string `yaml:"source,omitempty"` // Dockerfile path
 	Tag    string `yaml:"tag,omitempty"`    // Single image tag for image analysis
+	// Reports lists existing SBOM or scan reports to import instead of
+	// analyzing Tag (see ImageEntry.Reports).
+	Reports []string `yaml:"reports,omitempty"`
 	// Comparison section specific
 	Images  []ImageEntry `yaml:"images,omitempty"`
 	Details bool         `yaml:"details,omitempty"` // Show full per-image analysis (collapsed) in comparison
+	// PerPlatform analyzes every platform of a multi-arch image separately.
+	PerPlatform bool `yaml:"perPlatform,omitempty"`
+	// CompareBase analyzes the base image of the final Dockerfile stage and
+	// reports what the image inherits from it and what its layers introduce.
+	CompareBase bool `yaml:"compareBase,omitempty"`
+	// BaseImage overrides the base image read from the Dockerfile (e.g.,
+	// when the FROM reference depends on build arguments). Setting it
+	// implies CompareBase.
+	BaseImage string `yaml:"baseImage,omitempty"`
 	// Template overrides the global template for this section.
 	Template *TemplateConfig `yaml:"template,omitempty"`
+	// Policy overrides the global security policy for this section.
+	Policy *PolicyConfig `yaml:"policy,omitempty"`
+	// Runners overrides the global runner settings for this section, field
+	// by field.
+	Runners map[string]RunnerConfig `yaml:"runners,omitempty"`
 }
 
 // ResolvedImages returns the list of image tags for a comparison section,
@@ -76,12 +100,301 @@ type ToolConfig struct {
 	URL string `yaml:"url,omitempty"`
 }
 
+// PluginConfig declares an external analysis plugin. The executable receives
+// the image reference and Options as JSON on stdin and prints a partial
+// ImageStats document (plus optional custom fields) on stdout.
+type PluginConfig struct {
+	// Name identifies the plugin in logs and namespaces its custom fields.
+	Name string `yaml:"name"`
+	// Command is the executable to run (a name on PATH or a file path).
+	Command string `yaml:"command"`
+	// Args are extra command-line arguments passed to the executable.
+	Args []string `yaml:"args,omitempty"`
+	// Options are passed through to the plugin in the request's "options" field.
+	Options map[string]any `yaml:"options,omitempty"`
+	// Timeout bounds a single plugin invocation (e.g., "2m"). Defaults to the scan timeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// BuiltinRunners are the names of the built-in analysis runners, as used as
+// keys of the runners config.
+var BuiltinRunners = []string{"runtime", "manifest", "syft", "grype", "dive"}
+
+// RunnerConfig tunes an analysis runner: a built-in runner (see
+// BuiltinRunners) or a plugin, by name.
+type RunnerConfig struct {
+	// Enabled turns the runner on or off. Runners are enabled by default.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Timeout bounds a single run of the tool (e.g., "10m"). Defaults to 30s
+	// for runtime and manifest and 5m for the other runners.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Retries is the number of retries after transient failures, such as
+	// registry rate limits or network errors.
+	Retries int `yaml:"retries,omitempty"`
+	// Backoff is the delay before the first retry (default 2s), doubled for
+	// every further retry.
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+	// Args are extra command-line arguments for syft, grype, dive or a plugin.
+	Args []string `yaml:"args,omitempty"`
+}
+
+// IsEnabled reports whether the runner is enabled.
+func (r RunnerConfig) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// merge returns r with the fields set in o overriding its own.
+func (r RunnerConfig) merge(o RunnerConfig) RunnerConfig {
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+	if o.Timeout != 0 {
+		r.Timeout = o.Timeout
+	}
+	if o.Retries != 0 {
+		r.Retries = o.Retries
+	}
+	if o.Backoff != 0 {
+		r.Backoff = o.Backoff
+	}
+	if o.Args != nil {
+		r.Args = o.Args
+	}
+	return r
+}
+
+// validate checks the settings of the runner with the given name.
+func (r RunnerConfig) validate(name string) error {
+	if r.Timeout < 0 || r.Backoff < 0 {
+		return fmt.Errorf("timeout and backoff must not be negative")
+	}
+	if r.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if len(r.Args) > 0 && (name == "runtime" || name == "manifest") {
+		return fmt.Errorf("args are not supported for the %s runner", name)
+	}
+	return nil
+}
+
+// SuppressionRule accepts the risk of a vulnerability so that it is reported
+// under "Accepted Risks" instead of counting towards summaries and badges.
+type SuppressionRule struct {
+	// ID is the vulnerability identifier (e.g., "CVE-2023-1234").
+	ID string `yaml:"id"`
+	// Package restricts the rule to a package name (optional).
+	Package string `yaml:"package,omitempty"`
+	// Versions restricts the rule to installed versions matching a constraint
+	// such as ">=1.0, <1.2.3" (optional).
+	Versions string `yaml:"versions,omitempty"`
+	// Justification explains why the risk is accepted. Required.
+	Justification string `yaml:"justification"`
+	// Status records the triage outcome: "not_affected" or "false_positive".
+	// Empty (the default) accepts the risk of an affected image.
+	Status string `yaml:"status,omitempty"`
+	// Expires is the date (YYYY-MM-DD) after which the rule stops applying.
+	Expires Date `yaml:"expires,omitempty"`
+}
+
+// Date is a calendar date written as YYYY-MM-DD, quoted or not. It decodes
+// to midnight UTC.
+type Date struct {
+	time.Time
+}
+
+// UnmarshalYAML parses a YYYY-MM-DD scalar.
+func (d *Date) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a date (YYYY-MM-DD)", value.Line)
+	}
+	t, err := time.Parse(time.DateOnly, value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid date %q (want YYYY-MM-DD)", value.Line, value.Value)
+	}
+	d.Time = t
+	return nil
+}
+
+// SuppressionConfig lists accepted vulnerability risks, either inline or
+// from OpenVEX documents.
+type SuppressionConfig struct {
+	Ignore []SuppressionRule `yaml:"ignore,omitempty"`
+	// VEX lists paths to OpenVEX documents whose not_affected and fixed
+	// statements are treated as suppressions.
+	VEX []string `yaml:"vex,omitempty"`
+}
+
+// PolicyConfig defines the security gate evaluated against every analyzed
+// image. When any rule fails, the documentation is still written but
+// dock-docs exits with a distinct non-zero status.
+type PolicyConfig struct {
+	// MaxCritical is the maximum number of Critical vulnerabilities allowed.
+	MaxCritical *int `yaml:"maxCritical,omitempty"`
+	// MaxHigh is the maximum number of High vulnerabilities allowed.
+	MaxHigh *int `yaml:"maxHigh,omitempty"`
+	// OnlyFixable counts only vulnerabilities with a known fix towards the limits.
+	OnlyFixable bool `yaml:"onlyFixable,omitempty"`
+	// MinEfficiency is the minimum Dive efficiency score (0-100).
+	MinEfficiency float64 `yaml:"minEfficiency,omitempty"`
+	// MaxSizeMB is the maximum image size in megabytes.
+	MaxSizeMB float64 `yaml:"maxSizeMB,omitempty"`
+	// ForbiddenPackages lists package names or globs (e.g., "log4j-*") that
+	// must not be installed.
+	ForbiddenPackages []string `yaml:"forbiddenPackages,omitempty"`
+	// DenyEOL fails when the distribution or a runtime in the image is past
+	// its end of life.
+	DenyEOL bool `yaml:"denyEOL,omitempty"`
+	// AllowUnscanned passes rules whose input was not measured (e.g., the
+	// vulnerability limits when grype did not run) instead of failing them.
+	AllowUnscanned bool `yaml:"allowUnscanned,omitempty"`
+}
+
+// validate checks the policy thresholds for out-of-range values.
+func (p *PolicyConfig) validate() error {
+	if p.MaxCritical != nil && *p.MaxCritical < 0 {
+		return fmt.Errorf("maxCritical must not be negative")
+	}
+	if p.MaxHigh != nil && *p.MaxHigh < 0 {
+		return fmt.Errorf("maxHigh must not be negative")
+	}
+	if p.MinEfficiency < 0 || p.MinEfficiency > 100 {
+		return fmt.Errorf("minEfficiency must be between 0 and 100")
+	}
+	if p.MaxSizeMB < 0 {
+		return fmt.Errorf("maxSizeMB must not be negative")
+	}
+	for _, pattern := range p.ForbiddenPackages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("forbiddenPackages: invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// FrameworkConfig adds an entry to the framework catalogue used to build the
+// "tech stack" summary. Configured entries take precedence over built-ins.
+type FrameworkConfig struct {
+	// Name is the display name (e.g., "Acme Platform").
+	Name string `yaml:"name"`
+	// Tier is "runtime" or "framework".
+	Tier string `yaml:"tier"`
+	// Match lists package name globs (e.g., "acme-platform-*").
+	Match []string `yaml:"match"`
+	// Types optionally restricts matching to package types (e.g., "java-archive").
+	Types []string `yaml:"types,omitempty"`
+}
+
+// LicenseConfig configures license reporting.
+type LicenseConfig struct {
+	// Deny lists licenses (case-insensitive globs such as "AGPL-*") that are
+	// flagged in the rendered output.
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// DriftConfig enables drift detection between the Dockerfile documentation
+// and the configuration of the analyzed image.
+type DriftConfig struct {
+	// Ignore lists ENV names, LABEL keys or ports ("8080/tcp") to skip, as
+	// path.Match globs (e.g., "PATH", "org.opencontainers.*").
+	Ignore []string `yaml:"ignore,omitempty"`
+	// FailOn lists the finding kinds ("missing", "undocumented", "changed")
+	// that fail the run. By default findings are only reported.
+	FailOn []string `yaml:"failOn,omitempty"`
+}
+
+// LifecycleConfig configures end-of-life detection of the distribution and
+// runtimes in analyzed images.
+type LifecycleConfig struct {
+	// File is a lifecycle dataset in the format of the built-in one. Its
+	// products are checked before the built-in ones, so it can add releases
+	// or update end-of-life dates.
+	File string `yaml:"file,omitempty"`
+	// WarnDays is how many days before its end of life a release is
+	// reported as ending soon (default 90).
+	WarnDays int `yaml:"warnDays,omitempty"`
+}
+
+// CacheConfig configures the on-disk analysis cache.
+type CacheConfig struct {
+	// Dir overrides the cache directory (default: the user cache directory,
+	// e.g. ~/.cache/dock-docs).
+	Dir string `yaml:"dir,omitempty"`
+	// TTL is the maximum age of a cached result (e.g., "12h"). Defaults to 24h.
+	TTL time.Duration `yaml:"ttl,omitempty"`
+}
+
+// ConcurrencyConfig bounds how many analysis runners execute at once across
+// every section of a run.
+type ConcurrencyConfig struct {
+	// Max is the number of runners executing at once (default: the number
+	// of CPUs).
+	Max int `yaml:"max,omitempty"`
+	// Runners limits concurrent instances per runner name (e.g., grype: 2).
+	// Runner names are those of the runners settings: "runtime",
+	// "manifest", "syft", "grype", "dive" and plugin names.
+	Runners map[string]int `yaml:"runners,omitempty"`
+}
+
+// RuntimeConfig selects the container runtime used to inspect, pull and list
+// images, and the daemon it (and every analysis tool) connects to.
+type RuntimeConfig struct {
+	// Backend is "docker", "podman", "nerdctl", "skopeo" or "buildah". By
+	// default the first installed one is used, in that order. The
+	// DOCK_DOCS_RUNTIME environment variable overrides it.
+	Backend string `yaml:"backend,omitempty"`
+	// Context is a docker context name whose endpoint and TLS settings are used.
+	Context string `yaml:"context,omitempty"`
+	// Host is the daemon address (e.g., "tcp://build:2376"), exported as DOCKER_HOST.
+	Host string `yaml:"host,omitempty"`
+	// TLSVerify enables TLS verification of the daemon (DOCKER_TLS_VERIFY).
+	TLSVerify bool `yaml:"tlsVerify,omitempty"`
+	// CertPath is a directory containing ca.pem, cert.pem and key.pem
+	// (DOCKER_CERT_PATH).
+	CertPath string `yaml:"certPath,omitempty"`
+	// PodmanConnection is a podman system connection name.
+	PodmanConnection string `yaml:"podmanConnection,omitempty"`
+	// Pull is the pull policy: "if-not-present" (default), "always" or "never".
+	Pull string `yaml:"pull,omitempty"`
+	// Platform ("os/arch[/variant]") is requested when pulling images by tag.
+	Platform string `yaml:"platform,omitempty"`
+	// Auth configures registry credentials for pulls and scans.
+	Auth *RegistryAuthConfig `yaml:"auth,omitempty"`
+}
+
+// RegistryAuthConfig configures the registry credentials. Secrets are never
+// read from the config file itself: the username and password come from
+// environment variables.
+type RegistryAuthConfig struct {
+	// DockerConfig is the path of a docker config.json (or its directory).
+	DockerConfig string `yaml:"dockerConfig,omitempty"`
+	// Registry is the registry host the username and password log in to
+	// (default "docker.io").
+	Registry string `yaml:"registry,omitempty"`
+	// UsernameEnv names the environment variable holding the username
+	// (default DOCK_DOCS_REGISTRY_USERNAME).
+	UsernameEnv string `yaml:"usernameEnv,omitempty"`
+	// PasswordEnv names the environment variable holding the password or
+	// token (default DOCK_DOCS_REGISTRY_PASSWORD).
+	PasswordEnv string `yaml:"passwordEnv,omitempty"`
+}
+
 // Config is the top-level structure for a dock-docs YAML configuration file.
 type Config struct {
-	Output       string                `yaml:"output"`
-	BadgeBaseURL string                `yaml:"badgeBaseURL,omitempty"`
-	Tools        map[string]ToolConfig `yaml:"tools,omitempty"`
-	Sections     []Section             `yaml:"sections"`
+	Output       string                  `yaml:"output"`
+	BadgeBaseURL string                  `yaml:"badgeBaseURL,omitempty"`
+	Tools        map[string]ToolConfig   `yaml:"tools,omitempty"`
+	Plugins      []PluginConfig          `yaml:"plugins,omitempty"`
+	Suppressions *SuppressionConfig      `yaml:"suppressions,omitempty"`
+	Policy       *PolicyConfig           `yaml:"policy,omitempty"`
+	Licenses     *LicenseConfig          `yaml:"licenses,omitempty"`
+	Frameworks   []FrameworkConfig       `yaml:"frameworks,omitempty"`
+	Drift        *DriftConfig            `yaml:"drift,omitempty"`
+	Lifecycle    *LifecycleConfig        `yaml:"lifecycle,omitempty"`
+	Cache        *CacheConfig            `yaml:"cache,omitempty"`
+	Runtime      *RuntimeConfig          `yaml:"runtime,omitempty"`
+	Concurrency  *ConcurrencyConfig      `yaml:"concurrency,omitempty"`
+	Runners      map[string]RunnerConfig `yaml:"runners,omitempty"`
+	Sections     []Section               `yaml:"sections"`
 	// Template is the global template configuration (can be overridden per-section).
 	Template *TemplateConfig `yaml:"template,omitempty"`
 }
@@ -123,6 +436,50 @@ func (c *Config) ResolveTemplate(section Section) *TemplateConfig {
 	return c.Template
 }
 
+// ResolvePolicy returns the effective security policy for a section,
+// considering the section-level override and global default.
+// Returns nil if no policy is configured.
+func (c *Config) ResolvePolicy(section Section) *PolicyConfig {
+	if section.Policy != nil {
+		return section.Policy
+	}
+	return c.Policy
+}
+
+// ResolveRunners returns the effective runner settings for a section: the
+// global settings, overridden field by field by the section's settings.
+// Runners without settings are absent from the result.
+func (c *Config) ResolveRunners(section Section) map[string]RunnerConfig {
+	if len(c.Runners) == 0 && len(section.Runners) == 0 {
+		return nil
+	}
+	resolved := make(map[string]RunnerConfig, len(c.Runners)+len(section.Runners))
+	for name, r := range c.Runners {
+		resolved[name] = r
+	}
+	for name, r := range section.Runners {
+		resolved[name] = resolved[name].merge(r)
+	}
+	return resolved
+}
+
+// validateRunners checks runner settings keyed by runner name. Names must
+// be built-in runners or configured plugins.
+func (c *Config) validateRunners(prefix string, runners map[string]RunnerConfig) error {
+	for name, r := range runners {
+		known := slices.Contains(BuiltinRunners, name) || slices.ContainsFunc(c.Plugins, func(p PluginConfig) bool {
+			return p.Name == name
+		})
+		if !known {
+			return fmt.Errorf("%s: unknown runner %q (want %s or a plugin name)", prefix, name, strings.Join(BuiltinRunners, ", "))
+		}
+		if err := r.validate(name); err != nil {
+			return fmt.Errorf("%s.%s: %w", prefix, name, err)
+		}
+	}
+	return nil
+}
+
 // Validate checks the config for structural errors. It returns an error
 // describing the first problem found, or nil if the config is valid.
 func (c *Config) Validate() error {
@@ -142,6 +499,135 @@ func (c *Config) Validate() error {
 		if s.Type == SectionTypeComparison && len(s.Images) == 0 {
 			return fmt.Errorf("section %d: comparison section must have at least one image", i)
 		}
+
+		if s.Policy != nil {
+			if err := s.Policy.validate(); err != nil {
+				return fmt.Errorf("section %d: policy: %w", i, err)
+			}
+		}
+
+		if err := c.validateRunners(fmt.Sprintf("section %d: runners", i), s.Runners); err != nil {
+			return err
+		}
+	}
+
+	if err := c.validateRunners("runners", c.Runners); err != nil {
+		return err
+	}
+
+	if c.Policy != nil {
+		if err := c.Policy.validate(); err != nil {
+			return fmt.Errorf("policy: %w", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range c.Plugins {
+		if p.Name == "" {
+			return fmt.Errorf("plugin %d: name is required", i)
+		}
+		if p.Command == "" {
+			return fmt.Errorf("plugin %q: command is required", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("plugin %q: duplicate plugin name", p.Name)
+		}
+		if slices.Contains(BuiltinRunners, p.Name) {
+			return fmt.Errorf("plugin %q: name is reserved for a built-in runner", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	for i, f := range c.Frameworks {
+		if f.Name == "" {
+			return fmt.Errorf("framework %d: name is required", i)
+		}
+		if f.Tier != "runtime" && f.Tier != "framework" {
+			return fmt.Errorf("framework %q: tier must be \"runtime\" or \"framework\"", f.Name)
+		}
+		if len(f.Match) == 0 {
+			return fmt.Errorf("framework %q: match is required", f.Name)
+		}
+	}
+
+	if c.Licenses != nil {
+		for _, pattern := range c.Licenses.Deny {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("licenses.deny: invalid pattern %q", pattern)
+			}
+		}
+	}
+
+	if c.Drift != nil {
+		for _, pattern := range c.Drift.Ignore {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("drift.ignore: invalid pattern %q", pattern)
+			}
+		}
+		for _, kind := range c.Drift.FailOn {
+			switch kind {
+			case "missing", "undocumented", "changed":
+			default:
+				return fmt.Errorf("drift.failOn: unknown kind %q (want missing, undocumented or changed)", kind)
+			}
+		}
+	}
+
+	if c.Lifecycle != nil && c.Lifecycle.WarnDays < 0 {
+		return fmt.Errorf("lifecycle.warnDays must not be negative")
+	}
+
+	if c.Cache != nil && c.Cache.TTL < 0 {
+		return fmt.Errorf("cache.ttl must not be negative")
+	}
+
+	if c.Concurrency != nil {
+		if c.Concurrency.Max < 0 {
+			return fmt.Errorf("concurrency.max must not be negative")
+		}
+		for name, n := range c.Concurrency.Runners {
+			if n < 1 {
+				return fmt.Errorf("concurrency.runners.%s must be at least 1", name)
+			}
+		}
+	}
+
+	if c.Runtime != nil {
+		switch c.Runtime.Backend {
+		case "", "docker", "podman", "nerdctl", "skopeo", "buildah":
+		default:
+			return fmt.Errorf("runtime.backend: unknown backend %q (want docker, podman, nerdctl, skopeo or buildah)", c.Runtime.Backend)
+		}
+		set := 0
+		for _, v := range []string{c.Runtime.Context, c.Runtime.Host, c.Runtime.PodmanConnection} {
+			if v != "" {
+				set++
+			}
+		}
+		if set > 1 {
+			return fmt.Errorf("runtime: context, host and podmanConnection are mutually exclusive")
+		}
+		switch c.Runtime.Pull {
+		case "", "if-not-present", "always", "never":
+		default:
+			return fmt.Errorf("runtime.pull: unknown policy %q (want always, if-not-present or never)", c.Runtime.Pull)
+		}
+	}
+
+	if c.Suppressions != nil {
+		for i, r := range c.Suppressions.Ignore {
+			if r.ID == "" {
+				return fmt.Errorf("suppression %d: id is required", i)
+			}
+			if r.Justification == "" {
+				return fmt.Errorf("suppression %q: justification is required", r.ID)
+			}
+			switch r.Status {
+			case "", "not_affected", "false_positive":
+			default:
+				return fmt.Errorf("suppression %q: unknown status %q (want not_affected or false_positive)", r.ID, r.Status)
+			}
+		}
 	}
 
 	return nil
@@ -164,8 +650,45 @@ func (c *Config) ResolveRelativePaths(baseDir string) {
 
 	c.Output = resolve(c.Output)
 
+	if c.Cache != nil {
+		c.Cache.Dir = resolve(c.Cache.Dir)
+	}
+
+	if c.Lifecycle != nil {
+		c.Lifecycle.File = resolve(c.Lifecycle.File)
+	}
+
+	if c.Runtime != nil {
+		c.Runtime.CertPath = resolve(c.Runtime.CertPath)
+		if c.Runtime.Auth != nil {
+			c.Runtime.Auth.DockerConfig = resolve(c.Runtime.Auth.DockerConfig)
+		}
+	}
+
+	// Plugin commands given as bare names are looked up on PATH;
+	// only commands containing a path separator are config-relative.
+	for i := range c.Plugins {
+		if strings.ContainsRune(c.Plugins[i].Command, filepath.Separator) {
+			c.Plugins[i].Command = resolve(c.Plugins[i].Command)
+		}
+	}
+
+	if c.Suppressions != nil {
+		for i := range c.Suppressions.VEX {
+			c.Suppressions.VEX[i] = resolve(c.Suppressions.VEX[i])
+		}
+	}
+
 	for i := range c.Sections {
 		c.Sections[i].Source = resolve(c.Sections[i].Source)
+		for j := range c.Sections[i].Reports {
+			c.Sections[i].Reports[j] = resolve(c.Sections[i].Reports[j])
+		}
+		for j := range c.Sections[i].Images {
+			for k := range c.Sections[i].Images[j].Reports {
+				c.Sections[i].Images[j].Reports[k] = resolve(c.Sections[i].Images[j].Reports[k])
+			}
+		}
 		if c.Sections[i].Template != nil {
 			c.Sections[i].Template.Path = resolve(c.Sections[i].Template.Path)
 		}
@@ -3,8 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_ValidConfig(t *testing.T) {
@@ -772,3 +774,652 @@ func TestResolveRelativePaths(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_WithPlugins(t *testing.T) {
+	yamlContent := `output: "README.md"
+plugins:
+  - name: "licenses"
+    command: "./bin/license-check"
+    args: ["--strict"]
+    timeout: "45s"
+    options:
+      deny: ["AGPL-3.0"]
+  - name: "scanner"
+    command: "internal-scanner"
+sections:
+  - type: "image"
+    marker: "main"
+`
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(cfg.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %d", len(cfg.Plugins))
+	}
+	p := cfg.Plugins[0]
+	if p.Name != "licenses" || p.Command != "./bin/license-check" {
+		t.Errorf("unexpected plugin: %+v", p)
+	}
+	if len(p.Args) != 1 || p.Args[0] != "--strict" {
+		t.Errorf("Args = %v, want [--strict]", p.Args)
+	}
+	if p.Timeout != 45*time.Second {
+		t.Errorf("Timeout = %v, want 45s", p.Timeout)
+	}
+	if _, ok := p.Options["deny"]; !ok {
+		t.Errorf("expected deny option, got %v", p.Options)
+	}
+
+	cfg.ResolveRelativePaths("/projects/app")
+	if cfg.Plugins[0].Command != "/projects/app/bin/license-check" {
+		t.Errorf("path command not resolved: %q", cfg.Plugins[0].Command)
+	}
+	if cfg.Plugins[1].Command != "internal-scanner" {
+		t.Errorf("bare command should stay on PATH lookup, got %q", cfg.Plugins[1].Command)
+	}
+}
+
+func TestValidate_Plugins(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+	tests := []struct {
+		name    string
+		plugins []PluginConfig
+		wantErr string
+	}{
+		{name: "valid", plugins: []PluginConfig{{Name: "a", Command: "a"}}},
+		{name: "missing name", plugins: []PluginConfig{{Command: "a"}}, wantErr: "name is required"},
+		{name: "missing command", plugins: []PluginConfig{{Name: "a"}}, wantErr: "command is required"},
+		{name: "duplicate", plugins: []PluginConfig{{Name: "a", Command: "a"}, {Name: "a", Command: "b"}}, wantErr: "duplicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sections: section, Plugins: tt.plugins}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_WithSuppressions(t *testing.T) {
+	yamlContent := `output: "README.md"
+suppressions:
+  ignore:
+    - id: "CVE-2024-0001"
+      package: "openssl"
+      versions: "<3.0.2"
+      justification: "TLS is terminated at the load balancer"
+      expires: 2030-01-31
+    - id: "GHSA-aaaa-bbbb-cccc"
+      justification: "dev dependency only"
+    - id: "CVE-2024-0002"
+      justification: "quoted date"
+      expires: "2030-02-28"
+  vex:
+    - "security/app.openvex.json"
+sections:
+  - type: "image"
+    marker: "main"
+`
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Suppressions == nil || len(cfg.Suppressions.Ignore) != 3 {
+		t.Fatalf("expected 3 suppressions, got %+v", cfg.Suppressions)
+	}
+	r := cfg.Suppressions.Ignore[0]
+	if r.ID != "CVE-2024-0001" || r.Package != "openssl" || r.Versions != "<3.0.2" {
+		t.Errorf("unexpected rule: %+v", r)
+	}
+	if want := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC); !r.Expires.Equal(want) {
+		t.Errorf("Expires = %v, want %v", r.Expires, want)
+	}
+	if !cfg.Suppressions.Ignore[1].Expires.IsZero() {
+		t.Errorf("expected zero expiry for rule without expires, got %v", cfg.Suppressions.Ignore[1].Expires)
+	}
+	if want := time.Date(2030, 2, 28, 0, 0, 0, 0, time.UTC); !cfg.Suppressions.Ignore[2].Expires.Equal(want) {
+		t.Errorf("quoted Expires = %v, want %v", cfg.Suppressions.Ignore[2].Expires, want)
+	}
+
+	cfg.ResolveRelativePaths("/projects/app")
+	if got := cfg.Suppressions.VEX[0]; got != "/projects/app/security/app.openvex.json" {
+		t.Errorf("VEX path not resolved: %q", got)
+	}
+}
+
+func TestLoad_InvalidSuppressionExpiry(t *testing.T) {
+	yamlContent := `suppressions:
+  ignore:
+    - id: "CVE-2024-0001"
+      justification: "accepted"
+      expires: "30/06/2024"
+sections:
+  - type: "image"
+    marker: "main"
+`
+	configPath := filepath.Join(t.TempDir(), "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	_, err := Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), `invalid date "30/06/2024" (want YYYY-MM-DD)`) {
+		t.Errorf("Load() error = %v, want invalid date error", err)
+	}
+}
+
+func TestValidate_Suppressions(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+	tests := []struct {
+		name    string
+		rules   []SuppressionRule
+		wantErr string
+	}{
+		{name: "valid", rules: []SuppressionRule{{ID: "CVE-1", Justification: "ok"}}},
+		{name: "missing id", rules: []SuppressionRule{{Justification: "ok"}}, wantErr: "id is required"},
+		{name: "missing justification", rules: []SuppressionRule{{ID: "CVE-1"}}, wantErr: "justification is required"},
+		{name: "not affected", rules: []SuppressionRule{{ID: "CVE-1", Justification: "ok", Status: "not_affected"}}},
+		{name: "unknown status", rules: []SuppressionRule{{ID: "CVE-1", Justification: "ok", Status: "fixed"}}, wantErr: "unknown status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sections: section, Suppressions: &SuppressionConfig{Ignore: tt.rules}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_WithPolicy(t *testing.T) {
+	yamlContent := `output: "README.md"
+policy:
+  maxCritical: 0
+  maxHigh: 5
+  onlyFixable: true
+  minEfficiency: 90
+  maxSizeMB: 250
+  forbiddenPackages: ["log4j-*"]
+  allowUnscanned: true
+sections:
+  - type: "image"
+    marker: "main"
+  - type: "image"
+    marker: "legacy"
+    policy:
+      maxCritical: 10
+`
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p := cfg.ResolvePolicy(cfg.Sections[0])
+	if p == nil || p.MaxCritical == nil || *p.MaxCritical != 0 || p.MaxHigh == nil || *p.MaxHigh != 5 {
+		t.Fatalf("unexpected global policy: %+v", p)
+	}
+	if !p.OnlyFixable || p.MinEfficiency != 90 || p.MaxSizeMB != 250 || len(p.ForbiddenPackages) != 1 || !p.AllowUnscanned {
+		t.Errorf("unexpected global policy: %+v", p)
+	}
+	override := cfg.ResolvePolicy(cfg.Sections[1])
+	if override == nil || *override.MaxCritical != 10 || override.MaxHigh != nil {
+		t.Errorf("expected section override, got %+v", override)
+	}
+}
+
+func TestValidate_Policy(t *testing.T) {
+	neg := -1
+	tests := []struct {
+		name    string
+		policy  PolicyConfig
+		wantErr string
+	}{
+		{name: "valid", policy: PolicyConfig{MinEfficiency: 90, ForbiddenPackages: []string{"log4j-*"}}},
+		{name: "negative critical", policy: PolicyConfig{MaxCritical: &neg}, wantErr: "maxCritical"},
+		{name: "negative high", policy: PolicyConfig{MaxHigh: &neg}, wantErr: "maxHigh"},
+		{name: "efficiency out of range", policy: PolicyConfig{MinEfficiency: 150}, wantErr: "minEfficiency"},
+		{name: "negative size", policy: PolicyConfig{MaxSizeMB: -5}, wantErr: "maxSizeMB"},
+		{name: "bad glob", policy: PolicyConfig{ForbiddenPackages: []string{"[abc"}}, wantErr: "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.policy
+			cfg := &Config{Sections: []Section{{Type: SectionTypeImage, Marker: "main", Policy: &p}}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Licenses(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+
+	cfg := &Config{Sections: section, Licenses: &LicenseConfig{Deny: []string{"AGPL-*", "SSPL-1.0"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Licenses.Deny = []string{"[AGPL"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Validate() error = %v, want invalid pattern", err)
+	}
+}
+
+func TestValidate_Frameworks(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+	tests := []struct {
+		name      string
+		framework FrameworkConfig
+		wantErr   string
+	}{
+		{"valid", FrameworkConfig{Name: "Acme", Tier: "framework", Match: []string{"acme-*"}}, ""},
+		{"missing name", FrameworkConfig{Tier: "runtime", Match: []string{"x"}}, "name is required"},
+		{"bad tier", FrameworkConfig{Name: "Acme", Tier: "library", Match: []string{"x"}}, "tier must be"},
+		{"missing match", FrameworkConfig{Name: "Acme", Tier: "runtime"}, "match is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sections: section, Frameworks: []FrameworkConfig{tt.framework}}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_PerPlatform(t *testing.T) {
+	yamlContent := `output: "README.md"
+sections:
+  - type: "image"
+    marker: "main"
+    tag: "nginx:1.25"
+    perPlatform: true
+  - type: "comparison"
+    marker: "versions"
+    images:
+      - tag: "nginx:1.24"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Sections[0].PerPlatform {
+		t.Error("expected perPlatform to be enabled for the image section")
+	}
+	if cfg.Sections[1].PerPlatform {
+		t.Error("expected perPlatform to default to false")
+	}
+}
+
+func TestLoad_CompareBase(t *testing.T) {
+	yamlContent := `output: "README.md"
+sections:
+  - type: "image"
+    marker: "main"
+    tag: "app:1"
+    compareBase: true
+  - type: "image"
+    marker: "other"
+    tag: "app:2"
+    baseImage: "registry.example.com/base/java:21"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Sections[0].CompareBase || cfg.Sections[0].BaseImage != "" {
+		t.Errorf("section 0 = compareBase %v, baseImage %q; want true, \"\"", cfg.Sections[0].CompareBase, cfg.Sections[0].BaseImage)
+	}
+	if cfg.Sections[1].BaseImage != "registry.example.com/base/java:21" {
+		t.Errorf("section 1 baseImage = %q", cfg.Sections[1].BaseImage)
+	}
+}
+
+func TestValidate_Drift(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+
+	cfg := &Config{Sections: section, Drift: &DriftConfig{Ignore: []string{"PATH", "org.opencontainers.*"}, FailOn: []string{"missing", "changed"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Drift.FailOn = []string{"extra"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown kind") {
+		t.Errorf("Validate() error = %v, want unknown kind", err)
+	}
+
+	cfg.Drift = &DriftConfig{Ignore: []string{"[PATH"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Validate() error = %v, want invalid pattern", err)
+	}
+}
+
+func TestValidate_Runtime(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+
+	for _, backend := range []string{"", "docker", "podman", "nerdctl", "skopeo", "buildah"} {
+		cfg := &Config{Sections: section, Runtime: &RuntimeConfig{Backend: backend}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with backend %q: unexpected error: %v", backend, err)
+		}
+	}
+
+	cfg := &Config{Sections: section, Runtime: &RuntimeConfig{Backend: "lxc"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "runtime.backend") {
+		t.Errorf("Validate() error = %v, want runtime.backend", err)
+	}
+
+	cfg.Runtime = &RuntimeConfig{Host: "tcp://build:2376", TLSVerify: true, CertPath: "certs"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with host: unexpected error: %v", err)
+	}
+	cfg.Runtime.Context = "remote"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("Validate() error = %v, want mutually exclusive", err)
+	}
+
+	cfg.Runtime = &RuntimeConfig{Pull: "sometimes"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "runtime.pull") {
+		t.Errorf("Validate() error = %v, want runtime.pull", err)
+	}
+
+	cfg.Runtime = &RuntimeConfig{CertPath: "certs", Pull: "always", Auth: &RegistryAuthConfig{DockerConfig: ".docker"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with pull policy: unexpected error: %v", err)
+	}
+	cfg.ResolveRelativePaths("/projects/myapp")
+	if cfg.Runtime.CertPath != "/projects/myapp/certs" {
+		t.Errorf("Runtime.CertPath = %q, want /projects/myapp/certs", cfg.Runtime.CertPath)
+	}
+	if cfg.Runtime.Auth.DockerConfig != "/projects/myapp/.docker" {
+		t.Errorf("Runtime.Auth.DockerConfig = %q, want /projects/myapp/.docker", cfg.Runtime.Auth.DockerConfig)
+	}
+}
+
+func TestLoad_WithCache(t *testing.T) {
+	yamlContent := `cache:
+  dir: ".cache"
+  ttl: 12h
+sections:
+  - type: "image"
+    marker: "main"
+    tag: "app:latest"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache == nil || cfg.Cache.TTL != 12*time.Hour {
+		t.Fatalf("Cache = %+v, want ttl 12h", cfg.Cache)
+	}
+
+	cfg.ResolveRelativePaths(tmpDir)
+	if want := filepath.Join(tmpDir, ".cache"); cfg.Cache.Dir != want {
+		t.Errorf("Cache.Dir = %q, want %q", cfg.Cache.Dir, want)
+	}
+
+	cfg.Cache.TTL = -time.Hour
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "cache.ttl") {
+		t.Errorf("Validate() error = %v, want cache.ttl", err)
+	}
+}
+
+func TestLoad_WithLifecycle(t *testing.T) {
+	yamlContent := `lifecycle:
+  file: "lifecycle.yaml"
+  warnDays: 30
+policy:
+  denyEOL: true
+sections:
+  - type: "image"
+    marker: "main"
+    tag: "app:latest"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Lifecycle == nil || cfg.Lifecycle.WarnDays != 30 {
+		t.Fatalf("Lifecycle = %+v, want warnDays 30", cfg.Lifecycle)
+	}
+	if !cfg.Policy.DenyEOL {
+		t.Error("Policy.DenyEOL = false, want true")
+	}
+
+	cfg.ResolveRelativePaths(tmpDir)
+	if want := filepath.Join(tmpDir, "lifecycle.yaml"); cfg.Lifecycle.File != want {
+		t.Errorf("Lifecycle.File = %q, want %q", cfg.Lifecycle.File, want)
+	}
+
+	cfg.Lifecycle.WarnDays = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "lifecycle.warnDays") {
+		t.Errorf("Validate() error = %v, want lifecycle.warnDays", err)
+	}
+}
+
+func TestResolveRelativePaths_Reports(t *testing.T) {
+	cfg := Config{Sections: []Section{
+		{Type: SectionTypeImage, Reports: []string{"sbom.spdx.json", "/abs/grype.json"}},
+		{Type: SectionTypeComparison, Images: []ImageEntry{{Tag: "app:1", Reports: []string{"reports/trivy.json"}}}},
+	}}
+	cfg.ResolveRelativePaths("/projects/myapp")
+
+	want := []string{"/projects/myapp/sbom.spdx.json", "/abs/grype.json"}
+	if got := cfg.Sections[0].Reports; got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("section reports = %v, want %v", got, want)
+	}
+	if got := cfg.Sections[1].Images[0].Reports[0]; got != "/projects/myapp/reports/trivy.json" {
+		t.Errorf("image entry report = %q", got)
+	}
+}
+
+func TestValidate_Concurrency(t *testing.T) {
+	section := []Section{{Type: SectionTypeImage, Marker: "main"}}
+
+	tests := []struct {
+		name    string
+		conc    *ConcurrencyConfig
+		wantErr string
+	}{
+		{"limits", &ConcurrencyConfig{Max: 4, Runners: map[string]int{"grype": 2}}, ""},
+		{"default max", &ConcurrencyConfig{Runners: map[string]int{"dive": 1}}, ""},
+		{"runtime limit", &ConcurrencyConfig{Runners: map[string]int{"runtime": 1}}, ""},
+		{"negative max", &ConcurrencyConfig{Max: -1}, "concurrency.max"},
+		{"zero runner limit", &ConcurrencyConfig{Runners: map[string]int{"grype": 0}}, "concurrency.runners.grype"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sections: section, Concurrency: tt.conc}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Runners(t *testing.T) {
+	plugins := []PluginConfig{{Name: "licenses", Command: "license-check"}}
+
+	tests := []struct {
+		name    string
+		runners map[string]RunnerConfig
+		section map[string]RunnerConfig
+		wantErr string
+	}{
+		{"built-in and plugin", map[string]RunnerConfig{"grype": {Retries: 3, Args: []string{"--only-fixed"}}, "licenses": {Timeout: time.Minute}}, nil, ""},
+		{"unknown runner", map[string]RunnerConfig{"trivy": {}}, nil, `unknown runner "trivy"`},
+		{"negative retries", map[string]RunnerConfig{"syft": {Retries: -1}}, nil, "runners.syft: retries"},
+		{"negative timeout", map[string]RunnerConfig{"dive": {Timeout: -time.Second}}, nil, "runners.dive: timeout"},
+		{"args for runtime", map[string]RunnerConfig{"runtime": {Args: []string{"-q"}}}, nil, "args are not supported"},
+		{"section override", nil, map[string]RunnerConfig{"dive": {Enabled: new(bool)}}, ""},
+		{"unknown section runner", nil, map[string]RunnerConfig{"trivy": {}}, "section 0: runners"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Plugins:  plugins,
+				Runners:  tt.runners,
+				Sections: []Section{{Type: SectionTypeImage, Marker: "main", Runners: tt.section}},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{
+		Plugins:  []PluginConfig{{Name: "grype", Command: "grype-wrapper"}},
+		Sections: []Section{{Type: SectionTypeImage, Marker: "main"}},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("Validate() error = %v, want reserved plugin name", err)
+	}
+}
+
+func TestResolveRunners(t *testing.T) {
+	yamlContent := `
+runners:
+  dive:
+    enabled: false
+  grype:
+    timeout: 10m
+    retries: 3
+    backoff: 5s
+    args: ["--only-fixed"]
+sections:
+  - type: image
+    marker: main
+  - type: image
+    marker: detailed
+    runners:
+      dive:
+        enabled: true
+      grype:
+        retries: 1
+`
+	configPath := filepath.Join(t.TempDir(), "dock-docs.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	global := cfg.ResolveRunners(cfg.Sections[0])
+	if global["dive"].IsEnabled() {
+		t.Error("dive should be disabled globally")
+	}
+	if !global["syft"].IsEnabled() {
+		t.Error("runners without settings should be enabled")
+	}
+	want := RunnerConfig{Timeout: 10 * time.Minute, Retries: 3, Backoff: 5 * time.Second, Args: []string{"--only-fixed"}}
+	if !reflect.DeepEqual(global["grype"], want) {
+		t.Errorf("grype = %+v, want %+v", global["grype"], want)
+	}
+
+	section := cfg.ResolveRunners(cfg.Sections[1])
+	if !section["dive"].IsEnabled() {
+		t.Error("dive should be enabled by the section override")
+	}
+	want.Retries = 1
+	if !reflect.DeepEqual(section["grype"], want) {
+		t.Errorf("section grype = %+v, want %+v", section["grype"], want)
+	}
+	if cfg.Runners["grype"].Retries != 3 {
+		t.Error("ResolveRunners must not modify the global settings")
+	}
+
+	if got := (&Config{}).ResolveRunners(Section{}); got != nil {
+		t.Errorf("ResolveRunners() without settings = %v, want nil", got)
+	}
+}
@@ -0,0 +1,174 @@
+// Package drift compares the documentation extracted from a Dockerfile with
+// the configuration recorded in the built image, so stale images and
+// undocumented settings can be reported.
+package drift
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// maxPortRange caps the expansion of EXPOSE ranges such as "8000-8010".
+const maxPortRange = 1024
+
+// Error is returned when drift findings match the configured failure kinds.
+type Error struct {
+	Image    string
+	Findings []types.DriftFinding
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		msgs = append(msgs, f.Message())
+	}
+	return fmt.Sprintf("Dockerfile drift detected for %s: %s", e.Image, strings.Join(msgs, "; "))
+}
+
+// Detect compares the ENV, EXPOSE and LABEL instructions of the final build
+// stage (and the stages it is built FROM) with the image config, using the
+// names and values as written rather than their @name/@default overrides. Names matching one of the ignore patterns
+// (path.Match globs, e.g. "PATH" or "org.opencontainers.*") are skipped.
+// Findings are grouped by instruction; within a group, Dockerfile-side
+// findings come first in Dockerfile order, followed by undocumented image
+// settings.
+func Detect(doc *parser.Documentation, cfg *types.ImageConfig, ignore []string) []types.DriftFinding {
+	if doc == nil || cfg == nil {
+		return nil
+	}
+	ignored := func(name string) bool {
+		for _, pattern := range ignore {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var findings []types.DriftFinding
+	findings = append(findings, compareKV("ENV", doc.FinalStageItems("ENV"), cfg.EnvVars(), ignored)...)
+	findings = append(findings, comparePorts(doc.FinalStageItems("EXPOSE"), cfg.ExposedPorts, ignored)...)
+	findings = append(findings, compareKV("LABEL", doc.FinalStageItems("LABEL"), cfg.LabelList(), ignored)...)
+	return findings
+}
+
+// Failing returns the findings whose kind is one of kinds.
+func Failing(findings []types.DriftFinding, kinds []string) []types.DriftFinding {
+	var failing []types.DriftFinding
+	for _, f := range findings {
+		if slices.Contains(kinds, f.Kind) {
+			failing = append(failing, f)
+		}
+	}
+	return failing
+}
+
+// compareKV diffs ENV or LABEL items against the image's key/value pairs.
+// Values that reference build arguments or other variables ("$VERSION") cannot
+// be resolved statically, so only their presence is checked.
+func compareKV(instruction string, items []parser.DocItem, image []types.KeyValue, ignored func(string) bool) []types.DriftFinding {
+	imageValues := make(map[string]string, len(image))
+	for _, kv := range image {
+		imageValues[kv.Key] = kv.Value
+	}
+
+	var findings []types.DriftFinding
+	documented := make(map[string]bool, len(items))
+	for _, item := range items {
+		documented[item.Key] = true
+		if ignored(item.Key) {
+			continue
+		}
+		value, ok := imageValues[item.Key]
+		switch {
+		case !ok:
+			findings = append(findings, types.DriftFinding{
+				Kind: types.DriftMissing, Instruction: instruction, Name: item.Key, Dockerfile: item.Literal,
+			})
+		case !strings.Contains(item.Literal, "$") && item.Literal != value:
+			findings = append(findings, types.DriftFinding{
+				Kind: types.DriftChanged, Instruction: instruction, Name: item.Key, Dockerfile: item.Literal, Image: value,
+			})
+		}
+	}
+
+	for _, kv := range image {
+		if documented[kv.Key] || ignored(kv.Key) {
+			continue
+		}
+		findings = append(findings, types.DriftFinding{
+			Kind: types.DriftUndocumented, Instruction: instruction, Name: kv.Key, Image: kv.Value,
+		})
+	}
+	return findings
+}
+
+// comparePorts diffs EXPOSE items against the image's exposed ports. When a
+// documented port is a variable, undocumented image ports are not reported
+// because the variable may expand to any of them.
+func comparePorts(items []parser.DocItem, image []string, ignored func(string) bool) []types.DriftFinding {
+	imagePorts := make(map[string]bool, len(image))
+	for _, p := range image {
+		imagePorts[p] = true
+	}
+
+	var findings []types.DriftFinding
+	documented := make(map[string]bool)
+	dynamic := false
+	for _, item := range items {
+		if strings.Contains(item.Literal, "$") {
+			dynamic = true
+			continue
+		}
+		for _, port := range expandPort(item.Literal) {
+			documented[port] = true
+			if !imagePorts[port] && !ignored(port) {
+				findings = append(findings, types.DriftFinding{
+					Kind: types.DriftMissing, Instruction: "EXPOSE", Name: port, Dockerfile: item.Literal,
+				})
+			}
+		}
+	}
+
+	if dynamic {
+		return findings
+	}
+	for _, port := range image {
+		if documented[port] || ignored(port) {
+			continue
+		}
+		findings = append(findings, types.DriftFinding{
+			Kind: types.DriftUndocumented, Instruction: "EXPOSE", Name: port, Image: port,
+		})
+	}
+	return findings
+}
+
+// expandPort normalizes an EXPOSE argument to the "port/proto" form used in
+// image configs, expanding ranges ("8000-8002/udp") into individual ports.
+func expandPort(spec string) []string {
+	port, proto, _ := strings.Cut(strings.TrimSpace(spec), "/")
+	proto = strings.ToLower(proto)
+	if proto == "" {
+		proto = "tcp"
+	}
+
+	lo, hi, isRange := strings.Cut(port, "-")
+	start, err1 := strconv.Atoi(lo)
+	end, err2 := strconv.Atoi(hi)
+	if !isRange || err1 != nil || err2 != nil || end < start || end-start > maxPortRange {
+		return []string{port + "/" + proto}
+	}
+
+	ports := make([]string, 0, end-start+1)
+	for p := start; p <= end; p++ {
+		ports = append(ports, strconv.Itoa(p)+"/"+proto)
+	}
+	return ports
+}
@@ -0,0 +1,121 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestDetect(t *testing.T) {
+	doc := &parser.Documentation{Items: []parser.DocItem{
+		{Type: "ENV", Name: "APP_HOME", Value: "/opt/app", Key: "APP_HOME", Literal: "/opt/app"},
+		{Type: "ENV", Name: "LOG_LEVEL", Value: "info", Key: "LOG_LEVEL", Literal: "info"},
+		{Type: "ENV", Name: "VERSION", Value: "$APP_VERSION", Key: "VERSION", Literal: "$APP_VERSION"},
+		{Type: "ENV", Name: "REMOVED", Value: "x", Key: "REMOVED", Literal: "x"},
+		{Type: "ARG", Name: "APP_VERSION"},
+		{Type: "EXPOSE", Name: "8080", Value: "8080", Key: "8080", Literal: "8080"},
+		{Type: "EXPOSE", Name: "metrics", Value: "9100/udp", Key: "metrics", Literal: "9100/udp"},
+		{Type: "LABEL", Name: "maintainer", Value: "team@example.com", Key: "maintainer", Literal: "team@example.com"},
+	}}
+	cfg := &types.ImageConfig{
+		Env:          []string{"PATH=/usr/bin", "APP_HOME=/opt/app", "LOG_LEVEL=debug", "VERSION=1.2.3", "LEGACY=1"},
+		ExposedPorts: []string{"8080/tcp", "9090/tcp"},
+		Labels:       map[string]string{"maintainer": "team@example.com", "org.opencontainers.image.source": "x"},
+	}
+
+	got := Detect(doc, cfg, []string{"PATH", "org.opencontainers.*"})
+	want := []types.DriftFinding{
+		{Kind: types.DriftChanged, Instruction: "ENV", Name: "LOG_LEVEL", Dockerfile: "info", Image: "debug"},
+		{Kind: types.DriftMissing, Instruction: "ENV", Name: "REMOVED", Dockerfile: "x"},
+		{Kind: types.DriftUndocumented, Instruction: "ENV", Name: "LEGACY", Image: "1"},
+		{Kind: types.DriftMissing, Instruction: "EXPOSE", Name: "9100/udp", Dockerfile: "9100/udp"},
+		{Kind: types.DriftUndocumented, Instruction: "EXPOSE", Name: "9090/tcp", Image: "9090/tcp"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := Detect(doc, nil, nil); got != nil {
+		t.Errorf("Detect() without image config = %+v, want nil", got)
+	}
+}
+
+// TestDetect_MultiStage tests that only the final stage is compared, using
+// the literal instruction values rather than documented defaults.
+func TestDetect_MultiStage(t *testing.T) {
+	content := `FROM golang:1.22 AS builder
+ENV CGO_ENABLED=0
+EXPOSE 6060
+
+FROM alpine:3.19
+# @default: 8080
+ENV PORT=80
+EXPOSE 80
+`
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	cfg := &types.ImageConfig{
+		Env:          []string{"PORT=80"},
+		ExposedPorts: []string{"80/tcp"},
+	}
+	if got := Detect(doc, cfg, nil); len(got) != 0 {
+		t.Errorf("Detect() = %+v, want no drift", got)
+	}
+}
+
+func TestDetect_DynamicPort(t *testing.T) {
+	doc := &parser.Documentation{Items: []parser.DocItem{
+		{Type: "EXPOSE", Name: "$PORT", Value: "$PORT", Key: "$PORT", Literal: "$PORT"},
+	}}
+	cfg := &types.ImageConfig{ExposedPorts: []string{"3000/tcp"}}
+	if got := Detect(doc, cfg, nil); len(got) != 0 {
+		t.Errorf("expected no port findings when EXPOSE uses a variable, got %+v", got)
+	}
+}
+
+func TestExpandPort(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"8080", []string{"8080/tcp"}},
+		{"53/UDP", []string{"53/udp"}},
+		{"8000-8002", []string{"8000/tcp", "8001/tcp", "8002/tcp"}},
+		{"9000-8000", []string{"9000-8000/tcp"}},
+	}
+	for _, tt := range tests {
+		if got := expandPort(tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandPort(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestFailingAndError(t *testing.T) {
+	findings := []types.DriftFinding{
+		{Kind: types.DriftMissing, Instruction: "ENV", Name: "A"},
+		{Kind: types.DriftUndocumented, Instruction: "EXPOSE", Name: "9090/tcp"},
+	}
+	if got := Failing(findings, nil); got != nil {
+		t.Errorf("Failing(no kinds) = %+v, want nil", got)
+	}
+	failing := Failing(findings, []string{types.DriftMissing})
+	if len(failing) != 1 || failing[0].Name != "A" {
+		t.Fatalf("Failing(missing) = %+v", failing)
+	}
+
+	err := &Error{Image: "app:1", Findings: failing}
+	if msg := err.Error(); !strings.Contains(msg, "app:1") || !strings.Contains(msg, "ENV A is declared in the Dockerfile but not set in the image") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
@@ -0,0 +1,80 @@
+// Package events reports the progress of an analysis (image pulls, runners
+// starting and finishing, cache hits) to whoever displays it, such as a live
+// progress line on a terminal or JSON log lines in CI.
+//
+// Events travel with the context: the caller installs a Handler with
+// WithHandler, and the analysis code calls Emit. Without a handler, Emit does
+// nothing.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Kind identifies what happened.
+type Kind string
+
+// Event kinds.
+const (
+	// AnalysisStarted is emitted when AnalyzeImage starts on an image.
+	// Runners is the number of runners that will run.
+	AnalysisStarted Kind = "analysis_started"
+	// AnalysisFinished is emitted when AnalyzeImage has merged the results
+	// of every runner. Error is set when runners failed.
+	AnalysisFinished Kind = "analysis_finished"
+	// RunnerStarted is emitted when a runner is started on an image.
+	RunnerStarted Kind = "runner_started"
+	// RunnerFinished is emitted when a runner has finished, was skipped or
+	// is unavailable. Status is one of the types.Runner* statuses.
+	RunnerFinished Kind = "runner_finished"
+	// PullStarted is emitted when an image pull starts.
+	PullStarted Kind = "pull_started"
+	// ImagePulled is emitted when an image pull has finished. Error is set
+	// when it failed.
+	ImagePulled Kind = "image_pulled"
+	// CacheHit is emitted when a runner's result is served from the
+	// analysis cache.
+	CacheHit Kind = "cache_hit"
+)
+
+// Event is a single progress event.
+type Event struct {
+	Kind     Kind          `json:"event"`
+	Time     time.Time     `json:"time"`
+	Image    string        `json:"image,omitempty"`
+	Runner   string        `json:"runner,omitempty"`
+	Status   string        `json:"status,omitempty"`
+	Runners  int           `json:"runners,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Handler receives events. It is called synchronously from the goroutine
+// emitting the event and must be safe for concurrent use.
+type Handler func(Event)
+
+// handlerKey is the context key of the event handler.
+type handlerKey struct{}
+
+// WithHandler returns ctx carrying h, which receives every event emitted
+// with the returned context. A nil h returns ctx unchanged.
+func WithHandler(ctx context.Context, h Handler) context.Context {
+	if h == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, handlerKey{}, h)
+}
+
+// Emit sends e to the handler carried by ctx, if any, setting its time when
+// unset.
+func Emit(ctx context.Context, e Event) {
+	h, ok := ctx.Value(handlerKey{}).(Handler)
+	if !ok {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	h(e)
+}
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	// Without a handler, Emit does nothing.
+	Emit(context.Background(), Event{Kind: RunnerStarted})
+
+	var got []Event
+	ctx := WithHandler(context.Background(), func(e Event) { got = append(got, e) })
+	Emit(ctx, Event{Kind: RunnerStarted, Image: "app:1", Runner: "syft"})
+	if len(got) != 1 || got[0].Kind != RunnerStarted || got[0].Runner != "syft" {
+		t.Fatalf("Emit() delivered %+v", got)
+	}
+	if got[0].Time.IsZero() {
+		t.Error("Emit() did not set the event time")
+	}
+
+	if WithHandler(ctx, nil) != ctx {
+		t.Error("WithHandler(nil) should return ctx unchanged")
+	}
+}
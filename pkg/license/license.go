@@ -0,0 +1,88 @@
+// Package license aggregates package licenses into a per-image summary,
+// flags licenses that appear on a configured denylist and looks up license
+// identifiers on the SPDX license list.
+package license
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Summarize counts how many packages declare each license, most common first.
+// Packages that declare no license are counted under "Unknown".
+func Summarize(pkgs []types.PackageSummary) []types.LicenseCount {
+	counts := make(map[string]int)
+	for _, p := range pkgs {
+		if len(p.Licenses) == 0 {
+			counts["Unknown"]++
+			continue
+		}
+		for _, l := range p.Licenses {
+			counts[l]++
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	summary := make([]types.LicenseCount, 0, len(counts))
+	for l, n := range counts {
+		summary = append(summary, types.LicenseCount{License: l, Packages: n})
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Packages != summary[j].Packages {
+			return summary[i].Packages > summary[j].Packages
+		}
+		return summary[i].License < summary[j].License
+	})
+	return summary
+}
+
+// Denied reports whether a license (or any identifier in an SPDX expression
+// such as "MIT OR AGPL-3.0-only") matches one of the denylist patterns.
+// Patterns are case-insensitive path.Match globs, e.g. "AGPL-*" or "GPL-3.0*".
+func Denied(license string, deny []string) bool {
+	for _, id := range identifiers(license) {
+		for _, pattern := range deny {
+			if ok, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(id)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Flag marks every entry of stats.LicenseSummary that matches the denylist
+// and returns the number of flagged licenses.
+func Flag(stats *types.ImageStats, deny []string) int {
+	if stats == nil || len(deny) == 0 {
+		return 0
+	}
+	flagged := 0
+	for i := range stats.LicenseSummary {
+		if Denied(stats.LicenseSummary[i].License, deny) {
+			stats.LicenseSummary[i].Denied = true
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// identifiers splits an SPDX expression into its license identifiers,
+// dropping operators and parentheses. A plain license name is returned as is.
+func identifiers(expr string) []string {
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expr))
+	ids := make([]string, 0, len(fields)+1)
+	ids = append(ids, strings.TrimSpace(expr))
+	for _, f := range fields {
+		switch strings.ToUpper(f) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		ids = append(ids, f)
+	}
+	return ids
+}
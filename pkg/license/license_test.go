@@ -0,0 +1,105 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestSummarize(t *testing.T) {
+	pkgs := []types.PackageSummary{
+		{Name: "musl", Licenses: []string{"MIT"}},
+		{Name: "busybox", Licenses: []string{"GPL-2.0-only"}},
+		{Name: "zlib", Licenses: []string{"Zlib"}},
+		{Name: "left-pad", Licenses: []string{"MIT"}},
+		{Name: "mystery"},
+	}
+	got := Summarize(pkgs)
+	want := []types.LicenseCount{
+		{License: "MIT", Packages: 2},
+		{License: "GPL-2.0-only", Packages: 1},
+		{License: "Unknown", Packages: 1},
+		{License: "Zlib", Packages: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Summarize() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Summarize()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := Summarize(nil); got != nil {
+		t.Errorf("Summarize(nil) = %+v, want nil", got)
+	}
+}
+
+func TestDenied(t *testing.T) {
+	tests := []struct {
+		license string
+		deny    []string
+		want    bool
+	}{
+		{"AGPL-3.0-only", []string{"AGPL-*"}, true},
+		{"agpl-3.0", []string{"AGPL-*"}, true},
+		{"MIT", []string{"AGPL-*"}, false},
+		{"MIT OR AGPL-3.0-or-later", []string{"AGPL-*"}, true},
+		{"(GPL-2.0-only WITH Classpath-exception-2.0)", []string{"GPL-2.0-only"}, true},
+		{"LGPL-2.1", []string{"GPL-*"}, false},
+		{"Commercial License", []string{"Commercial License"}, true},
+		{"MIT", nil, false},
+	}
+	for _, tt := range tests {
+		if got := Denied(tt.license, tt.deny); got != tt.want {
+			t.Errorf("Denied(%q, %v) = %v, want %v", tt.license, tt.deny, got, tt.want)
+		}
+	}
+}
+
+func TestFlag(t *testing.T) {
+	stats := &types.ImageStats{
+		LicenseSummary: []types.LicenseCount{
+			{License: "MIT", Packages: 10},
+			{License: "AGPL-3.0-only", Packages: 1},
+		},
+	}
+	if n := Flag(stats, []string{"AGPL-*"}); n != 1 {
+		t.Fatalf("Flag() = %d, want 1", n)
+	}
+	if stats.LicenseSummary[0].Denied || !stats.LicenseSummary[1].Denied {
+		t.Errorf("unexpected flags: %+v", stats.LicenseSummary)
+	}
+	if n := Flag(nil, []string{"AGPL-*"}); n != 0 {
+		t.Errorf("Flag(nil) = %d, want 0", n)
+	}
+}
+
+func TestSPDXID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"MIT", "MIT", true},
+		{"apache-2.0", "Apache-2.0", true},
+		{"GPL-2.0+", "GPL-2.0+", true},
+		{"MPL-2.0+", "MPL-2.0+", true},
+		{"BSD", "", false},
+		{"GPL", "", false},
+		{"Apache", "", false},
+		{"Classpath-exception-2.0", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := SPDXID(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("SPDXID(%q) = (%q, %v), want (%q, %v)", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if id, ok := SPDXException("llvm-exception"); !ok || id != "LLVM-exception" {
+		t.Errorf("SPDXException(llvm-exception) = (%q, %v), want LLVM-exception", id, ok)
+	}
+	if _, ok := SPDXException("MIT"); ok {
+		t.Error("SPDXException(MIT) should not find a license")
+	}
+}
@@ -0,0 +1,55 @@
+package license
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed spdx_licenses.txt
+var spdxList string
+
+// spdxIDs maps the upper-cased identifiers of the SPDX license list to their
+// canonical form, for licenses and for license exceptions.
+var spdxIDs = sync.OnceValues(func() (licenses, exceptions map[string]string) {
+	licenses, exceptions = make(map[string]string), make(map[string]string)
+	ids := licenses
+	for _, line := range strings.Split(spdxList, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case line == "[exceptions]":
+			ids = exceptions
+			continue
+		}
+		ids[strings.ToUpper(line)] = line
+	}
+	return licenses, exceptions
+})
+
+// SPDXID returns the canonical identifier of a license on the SPDX license
+// list (e.g., "apache-2.0" returns "Apache-2.0"). A trailing "+" (the "or
+// later" operator) is kept. Names that are not on the list, such as "BSD"
+// or "GPL", are reported as not found.
+func SPDXID(name string) (string, bool) {
+	licenses, _ := spdxIDs()
+	if id, ok := licenses[strings.ToUpper(name)]; ok {
+		return id, true
+	}
+	if base, ok := strings.CutSuffix(name, "+"); ok {
+		if id, ok := licenses[strings.ToUpper(base)]; ok {
+			return id + "+", true
+		}
+	}
+	return "", false
+}
+
+// SPDXException returns the canonical identifier of a license exception on
+// the SPDX exception list (e.g., "Classpath-exception-2.0"), which may only
+// follow WITH in a license expression.
+func SPDXException(name string) (string, bool) {
+	_, exceptions := spdxIDs()
+	id, ok := exceptions[strings.ToUpper(name)]
+	return id, ok
+}
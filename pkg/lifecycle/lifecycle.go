@@ -0,0 +1,214 @@
+// Package lifecycle reports whether the distribution and runtimes in an image
+// are still supported, using an offline dataset of release end-of-life dates.
+// The dataset ships with dock-docs and can be extended or updated from a file.
+package lifecycle
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+//go:embed lifecycle.yaml
+var builtinDataset []byte
+
+// DefaultWarnWithin is how long before its end of life a release is
+// reported as ending soon, unless configured otherwise.
+const DefaultWarnWithin = 90 * 24 * time.Hour
+
+// Release is a release cycle of a product and its end-of-life date.
+type Release struct {
+	// Cycle is the version prefix of the cycle (e.g., "3.15" or "18").
+	Cycle string `yaml:"cycle"`
+	// EOL is the end of security support ("YYYY-MM-DD").
+	EOL string `yaml:"eol"`
+}
+
+// Product maps distribution or runtime names to release cycles.
+type Product struct {
+	// Name is the display name (e.g., "Alpine Linux").
+	Name string `yaml:"name"`
+	// Kind is types.LifecycleDistro or types.LifecycleRuntime.
+	Kind string `yaml:"kind"`
+	// Match lists names, compared case-insensitively: distribution names
+	// that start the OS distro string (e.g., "Alpine Linux"), or tech stack
+	// runtime names (e.g., "Node.js").
+	Match []string `yaml:"match"`
+	// Releases lists the release cycles.
+	Releases []Release `yaml:"releases"`
+}
+
+// Validate checks a product for structural errors.
+func (p Product) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("lifecycle product: name is required")
+	}
+	if p.Kind != types.LifecycleDistro && p.Kind != types.LifecycleRuntime {
+		return fmt.Errorf("lifecycle product %q: kind must be %q or %q", p.Name, types.LifecycleDistro, types.LifecycleRuntime)
+	}
+	if len(p.Match) == 0 {
+		return fmt.Errorf("lifecycle product %q: at least one match name is required", p.Name)
+	}
+	for _, r := range p.Releases {
+		if r.Cycle == "" {
+			return fmt.Errorf("lifecycle product %q: release cycle is required", p.Name)
+		}
+		if _, err := time.Parse(time.DateOnly, r.EOL); err != nil {
+			return fmt.Errorf("lifecycle product %q: cycle %s: invalid eol %q (want YYYY-MM-DD)", p.Name, r.Cycle, r.EOL)
+		}
+	}
+	return nil
+}
+
+// release returns the longest release cycle that version belongs to.
+func (p Product) release(version string) (Release, bool) {
+	var best Release
+	found := false
+	for _, r := range p.Releases {
+		if inCycle(version, r.Cycle) && len(r.Cycle) > len(best.Cycle) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// inCycle reports whether version belongs to cycle: "3.18.4" and "3.18"
+// belong to "3.18", "3.180" does not.
+func inCycle(version, cycle string) bool {
+	if !strings.HasPrefix(version, cycle) {
+		return false
+	}
+	rest := version[len(cycle):]
+	return rest == "" || rest[0] < '0' || rest[0] > '9'
+}
+
+// Database is an ordered list of products; for a given name and version, the
+// first product with a matching release cycle wins.
+type Database struct {
+	products []Product
+	// WarnWithin is how long before its end of life a release is reported
+	// as ending soon.
+	WarnWithin time.Duration
+}
+
+// builtinProducts parses the embedded dataset once. The file is fixed at
+// build time and validated by tests, so a parse failure is a programming error.
+var builtinProducts = sync.OnceValue(func() []Product {
+	var products []Product
+	if err := yaml.Unmarshal(builtinDataset, &products); err != nil {
+		panic(fmt.Sprintf("invalid built-in lifecycle dataset: %v", err))
+	}
+	return products
+})
+
+// Default returns the built-in dataset.
+func Default() *Database {
+	return &Database{products: slices.Clone(builtinProducts()), WarnWithin: DefaultWarnWithin}
+}
+
+// New returns the built-in dataset extended with extra products. Extra
+// products are checked first, so they can add or override release cycles.
+func New(extra []Product) (*Database, error) {
+	for _, p := range extra {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Database{
+		products:   append(slices.Clone(extra), builtinProducts()...),
+		WarnWithin: DefaultWarnWithin,
+	}, nil
+}
+
+// Load reads a lifecycle dataset file in the format of the built-in one.
+func Load(path string) ([]Product, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lifecycle dataset: %w", err)
+	}
+	var products []Product
+	if err := yaml.Unmarshal(data, &products); err != nil {
+		return nil, fmt.Errorf("failed to parse lifecycle dataset %s: %w", path, err)
+	}
+	return products, nil
+}
+
+// Products returns the products in evaluation order.
+func (d *Database) Products() []Product {
+	return d.products
+}
+
+// Lookup returns the product and release cycle for a distribution or
+// runtime name and version.
+func (d *Database) Lookup(kind, name, version string) (Product, Release, bool) {
+	version = strings.TrimLeftFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	for _, p := range d.products {
+		if p.Kind != kind || !slices.ContainsFunc(p.Match, func(m string) bool { return strings.EqualFold(m, name) }) {
+			continue
+		}
+		if r, ok := p.release(version); ok {
+			return p, r, true
+		}
+	}
+	return Product{}, Release{}, false
+}
+
+// Check sets stats.Lifecycle to the support status, as of now, of the
+// image's distribution (from OSDistro) and of the runtimes in its tech
+// stack, so the stats must be classified first.
+func (d *Database) Check(stats *types.ImageStats, now time.Time) {
+	if stats == nil {
+		return
+	}
+	stats.Lifecycle = nil
+	add := func(kind, name, version string) {
+		p, r, ok := d.Lookup(kind, name, version)
+		if !ok {
+			return
+		}
+		eol, _ := time.Parse(time.DateOnly, r.EOL)
+		status := types.LifecycleSupported
+		switch {
+		case !now.Before(eol):
+			status = types.LifecycleEOL
+		case eol.Sub(now) <= d.WarnWithin:
+			status = types.LifecycleEOLSoon
+		}
+		stats.Lifecycle = append(stats.Lifecycle, types.LifecycleEntry{
+			Kind: kind, Name: p.Name, Cycle: r.Cycle, Version: version, EOL: eol, Status: status,
+		})
+	}
+
+	if name, version, ok := d.splitDistro(stats.OSDistro); ok {
+		add(types.LifecycleDistro, name, version)
+	}
+	for _, t := range stats.TechStack {
+		if t.Tier == types.TierRuntime {
+			add(types.LifecycleRuntime, t.Name, t.Version)
+		}
+	}
+}
+
+// splitDistro splits an OS distro string such as "Alpine Linux 3.18.4" into
+// the longest known distribution name and the version that follows it.
+func (d *Database) splitDistro(distro string) (name, version string, ok bool) {
+	for _, p := range d.products {
+		if p.Kind != types.LifecycleDistro {
+			continue
+		}
+		for _, m := range p.Match {
+			if len(distro) > len(m) && strings.EqualFold(distro[:len(m)], m) && distro[len(m)] == ' ' && len(m) > len(name) {
+				name, version, ok = m, strings.TrimSpace(distro[len(m):]), true
+			}
+		}
+	}
+	return name, version, ok
+}
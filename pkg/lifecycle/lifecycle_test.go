@@ -0,0 +1,153 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestBuiltinDatasetValid(t *testing.T) {
+	products := Default().Products()
+	if len(products) == 0 {
+		t.Fatal("built-in lifecycle dataset is empty")
+	}
+	for _, p := range products {
+		if err := p.Validate(); err != nil {
+			t.Errorf("built-in product invalid: %v", err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	db := Default()
+	tests := []struct {
+		kind, name, version string
+		wantName, wantCycle string
+		wantOK              bool
+	}{
+		{types.LifecycleDistro, "Alpine Linux", "3.15.4", "Alpine Linux", "3.15", true},
+		{types.LifecycleDistro, "alpine", "3.18", "Alpine Linux", "3.18", true},
+		{types.LifecycleDistro, "Debian GNU/Linux", "12 (bookworm)", "Debian", "12", true},
+		{types.LifecycleDistro, "Ubuntu", "22.04.3 LTS (Jammy Jellyfish)", "Ubuntu", "22.04", true},
+		{types.LifecycleDistro, "Amazon Linux", "2023", "Amazon Linux", "2023", true},
+		{types.LifecycleRuntime, "Go", "go1.21.5", "Go", "1.21", true},
+		{types.LifecycleRuntime, "OpenJDK", "1.8.0_392", "OpenJDK", "1.8", true},
+		{types.LifecycleRuntime, "Node.js", "18.19.0-r0", "Node.js", "18", true},
+		// "3.1" must not match "3.10".
+		{types.LifecycleRuntime, "Ruby", "3.10.0", "", "", false},
+		{types.LifecycleDistro, "Node.js", "18.19.0", "", "", false},
+		{types.LifecycleDistro, "Alpine Linux", "edge", "", "", false},
+	}
+	for _, tt := range tests {
+		p, r, ok := db.Lookup(tt.kind, tt.name, tt.version)
+		if ok != tt.wantOK || p.Name != tt.wantName || r.Cycle != tt.wantCycle {
+			t.Errorf("Lookup(%s, %q, %q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.kind, tt.name, tt.version, p.Name, r.Cycle, ok, tt.wantName, tt.wantCycle, tt.wantOK)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	stats := &types.ImageStats{
+		OSDistro: "Alpine Linux 3.15.4",
+		TechStack: []types.TechStackEntry{
+			{Name: "Node.js", Version: "20.11.1-r0", Tier: types.TierRuntime},
+			{Name: "Python", Version: "3.12.1", Tier: types.TierRuntime},
+			{Name: "Express", Version: "4.18.2", Tier: types.TierFramework},
+		},
+		Lifecycle: []types.LifecycleEntry{{Name: "stale"}},
+	}
+	Default().Check(stats, now)
+
+	want := []types.LifecycleEntry{
+		{Kind: types.LifecycleDistro, Name: "Alpine Linux", Cycle: "3.15", Version: "3.15.4",
+			EOL: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), Status: types.LifecycleEOL},
+		{Kind: types.LifecycleRuntime, Name: "Node.js", Cycle: "20", Version: "20.11.1-r0",
+			EOL: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), Status: types.LifecycleEOLSoon},
+		{Kind: types.LifecycleRuntime, Name: "Python", Cycle: "3.12", Version: "3.12.1",
+			EOL: time.Date(2028, 10, 31, 0, 0, 0, 0, time.UTC), Status: types.LifecycleSupported},
+	}
+	if !reflect.DeepEqual(stats.Lifecycle, want) {
+		t.Errorf("Check() lifecycle =\n%+v\nwant\n%+v", stats.Lifecycle, want)
+	}
+	if got := stats.Lifecycle[0].Message(); got != "Alpine Linux 3.15 — EOL since 2023-11-01" {
+		t.Errorf("Message() = %q", got)
+	}
+
+	db := Default()
+	db.WarnWithin = 0
+	db.Check(stats, now)
+	if stats.Lifecycle[1].Status != types.LifecycleSupported {
+		t.Errorf("status without warning period = %q, want supported", stats.Lifecycle[1].Status)
+	}
+}
+
+func TestNew_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lifecycle.yaml")
+	data := `- name: Alpine Linux
+  kind: distro
+  match: ["Alpine Linux"]
+  releases:
+    - {cycle: "3.15", eol: "2030-01-01"}
+- name: Acme OS
+  kind: distro
+  match: ["Acme OS"]
+  releases:
+    - {cycle: "1", eol: 2025-01-01}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	products, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	db, err := New(products)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		distro  string
+		wantEOL string
+	}{
+		{"Alpine Linux 3.15.4", "2030-01-01"}, // overridden
+		{"Alpine Linux 3.18.4", "2025-05-09"}, // built-in
+		{"Acme OS 1.2", "2025-01-01"},         // added
+	}
+	for _, tt := range tests {
+		stats := &types.ImageStats{OSDistro: tt.distro}
+		db.Check(stats, time.Now())
+		if len(stats.Lifecycle) != 1 || stats.Lifecycle[0].EOL.Format(time.DateOnly) != tt.wantEOL {
+			t.Errorf("Check(%q) = %+v, want EOL %s", tt.distro, stats.Lifecycle, tt.wantEOL)
+		}
+	}
+}
+
+func TestProduct_Validate(t *testing.T) {
+	tests := []struct {
+		product Product
+		wantErr string
+	}{
+		{Product{Kind: "distro", Match: []string{"x"}}, "name is required"},
+		{Product{Name: "x", Kind: "os", Match: []string{"x"}}, "kind must be"},
+		{Product{Name: "x", Kind: "distro"}, "match name is required"},
+		{Product{Name: "x", Kind: "distro", Match: []string{"x"}, Releases: []Release{{EOL: "2024-01-01"}}}, "cycle is required"},
+		{Product{Name: "x", Kind: "distro", Match: []string{"x"}, Releases: []Release{{Cycle: "1", EOL: "2024-01"}}}, "invalid eol"},
+	}
+	for _, tt := range tests {
+		err := tt.product.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%+v) error = %v, want %q", tt.product, err, tt.wantErr)
+		}
+	}
+	if _, err := New([]Product{{Name: "x"}}); err == nil {
+		t.Error("New() expected error for an invalid product")
+	}
+}
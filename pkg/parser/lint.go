@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/linter"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Diagnostic is a Dockerfile lint warning reported by BuildKit's build checks.
+type Diagnostic struct {
+	Rule        string // e.g., "JSONArgsRecommended"
+	Description string // what the rule checks
+	URL         string // rule documentation
+	Message     string
+	StartLine   int
+	EndLine     int
+}
+
+// reservedStageNames are stage names BuildKit reserves for build sources.
+var reservedStageNames = map[string]bool{"context": true, "scratch": true}
+
+// lint runs the BuildKit build checks that can be evaluated from the
+// Dockerfile alone (casing, stage names, deprecated instructions, JSON
+// arguments). Checks that need build context, such as undefined variables,
+// are not run. Rules are configured with the "# check=skip=<rules>" parser
+// directive, as with "docker build --check".
+func lint(data []byte, result *parser.Result) []Diagnostic {
+	directive, _, _, _ := parser.ParseDirective("check", data)
+	cfg, err := linter.ParseLintOptions(directive)
+	if err != nil {
+		slog.Warn("ignoring invalid check directive", "error", err)
+		cfg = &linter.Config{}
+	}
+
+	var diags []Diagnostic
+	cfg.Warn = func(rule, description, url, msg string, location []parser.Range) {
+		d := Diagnostic{Rule: rule, Description: description, URL: url, Message: msg}
+		if len(location) > 0 {
+			d.StartLine = location[0].Start.Line
+			d.EndLine = location[len(location)-1].End.Line
+		}
+		diags = append(diags, d)
+	}
+	l := linter.New(cfg)
+
+	// The parser reports empty continuation lines as plain warnings; BuildKit
+	// converts them into the NoEmptyContinuation check the same way.
+	for _, w := range result.Warnings {
+		if w.URL == linter.RuleNoEmptyContinuation.URL && w.Location != nil {
+			l.Run(&linter.RuleNoEmptyContinuation, []parser.Range{*w.Location}, linter.RuleNoEmptyContinuation.Format())
+		}
+	}
+	stages, _, err := instructions.Parse(result.AST, l)
+	if err != nil {
+		slog.Debug("Dockerfile lint checks incomplete", "error", err)
+	}
+	checkStageNames(stages, l)
+	checkCasing(stages, l)
+	checkJSONArgs(stages, l)
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].StartLine < diags[j].StartLine })
+	return diags
+}
+
+// checkStageNames reports reserved and duplicate stage names.
+func checkStageNames(stages []instructions.Stage, l *linter.Linter) {
+	seen := make(map[string]bool)
+	for _, stage := range stages {
+		if stage.Name == "" {
+			continue
+		}
+		if reservedStageNames[stage.Name] {
+			l.Run(&linter.RuleReservedStageName, stage.Location, linter.RuleReservedStageName.Format(stage.Name))
+		}
+		if seen[stage.Name] {
+			l.Run(&linter.RuleDuplicateStageName, stage.Location, linter.RuleDuplicateStageName.Format(stage.Name))
+		}
+		seen[stage.Name] = true
+	}
+}
+
+// checkCasing reports instructions whose casing differs from the majority.
+func checkCasing(stages []instructions.Stage, l *linter.Linter) {
+	lower, upper := 0, 0
+	count := func(name string) {
+		switch name {
+		case strings.ToLower(name):
+			lower++
+		case strings.ToUpper(name):
+			upper++
+		}
+	}
+	for _, stage := range stages {
+		count(stage.OrigCmd)
+		for _, cmd := range stage.Commands {
+			count(cmd.Name())
+		}
+	}
+
+	check := func(name string, location []parser.Range) {
+		casing := ""
+		if lower > upper && name != strings.ToLower(name) {
+			casing = "lowercase"
+		} else if lower <= upper && name != strings.ToUpper(name) {
+			casing = "uppercase"
+		}
+		if casing != "" {
+			l.Run(&linter.RuleConsistentInstructionCasing, location, linter.RuleConsistentInstructionCasing.Format(name, casing))
+		}
+	}
+	for _, stage := range stages {
+		check(stage.OrigCmd, stage.Location)
+		for _, cmd := range stage.Commands {
+			check(cmd.Name(), cmd.Location())
+		}
+	}
+}
+
+// checkJSONArgs reports shell-form CMD and ENTRYPOINT instructions in stages
+// that do not set a SHELL, since the process then cannot receive signals.
+func checkJSONArgs(stages []instructions.Stage, l *linter.Linter) {
+	for _, stage := range stages {
+		shell := false
+		for _, cmd := range stage.Commands {
+			var prependShell bool
+			switch c := cmd.(type) {
+			case *instructions.ShellCommand:
+				shell = true
+			case *instructions.CmdCommand:
+				prependShell = c.PrependShell
+			case *instructions.EntrypointCommand:
+				prependShell = c.PrependShell
+			}
+			if prependShell && !shell {
+				l.Run(&linter.RuleJSONArgsRecommended, cmd.Location(), linter.RuleJSONArgsRecommended.Format(cmd.Name()))
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse_InstructionsAndDiagnostics(t *testing.T) {
+	content := `# check=skip=MaintainerDeprecated
+from alpine:3.19 as Build
+MAINTAINER someone@example.com
+RUN apk add --no-cache \
+    curl
+
+CMD echo hello
+ENTRYPOINT ["/bin/app"]
+`
+	tmpFile := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	doc, err := Parse(tmpFile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Path != tmpFile {
+		t.Errorf("Path = %q, want %q", doc.Path, tmpFile)
+	}
+
+	if len(doc.Instructions) != 5 {
+		t.Fatalf("expected 5 instructions, got %+v", doc.Instructions)
+	}
+	run := doc.Instructions[2]
+	if run.Command != "RUN" || run.StartLine != 4 || run.EndLine != 5 {
+		t.Errorf("unexpected RUN instruction: %+v", run)
+	}
+	if doc.Instructions[0].Command != "FROM" {
+		t.Errorf("commands should be upper-cased, got %q", doc.Instructions[0].Command)
+	}
+
+	rules := map[string]int{}
+	for _, d := range doc.Diagnostics {
+		rules[d.Rule] = d.StartLine
+		if d.Message == "" || d.URL == "" {
+			t.Errorf("diagnostic missing message or URL: %+v", d)
+		}
+	}
+	for rule, line := range map[string]int{"StageNameCasing": 2, "ConsistentInstructionCasing": 2, "JSONArgsRecommended": 7} {
+		if got, ok := rules[rule]; !ok || got != line {
+			t.Errorf("expected %s at line %d, got %v (diagnostics: %+v)", rule, line, got, doc.Diagnostics)
+		}
+	}
+	if len(doc.Diagnostics) != 3 {
+		t.Errorf("expected 3 diagnostics (MaintainerDeprecated skipped), got %+v", doc.Diagnostics)
+	}
+}
@@ -4,7 +4,9 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -17,11 +19,39 @@ type DocItem struct {
 	Description string // from @description
 	Type        string // "ARG", "ENV", "LABEL", "EXPOSE"
 	Required    bool   // from @required
+
+	Key     string // name as written in the instruction, before @name
+	Literal string // value as written in the instruction, before @default
+	Stage   int    // index of the build stage (0 for the first FROM, -1 before it)
+}
+
+// Instruction is a top-level Dockerfile instruction and its source lines.
+type Instruction struct {
+	Command   string // upper-cased, e.g., "FROM", "RUN"
+	Original  string // full instruction text, continuation lines joined
+	StartLine int
+	EndLine   int
 }
 
 // Documentation holds all extracted documentation items from a Dockerfile.
 type Documentation struct {
 	Items []DocItem
+
+	// Path is the Dockerfile the documentation was parsed from.
+	Path string
+	// Instructions lists every instruction in file order, so findings can be
+	// located at the line that introduced them.
+	Instructions []Instruction
+	// Diagnostics holds the Dockerfile lint warnings.
+	Diagnostics []Diagnostic
+	// BaseImage is the image the final stage is built FROM, with stage
+	// aliases and global ARG defaults resolved (e.g., "alpine:3.19"). It is
+	// empty for images built from scratch or when the reference cannot be
+	// resolved.
+	BaseImage string
+	// FinalStages lists the stages whose instructions reach the built image:
+	// the last stage and the stages it is built FROM, in file order.
+	FinalStages []int
 }
 
 // FilterByType returns items of a specific type (ARG, ENV, LABEL, EXPOSE).
@@ -35,33 +65,72 @@ func (d *Documentation) FilterByType(t string) []DocItem {
 	return filtered
 }
 
+// FinalStageItems returns the items of a specific type declared in the final
+// stage or the stages it is built FROM, i.e. those that end up in the image.
+// If the stages are unknown (FinalStages is empty), every item is returned.
+func (d *Documentation) FinalStageItems(t string) []DocItem {
+	if len(d.FinalStages) == 0 {
+		return d.FilterByType(t)
+	}
+	var filtered []DocItem
+	for _, item := range d.Items {
+		if item.Type == t && slices.Contains(d.FinalStages, item.Stage) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
 // Parse reads a Dockerfile and extracts documentation metadata.
 func Parse(filename string) (*Documentation, error) {
-	f, err := os.Open(filename)
+	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		// Ignore error on close in defer as we are reading only
-		_ = f.Close()
-	}()
 
-	result, err := parser.Parse(f)
+	result, err := parser.Parse(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
 	doc := &Documentation{
-		Items: make([]DocItem, 0),
+		Items:       make([]DocItem, 0),
+		Path:        filename,
+		Diagnostics: lint(data, result),
 	}
 
+	doc.BaseImage = baseImage(result.AST.Children)
+
+	stage := -1
+	var parents []int               // stage each stage is built FROM, or -1
+	aliases := make(map[string]int) // stage index by lower-cased name
 	for _, node := range result.AST.Children {
+		doc.Instructions = append(doc.Instructions, Instruction{
+			Command:   strings.ToUpper(node.Value),
+			Original:  node.Original,
+			StartLine: node.StartLine,
+			EndLine:   node.EndLine,
+		})
+
 		// 1. Parse comments into a list of metadata objects
 		metas := parseComments(node)
 
 		var items []DocItem
 
 		switch strings.ToUpper(node.Value) {
+		case "FROM":
+			stage++
+			parent := -1
+			if node.Next != nil {
+				if i, ok := aliases[strings.ToLower(node.Next.Value)]; ok {
+					parent = i
+				}
+				if as := node.Next.Next; as != nil && strings.EqualFold(as.Value, "AS") && as.Next != nil {
+					aliases[strings.ToLower(as.Next.Value)] = stage
+				}
+			}
+			parents = append(parents, parent)
+			continue
 		case "ARG":
 			items = parseMultiKV(node, "ARG")
 		case "ENV":
@@ -81,6 +150,9 @@ func Parse(filename string) (*Documentation, error) {
 		//   User spec: "associating the single comment to all of them might look weird".
 		//   So yes, map 1:1. Remaining items get no metadata (unless we decide otherwise later).
 		for i := range items {
+			items[i].Key = items[i].Name
+			items[i].Literal = items[i].Value
+			items[i].Stage = stage
 			if i < len(metas) {
 				m := metas[i]
 				if m.Name != "" {
@@ -101,9 +173,65 @@ func Parse(filename string) (*Documentation, error) {
 		}
 	}
 
+	for s := stage; s >= 0; s = parents[s] {
+		doc.FinalStages = append([]int{s}, doc.FinalStages...)
+	}
+
 	return doc, nil
 }
 
+// baseImage returns the image the final stage is built from. Stage aliases
+// ("FROM builder") are followed to the stage's own FROM, and variables are
+// expanded with the defaults of the ARGs declared before the first FROM.
+func baseImage(nodes []*parser.Node) string {
+	args := make(map[string]string)
+	stages := make(map[string]string) // image by lower-cased stage name
+	var base string
+	seenFrom := false
+	for _, node := range nodes {
+		switch strings.ToUpper(node.Value) {
+		case "ARG":
+			if seenFrom {
+				continue
+			}
+			for n := node.Next; n != nil; n = n.Next {
+				name, val, _ := strings.Cut(n.Value, "=")
+				args[name] = stripQuotes(val)
+			}
+		case "FROM":
+			seenFrom = true
+			if node.Next == nil {
+				continue
+			}
+			unresolved := false
+			image := os.Expand(node.Next.Value, func(v string) string {
+				name, def, hasDef := strings.Cut(v, ":-")
+				if val := args[name]; val != "" {
+					return val
+				}
+				if !hasDef {
+					unresolved = true
+				}
+				return def
+			})
+			if unresolved {
+				image = ""
+			}
+			if resolved, ok := stages[strings.ToLower(image)]; ok {
+				image = resolved
+			}
+			if strings.EqualFold(image, "scratch") {
+				image = ""
+			}
+			if as := node.Next.Next; as != nil && strings.EqualFold(as.Value, "AS") && as.Next != nil {
+				stages[strings.ToLower(as.Next.Value)] = image
+			}
+			base = image
+		}
+	}
+	return base
+}
+
 func parseComments(node *parser.Node) []DocItem {
 	if node.PrevComment == nil {
 		return nil
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -628,6 +629,49 @@ EXPOSE 8080
 	}
 }
 
+func TestParse_Stages(t *testing.T) {
+	content := `ARG GO_VERSION=1.22
+FROM golang:${GO_VERSION} AS builder
+ENV CGO_ENABLED=0
+
+FROM alpine:3.19 AS base
+# @default: 8080
+ENV PORT=80
+
+FROM golang:1.22 AS tools
+EXPOSE 6060
+
+FROM base
+# @name: Version
+LABEL version=1.0
+`
+	tmpFile := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	doc, err := Parse(tmpFile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if want := []int{1, 3}; !reflect.DeepEqual(doc.FinalStages, want) {
+		t.Errorf("FinalStages = %v, want %v", doc.FinalStages, want)
+	}
+	if got := doc.Items[0]; got.Type != "ARG" || got.Stage != -1 {
+		t.Errorf("global ARG = %+v, want stage -1", got)
+	}
+	env := doc.FinalStageItems("ENV")
+	if len(env) != 1 || env[0].Key != "PORT" || env[0].Value != "8080" || env[0].Literal != "80" || env[0].Stage != 1 {
+		t.Errorf("final-stage ENV = %+v, want PORT with literal 80", env)
+	}
+	if got := doc.FinalStageItems("EXPOSE"); len(got) != 0 {
+		t.Errorf("final-stage EXPOSE = %+v, want none", got)
+	}
+	if got := doc.FinalStageItems("LABEL"); len(got) != 1 || got[0].Name != "Version" || got[0].Key != "version" {
+		t.Errorf("final-stage LABEL = %+v, want key version documented as Version", got)
+	}
+}
+
 func TestParse_FileNotFound(t *testing.T) {
 	_, err := Parse("/nonexistent/Dockerfile")
 	if err == nil {
@@ -657,3 +701,39 @@ INVALID COMMAND
 	// If it doesn't error, it should return empty or minimal items
 	t.Logf("Parsed %d items from invalid Dockerfile", len(doc.Items))
 }
+
+func TestParse_BaseImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"single stage", "FROM alpine:3.19\nRUN true\n", "alpine:3.19"},
+		{"final stage wins", "FROM golang:1.22 AS build\nFROM gcr.io/distroless/static:nonroot\n", "gcr.io/distroless/static:nonroot"},
+		{"platform flag", "FROM --platform=$BUILDPLATFORM golang:1.22 AS build\nFROM --platform=linux/amd64 debian:12-slim\n", "debian:12-slim"},
+		{"stage alias", "FROM node:20 AS base\nFROM base AS deps\nFROM deps\n", "node:20"},
+		{"stage alias case-insensitive", "FROM node:20 AS Base\nFROM base\n", "node:20"},
+		{"global arg", "ARG VERSION=3.19\nFROM alpine:${VERSION}\n", "alpine:3.19"},
+		{"quoted arg", "ARG IMAGE=\"python:3.12-slim\"\nFROM $IMAGE\n", "python:3.12-slim"},
+		{"arg default", "FROM ${IMAGE:-ubuntu:24.04}\n", "ubuntu:24.04"},
+		{"unresolved arg", "ARG REGISTRY\nFROM ${REGISTRY}/app:1\n", ""},
+		{"stage arg ignored", "FROM alpine:3.19\nARG VERSION=1\nFROM busybox:${VERSION}\n", ""},
+		{"scratch", "FROM golang:1.22 AS build\nFROM scratch\n", ""},
+		{"no FROM", "ARG VERSION=1\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "Dockerfile")
+			if err := os.WriteFile(tmpFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write temp file: %v", err)
+			}
+			doc, err := Parse(tmpFile)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if doc.BaseImage != tt.want {
+				t.Errorf("BaseImage = %q, want %q", doc.BaseImage, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package policy evaluates security gate rules (vulnerability thresholds,
+// efficiency, size, forbidden packages and end-of-life software) against
+// analysis results.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Policy holds the thresholds an image must satisfy. Zero values disable the
+// corresponding rule; vulnerability limits are pointers so that a limit of
+// zero can be told apart from "no limit".
+type Policy struct {
+	// MaxCritical is the maximum number of Critical vulnerabilities allowed.
+	MaxCritical *int
+	// MaxHigh is the maximum number of High vulnerabilities allowed.
+	MaxHigh *int
+	// OnlyFixable counts only vulnerabilities with a known fix towards
+	// MaxCritical and MaxHigh.
+	OnlyFixable bool
+	// MinEfficiency is the minimum Dive efficiency score (0-100).
+	MinEfficiency float64
+	// MaxSizeBytes is the maximum image size in bytes.
+	MaxSizeBytes int64
+	// ForbiddenPackages lists package names (or path.Match globs such as
+	// "log4j-*") that must not be installed in the image.
+	ForbiddenPackages []string
+	// DenyEOL fails images whose distribution or runtimes are past their end
+	// of life.
+	DenyEOL bool
+	// AllowUnscanned passes vulnerability, efficiency and size rules whose
+	// input was not measured (e.g., grype did not run) instead of failing
+	// them.
+	AllowUnscanned bool
+}
+
+// IsZero reports whether the policy has no rules configured.
+func (p Policy) IsZero() bool {
+	return p.MaxCritical == nil && p.MaxHigh == nil && p.MinEfficiency == 0 &&
+		p.MaxSizeBytes == 0 && len(p.ForbiddenPackages) == 0 && !p.DenyEOL
+}
+
+// ViolationError is returned when an image fails its security policy.
+type ViolationError struct {
+	Image  string
+	Report *types.PolicyReport
+}
+
+func (e *ViolationError) Error() string {
+	msgs := make([]string, 0, len(e.Report.Checks))
+	for _, c := range e.Report.Violations() {
+		msgs = append(msgs, c.Message)
+	}
+	return fmt.Sprintf("policy check failed for %s: %s", e.Image, strings.Join(msgs, "; "))
+}
+
+// Evaluate checks stats against the policy and returns the report. Rules whose
+// input was not measured (e.g., efficiency when dive is unavailable) fail, so
+// the gate does not pass an image whose scan did not happen, unless
+// AllowUnscanned is set. The end-of-life rule passes when the lifecycle of
+// the image could not be determined.
+func Evaluate(stats *types.ImageStats, p Policy) *types.PolicyReport {
+	report := &types.PolicyReport{Passed: true}
+	add := func(c types.PolicyCheck) {
+		if !c.Passed {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, c)
+	}
+
+	scanned := measured(stats, "grype", !stats.VulnScanTime.IsZero() || len(stats.Vulnerabilities) > 0 || len(stats.Suppressed) > 0)
+	if p.MaxCritical != nil {
+		add(p.checkVulnCount(stats, "maxCritical", "Critical", *p.MaxCritical, scanned))
+	}
+	if p.MaxHigh != nil {
+		add(p.checkVulnCount(stats, "maxHigh", "High", *p.MaxHigh, scanned))
+	}
+
+	if p.MinEfficiency > 0 {
+		c := types.PolicyCheck{Rule: "minEfficiency", Passed: true, Limit: fmt.Sprintf("%.1f%%", p.MinEfficiency), Actual: "n/a"}
+		if !measured(stats, "dive", stats.Efficiency > 0) {
+			c = p.unmeasured(c, "efficiency not measured")
+		} else {
+			c.Actual = fmt.Sprintf("%.1f%%", stats.Efficiency)
+			if stats.Efficiency < p.MinEfficiency {
+				c.Passed = false
+				c.Message = fmt.Sprintf("efficiency %s is below the minimum of %s", c.Actual, c.Limit)
+			}
+		}
+		add(c)
+	}
+
+	if p.MaxSizeBytes > 0 {
+		c := types.PolicyCheck{Rule: "maxSize", Passed: true, Limit: formatMB(p.MaxSizeBytes), Actual: "n/a"}
+		if stats.SizeBytes == 0 {
+			c = p.unmeasured(c, "image size not measured")
+		} else {
+			c.Actual = formatMB(stats.SizeBytes)
+			if stats.SizeBytes > p.MaxSizeBytes {
+				c.Passed = false
+				c.Message = fmt.Sprintf("image size %s exceeds the maximum of %s", c.Actual, c.Limit)
+			}
+		}
+		add(c)
+	}
+
+	if len(p.ForbiddenPackages) > 0 {
+		found := forbiddenPackages(stats.Packages, p.ForbiddenPackages)
+		c := types.PolicyCheck{
+			Rule:   "forbiddenPackages",
+			Passed: len(found) == 0,
+			Limit:  strings.Join(p.ForbiddenPackages, ", "),
+			Actual: "none",
+		}
+		if len(found) > 0 {
+			c.Actual = strings.Join(found, ", ")
+			c.Message = "forbidden packages installed: " + c.Actual
+		}
+		add(c)
+	}
+
+	if p.DenyEOL {
+		c := types.PolicyCheck{Rule: "denyEOL", Passed: true, Limit: "none", Actual: "n/a"}
+		if len(stats.Lifecycle) == 0 {
+			c.Message = "lifecycle not determined"
+		} else {
+			var eol []string
+			for _, e := range stats.Lifecycle {
+				if e.Status == types.LifecycleEOL {
+					eol = append(eol, e.Name+" "+e.Cycle)
+				}
+			}
+			c.Actual = "none"
+			if len(eol) > 0 {
+				c.Passed = false
+				c.Actual = strings.Join(eol, ", ")
+				c.Message = "end-of-life software: " + c.Actual
+			}
+		}
+		add(c)
+	}
+
+	return report
+}
+
+// measured reports whether runner produced the input of a rule. Results
+// without runner statuses (e.g., saved by older versions) fall back to hasValue,
+// whether the input has been filled in.
+func measured(stats *types.ImageStats, runner string, hasValue bool) bool {
+	if len(stats.Runners) == 0 {
+		return hasValue
+	}
+	return stats.Has(runner)
+}
+
+// unmeasured marks c as a rule whose input was not measured: it fails with
+// msg unless the policy allows unscanned images.
+func (p Policy) unmeasured(c types.PolicyCheck, msg string) types.PolicyCheck {
+	c.Passed = p.AllowUnscanned
+	c.Message = msg
+	return c
+}
+
+func (p Policy) checkVulnCount(stats *types.ImageStats, rule, severity string, limit int, scanned bool) types.PolicyCheck {
+	c := types.PolicyCheck{Rule: rule, Passed: true, Limit: strconv.Itoa(limit), Actual: "n/a"}
+	if !scanned {
+		return p.unmeasured(c, "vulnerabilities not scanned")
+	}
+
+	count := stats.VulnSummary[severity]
+	label := strings.ToLower(severity)
+	if p.OnlyFixable {
+		count = stats.FixableBySeverity(severity)
+		label = "fixable " + label
+	}
+	c.Actual = strconv.Itoa(count)
+	if count > limit {
+		c.Passed = false
+		c.Message = fmt.Sprintf("%d %s vulnerabilities (maximum %d)", count, label, limit)
+	}
+	return c
+}
+
+// forbiddenPackages returns "name@version" for every installed package whose
+// name matches one of the patterns.
+func forbiddenPackages(pkgs []types.PackageSummary, patterns []string) []string {
+	var found []string
+	for _, pkg := range pkgs {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, pkg.Name); ok {
+				found = append(found, pkg.Name+"@"+pkg.Version)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func formatMB(b int64) string {
+	return fmt.Sprintf("%.2f MB", float64(b)/1024/1024)
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func intPtr(n int) *int { return &n }
+
+func testStats() *types.ImageStats {
+	return &types.ImageStats{
+		ImageTag:     "app:latest",
+		SizeBytes:    200 * 1024 * 1024,
+		Efficiency:   85,
+		VulnScanTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		VulnSummary:  map[string]int{"Critical": 2, "High": 3},
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-1", Severity: "Critical", FixState: types.FixStateFixed, FixedInVersions: []string{"1.1"}},
+			{ID: "CVE-2", Severity: "Critical", FixState: types.FixStateNotFixed},
+			{ID: "CVE-3", Severity: "High", FixState: types.FixStateNotFixed},
+			{ID: "CVE-4", Severity: "High", FixState: types.FixStateNotFixed},
+			{ID: "CVE-5", Severity: "High", FixState: types.FixStateNotFixed},
+		},
+		Packages: []types.PackageSummary{
+			{Name: "log4j-core", Version: "2.14.0"},
+			{Name: "openssl", Version: "3.0.1"},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		stats      *types.ImageStats
+		policy     Policy
+		wantPassed bool
+		wantFailed []string
+	}{
+		{
+			name:       "empty policy passes",
+			stats:      testStats(),
+			wantPassed: true,
+		},
+		{
+			name:       "critical limit exceeded",
+			stats:      testStats(),
+			policy:     Policy{MaxCritical: intPtr(0), MaxHigh: intPtr(5)},
+			wantFailed: []string{"maxCritical"},
+		},
+		{
+			name:       "only fixable counts",
+			stats:      testStats(),
+			policy:     Policy{MaxCritical: intPtr(1), MaxHigh: intPtr(0), OnlyFixable: true},
+			wantPassed: true,
+		},
+		{
+			name:       "efficiency and size",
+			stats:      testStats(),
+			policy:     Policy{MinEfficiency: 90, MaxSizeBytes: 100 * 1024 * 1024},
+			wantFailed: []string{"minEfficiency", "maxSize"},
+		},
+		{
+			name:       "forbidden package glob",
+			stats:      testStats(),
+			policy:     Policy{ForbiddenPackages: []string{"log4j-*", "telnet"}},
+			wantFailed: []string{"forbiddenPackages"},
+		},
+		{
+			name: "end-of-life software",
+			stats: &types.ImageStats{Lifecycle: []types.LifecycleEntry{
+				{Name: "Alpine Linux", Cycle: "3.15", Status: types.LifecycleEOL},
+			}},
+			policy:     Policy{DenyEOL: true},
+			wantFailed: []string{"denyEOL"},
+		},
+		{
+			name: "software nearing end of life",
+			stats: &types.ImageStats{Lifecycle: []types.LifecycleEntry{
+				{Name: "Node.js", Cycle: "20", Status: types.LifecycleEOLSoon},
+			}},
+			policy:     Policy{DenyEOL: true},
+			wantPassed: true,
+		},
+		{
+			name:       "unmeasured values fail",
+			stats:      &types.ImageStats{},
+			policy:     Policy{MaxCritical: intPtr(0), MinEfficiency: 90, MaxSizeBytes: 1, DenyEOL: true},
+			wantFailed: []string{"maxCritical", "minEfficiency", "maxSize"},
+		},
+		{
+			name: "runners that did not run fail",
+			stats: func() *types.ImageStats {
+				s := testStats()
+				s.Runners = []types.RunnerStatus{
+					{Name: "docker", Status: types.RunnerOK},
+					{Name: "grype", Status: types.RunnerFailed, Message: "boom"},
+					{Name: "dive", Status: types.RunnerUnavailable},
+				}
+				return s
+			}(),
+			policy:     Policy{MaxCritical: intPtr(5), MaxHigh: intPtr(5), MinEfficiency: 50},
+			wantFailed: []string{"maxCritical", "maxHigh", "minEfficiency"},
+		},
+		{
+			name:       "unmeasured values allowed",
+			stats:      &types.ImageStats{},
+			policy:     Policy{MaxCritical: intPtr(0), MinEfficiency: 90, MaxSizeBytes: 1, DenyEOL: true, AllowUnscanned: true},
+			wantPassed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Evaluate(tt.stats, tt.policy)
+			if report.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (checks: %+v)", report.Passed, tt.wantPassed, report.Checks)
+			}
+			var failed []string
+			for _, c := range report.Violations() {
+				failed = append(failed, c.Rule)
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("failed rules = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestEvaluate_CheckDetails(t *testing.T) {
+	report := Evaluate(testStats(), Policy{
+		MaxHigh:           intPtr(1),
+		ForbiddenPackages: []string{"log4j-core"},
+	})
+	if len(report.Checks) != 2 {
+		t.Fatalf("expected 2 checks, got %+v", report.Checks)
+	}
+	high := report.Checks[0]
+	if high.Limit != "1" || high.Actual != "3" || high.Message != "3 high vulnerabilities (maximum 1)" {
+		t.Errorf("unexpected maxHigh check: %+v", high)
+	}
+	if pkgs := report.Checks[1]; pkgs.Actual != "log4j-core@2.14.0" {
+		t.Errorf("unexpected forbiddenPackages check: %+v", pkgs)
+	}
+
+	stats := testStats()
+	stats.Lifecycle = []types.LifecycleEntry{
+		{Name: "Alpine Linux", Cycle: "3.15", Status: types.LifecycleEOL},
+		{Name: "Python", Cycle: "3.12", Status: types.LifecycleSupported},
+		{Name: "Node.js", Cycle: "16", Status: types.LifecycleEOL},
+	}
+	eol := Evaluate(stats, Policy{DenyEOL: true}).Checks[0]
+	if eol.Actual != "Alpine Linux 3.15, Node.js 16" || eol.Message != "end-of-life software: Alpine Linux 3.15, Node.js 16" {
+		t.Errorf("unexpected denyEOL check: %+v", eol)
+	}
+}
+
+func TestPolicy_IsZero(t *testing.T) {
+	if !(Policy{OnlyFixable: true}).IsZero() {
+		t.Error("policy with only OnlyFixable should be zero")
+	}
+	if (Policy{MaxCritical: intPtr(0)}).IsZero() {
+		t.Error("policy with MaxCritical=0 should not be zero")
+	}
+	if (Policy{DenyEOL: true}).IsZero() {
+		t.Error("policy with DenyEOL should not be zero")
+	}
+}
+
+func TestViolationError(t *testing.T) {
+	report := Evaluate(testStats(), Policy{MaxCritical: intPtr(0), MinEfficiency: 90})
+	err := &ViolationError{Image: "app:latest", Report: report}
+	want := "policy check failed for app:latest: 2 critical vulnerabilities (maximum 0); efficiency 85.0% is below the minimum of 90.0%"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
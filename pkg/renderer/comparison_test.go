@@ -3,6 +3,7 @@ package renderer
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
 )
@@ -94,3 +95,26 @@ func TestRenderComparison(t *testing.T) {
 		t.Error("expected output to contain v2 package curl")
 	}
 }
+
+func TestRenderComparison_Lifecycle(t *testing.T) {
+	stats := []*types.ImageStats{
+		{ImageTag: "app:v1", Lifecycle: []types.LifecycleEntry{
+			{Name: "Alpine Linux", Cycle: "3.15", EOL: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), Status: types.LifecycleEOL},
+		}},
+		{ImageTag: "app:v2", Lifecycle: []types.LifecycleEntry{
+			{Name: "Alpine Linux", Cycle: "3.22", EOL: time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC), Status: types.LifecycleSupported},
+		}},
+	}
+	for _, name := range []string{"default", "detailed", "html"} {
+		output, err := RenderComparisonWithTemplate(stats, RenderOptions{NoMoji: true}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderComparisonWithTemplate(%s) error = %v", name, err)
+		}
+		if got := strings.Count(output, "Alpine Linux 3.15 — EOL since 2023-11-01"); got != 1 {
+			t.Errorf("%s: end-of-life warning rendered %d times, want 1:\n%s", name, got, output)
+		}
+		if strings.Contains(output, "Alpine Linux 3.22 —") {
+			t.Errorf("%s: supported release should not be reported", name)
+		}
+	}
+}
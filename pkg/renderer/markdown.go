@@ -7,6 +7,8 @@ import (
 	"text/template"
 
 	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/sarif"
+	"github.com/northcutted/dock-docs/pkg/sbom"
 	"github.com/northcutted/dock-docs/pkg/templates"
 	"github.com/northcutted/dock-docs/pkg/types"
 )
@@ -20,14 +22,14 @@ type RenderOptions struct {
 // TemplateSelection specifies which template to use.
 // If both Name and Path are empty, the "default" built-in is used.
 type TemplateSelection struct {
-	// Name is a built-in template name (e.g., "default", "minimal", "detailed", "compact", "html", "json").
+	// Name is a built-in template name (e.g., "default", "minimal", "detailed", "compact", "html", "json", "cyclonedx", "spdx", "sarif").
 	Name string
 	// Path is the file path to a custom template (overrides Name).
 	Path string
 }
 
 // Format returns the output format for this template selection.
-// Returns "markdown", "html", or "json". Custom file templates default to "markdown".
+// Returns "markdown", "html", "json", "cyclonedx", "spdx" or "sarif". Custom file templates default to "markdown".
 func (s TemplateSelection) Format() string {
 	if s.Path != "" {
 		return "markdown" // custom templates default to markdown
@@ -70,7 +72,13 @@ func Render(doc *parser.Documentation, stats *types.ImageStats, opts RenderOptio
 }
 
 // RenderWithTemplate generates documentation using the specified template.
+// The SBOM and SARIF formats are produced by their encoders; the SBOM
+// formats require stats, while SARIF can report Dockerfile lint alone.
 func RenderWithTemplate(doc *parser.Documentation, stats *types.ImageStats, opts RenderOptions, sel TemplateSelection) (string, error) {
+	if sel.Path == "" && templates.IsEncodedFormat(sel.Format()) {
+		return encode(doc, stats, sel.Format())
+	}
+
 	tmpl, err := resolveTemplate(sel, templates.TemplateTypeImage, opts.NoMoji)
 	if err != nil {
 		return "", fmt.Errorf("failed to load template: %w", err)
@@ -101,6 +109,10 @@ func RenderComparison(stats []*types.ImageStats, opts RenderOptions) (string, er
 
 // RenderComparisonWithTemplate generates the comparison table using the specified template.
 func RenderComparisonWithTemplate(stats []*types.ImageStats, opts RenderOptions, sel TemplateSelection) (string, error) {
+	if sel.Path == "" && templates.IsEncodedFormat(sel.Format()) {
+		return "", fmt.Errorf("template %q does not support comparison sections; use it with a single image", sel.Name)
+	}
+
 	tmpl, err := resolveTemplate(sel, templates.TemplateTypeComparison, opts.NoMoji)
 	if err != nil {
 		return "", fmt.Errorf("failed to load template: %w", err)
@@ -115,6 +127,29 @@ func RenderComparisonWithTemplate(stats []*types.ImageStats, opts RenderOptions,
 	return templates.ExecuteWithLimits(tmpl, ctx, sec)
 }
 
+// encode renders doc and stats as an SBOM or SARIF document.
+func encode(doc *parser.Documentation, stats *types.ImageStats, format string) (string, error) {
+	if stats == nil && format != templates.FormatSARIF {
+		return "", fmt.Errorf("%s output requires image analysis; set an image tag", format)
+	}
+	var data []byte
+	var err error
+	switch format {
+	case templates.FormatSARIF:
+		data, err = sarif.Encode(doc, stats)
+	case templates.FormatCycloneDX:
+		data, err = sbom.CycloneDX(stats)
+	case templates.FormatSPDX:
+		data, err = sbom.SPDX(stats)
+	default:
+		return "", fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // resolveTemplate loads the appropriate template based on the selection.
 func resolveTemplate(sel TemplateSelection, tmplType templates.TemplateType, noMoji bool) (*template.Template, error) {
 	loader := templates.NewLoader(noMoji)
@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -173,6 +174,8 @@ func TestTemplateSelection_Format(t *testing.T) {
 		{"empty name defaults to markdown", TemplateSelection{}, "markdown"},
 		{"html name", TemplateSelection{Name: "html"}, "html"},
 		{"json name", TemplateSelection{Name: "json"}, "json"},
+		{"cyclonedx name", TemplateSelection{Name: "cyclonedx"}, "cyclonedx"},
+		{"spdx name", TemplateSelection{Name: "spdx"}, "spdx"},
 		{"minimal name", TemplateSelection{Name: "minimal"}, "markdown"},
 		{"compact name", TemplateSelection{Name: "compact"}, "markdown"},
 		{"detailed name", TemplateSelection{Name: "detailed"}, "markdown"},
@@ -264,3 +267,859 @@ func TestRender_EmptyDocument(t *testing.T) {
 		t.Error("should not contain Environment Variables section when empty")
 	}
 }
+
+func TestRender_RichVulnerabilityDetails(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:    "test:latest",
+		VulnSummary: map[string]int{"Critical": 1, "High": 1},
+		Vulnerabilities: []types.Vulnerability{
+			{
+				ID: "CVE-2024-0001", Severity: "Critical", Package: "openssl", Version: "3.0.1",
+				FixState: types.FixStateFixed, FixedInVersions: []string{"3.0.2"},
+				CVSSScore: 9.8, CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+				DataSource:  "https://security.alpinelinux.org/vuln/CVE-2024-0001",
+				Description: `Buffer overflow in "parser"`,
+			},
+			{ID: "GHSA-aaaa-bbbb-cccc", Severity: "High", Package: "lodash", Version: "4.17.0", FixState: types.FixStateNotFixed},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"[CVE-2024-0001](https://security.alpinelinux.org/vuln/CVE-2024-0001)",
+		"[GHSA-aaaa-bbbb-cccc](https://github.com/advisories/GHSA-aaaa-bbbb-cccc)",
+		"| 9.8 |",
+		"`3.0.2`",
+		"**Fixable now:** 1 of 2",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(jsonOut)) {
+		t.Fatalf("json template produced invalid JSON:\n%s", jsonOut)
+	}
+	if !strings.Contains(jsonOut, `"fixable": 1`) || !strings.Contains(jsonOut, `"cvss_score": 9.8`) {
+		t.Errorf("expected fixable count and CVSS score in JSON output, got:\n%s", jsonOut)
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_AcceptedRisks(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:    "test:latest",
+		VulnSummary: map[string]int{"High": 0},
+		Suppressed: []types.SuppressedVulnerability{
+			{
+				Vulnerability: types.Vulnerability{ID: "CVE-2024-0002", Severity: "High", Package: "busybox", Version: "1.36.1"},
+				Justification: `not reachable from "main"`,
+				Expires:       time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+				Source:        "dock-docs.yaml",
+			},
+			{
+				Vulnerability: types.Vulnerability{ID: "CVE-2024-0003", Severity: "Low", Package: "zlib", Version: "1.3"},
+				Justification: "vulnerable code not present",
+			},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"Accepted Risks (2 suppressed)",
+		"| `busybox` | `1.36.1` | not reachable from \"main\" | 2030-01-31 |",
+		"| vulnerable code not present | never |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	for _, name := range []string{"json", "html"} {
+		out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, "CVE-2024-0002") {
+			t.Errorf("%s output missing suppressed finding:\n%s", name, out)
+		}
+		if name == "json" && !json.Valid([]byte(out)) {
+			t.Fatalf("json template produced invalid JSON:\n%s", out)
+		}
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) || !strings.Contains(cmpOut, `"expires": "never"`) {
+		t.Errorf("unexpected json comparison output:\n%s", cmpOut)
+	}
+}
+
+func TestRender_PolicyReport(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:    "test:latest",
+		VulnSummary: map[string]int{"Critical": 2},
+		Policy: &types.PolicyReport{
+			Passed: false,
+			Checks: []types.PolicyCheck{
+				{Rule: "maxCritical", Limit: "0", Actual: "2", Message: "2 critical vulnerabilities (maximum 0)"},
+				{Rule: "minEfficiency", Passed: true, Limit: "90.0%", Actual: "95.0%"},
+			},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{NoMoji: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"### Security Policy: [NO] FAIL",
+		"| `maxCritical` | 0 | 2 | [NO] 2 critical vulnerabilities (maximum 0) |",
+		"| `minEfficiency` | 90.0% | 95.0% | [YES] pass |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	for _, name := range []string{"json", "html", "detailed"} {
+		out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, "maxCritical") {
+			t.Errorf("%s output missing policy report:\n%s", name, out)
+		}
+		if name == "json" && !json.Valid([]byte(out)) {
+			t.Fatalf("json template produced invalid JSON:\n%s", out)
+		}
+	}
+
+	stats.Policy = nil
+	out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(out)) || !strings.Contains(out, `"policy": null`) {
+		t.Errorf("expected null policy in JSON output:\n%s", out)
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_Licenses(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:      "test:latest",
+		TotalPackages: 2,
+		Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4", Type: "apk", Licenses: []string{"MIT"}, PURL: "pkg:apk/alpine/musl@1.2.4"},
+			{Name: "ghostscript", Version: "10.0", Type: "apk", Licenses: []string{"AGPL-3.0-or-later"}},
+		},
+		LicenseSummary: []types.LicenseCount{
+			{License: "AGPL-3.0-or-later", Packages: 1, Denied: true},
+			{License: "MIT", Packages: 1},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{NoMoji: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"Licenses (2 distinct)",
+		"**Denied licenses:** `AGPL-3.0-or-later` (1 packages)",
+		"| [WARN] **AGPL-3.0-or-later** (denied) | 1 |",
+		"| MIT | 1 |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	detailed, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "detailed"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(detailed) error = %v", err)
+	}
+	if !strings.Contains(detailed, "| musl | 1.2.4 | apk | - | MIT |") {
+		t.Errorf("expected package type and license columns, got:\n%s", detailed)
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Packages []struct {
+				PURL     string   `json:"purl"`
+				Licenses []string `json:"licenses"`
+			} `json:"packages"`
+			Licenses []struct {
+				License string `json:"license"`
+				Denied  bool   `json:"denied"`
+			} `json:"licenses"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if len(parsed.Analysis.Licenses) != 2 || !parsed.Analysis.Licenses[0].Denied {
+		t.Errorf("unexpected license summary in JSON: %+v", parsed.Analysis.Licenses)
+	}
+	if len(parsed.Analysis.Packages) != 2 || parsed.Analysis.Packages[0].PURL != "pkg:apk/alpine/musl@1.2.4" {
+		t.Errorf("unexpected packages in JSON: %+v", parsed.Analysis.Packages)
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_TechStack(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:      "test:latest",
+		TotalPackages: 2,
+		Packages: []types.PackageSummary{
+			{Name: "openjdk17-jre", Version: "17.0.9", Type: "apk", Tier: types.TierRuntime, Framework: "OpenJDK"},
+			{Name: "musl", Version: "1.2.4", Type: "apk", Tier: types.TierOS},
+		},
+		TechStack: []types.TechStackEntry{
+			{Name: "OpenJDK", Version: "17.0.9", Tier: types.TierRuntime},
+			{Name: "Spring Boot", Version: "3.2.0", Tier: types.TierFramework},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{NoMoji: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(output, "**Tech Stack:** `OpenJDK 17.0.9`, `Spring Boot 3.2.0`") {
+		t.Errorf("expected tech stack summary, got:\n%s", output)
+	}
+
+	detailed, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "detailed"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(detailed) error = %v", err)
+	}
+	for _, want := range []string{
+		"### Tech Stack",
+		"| Spring Boot | 3.2.0 | framework |",
+		"| musl | 1.2.4 | apk | os | - |",
+	} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("expected detailed output to contain %q, got:\n%s", want, detailed)
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Packages []struct {
+				Tier      string `json:"tier"`
+				Framework string `json:"framework"`
+			} `json:"packages"`
+			TechStack []struct {
+				Name string `json:"name"`
+				Tier string `json:"tier"`
+			} `json:"tech_stack"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if len(parsed.Analysis.TechStack) != 2 || parsed.Analysis.TechStack[1].Tier != types.TierFramework {
+		t.Errorf("unexpected tech stack in JSON: %+v", parsed.Analysis.TechStack)
+	}
+	if parsed.Analysis.Packages[0].Framework != "OpenJDK" {
+		t.Errorf("unexpected package framework in JSON: %+v", parsed.Analysis.Packages)
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_Platforms(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:               "test:latest",
+		SupportedArchitectures: []string{"linux/amd64", "linux/arm64"},
+		Platforms: map[string]*types.ImageStats{
+			"linux/arm64": {SizeBytes: 20 * 1024 * 1024, TotalLayers: 4, TotalPackages: 12, VulnSummary: map[string]int{"High": 2}},
+			"linux/amd64": {SizeBytes: 10 * 1024 * 1024, TotalLayers: 3, TotalPackages: 10, VulnSummary: map[string]int{"Critical": 1}},
+		},
+	}
+
+	for _, name := range []string{"default", "detailed"} {
+		output, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		amd := strings.Index(output, "| `linux/amd64` | 10.00 MB | 3 | 10 | 1 | 0 | 0 | 0 |")
+		arm := strings.Index(output, "| `linux/arm64` | 20.00 MB | 4 | 12 | 0 | 2 | 0 | 0 |")
+		if amd < 0 || arm < 0 || amd > arm {
+			t.Errorf("%s: expected sorted platform matrix, got:\n%s", name, output)
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Platforms []struct {
+				Platform        string         `json:"platform"`
+				SizeBytes       int64          `json:"size_bytes"`
+				Vulnerabilities map[string]int `json:"vulnerabilities"`
+			} `json:"platforms"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if len(parsed.Analysis.Platforms) != 2 || parsed.Analysis.Platforms[1].Vulnerabilities["high"] != 2 {
+		t.Errorf("unexpected platforms in JSON: %+v", parsed.Analysis.Platforms)
+	}
+
+	for name, want := range map[string]string{
+		"default":  "| `linux/arm64` | 20.00 MB |",
+		"detailed": "| `linux/arm64` | 20.00 MB |",
+		"html":     "<td><code>linux/arm64</code></td>",
+		"json":     `"platform": "linux/arm64"`,
+	} {
+		out, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderComparisonWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, want) {
+			t.Errorf("%s comparison: expected %q, got:\n%s", name, want, out)
+		}
+		if name == "json" && !json.Valid([]byte(out)) {
+			t.Errorf("json comparison template produced invalid JSON:\n%s", out)
+		}
+	}
+}
+
+func TestRender_RunnerStatus(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:   "test:latest",
+		Efficiency: 0,
+		Runners: []types.RunnerStatus{
+			{Name: "syft", Status: types.RunnerOK},
+			{Name: "dive", Status: types.RunnerUnavailable, Message: "not installed"},
+		},
+	}
+
+	for name, want := range map[string]string{
+		"default":  "**Efficiency Score:** n/a — dive unavailable",
+		"detailed": "| **Wasted Space** | n/a — dive unavailable |",
+		"html":     "n/a — dive unavailable",
+	} {
+		out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, want) {
+			t.Errorf("%s: expected %q, got:\n%s", name, want, out)
+		}
+		if name != "html" && !strings.Contains(out, "**Incomplete analysis:** `dive` unavailable (not installed)") {
+			t.Errorf("%s: expected incomplete analysis note, got:\n%s", name, out)
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Efficiency *float64             `json:"efficiency"`
+			Runners    []types.RunnerStatus `json:"runners"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if parsed.Analysis.Efficiency != nil {
+		t.Errorf("efficiency = %v, want null", *parsed.Analysis.Efficiency)
+	}
+	if len(parsed.Analysis.Runners) != 2 || parsed.Analysis.Runners[1].Status != types.RunnerUnavailable {
+		t.Errorf("unexpected runners in JSON: %+v", parsed.Analysis.Runners)
+	}
+
+	out, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "default"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate error = %v", err)
+	}
+	if !strings.Contains(out, "| n/a — dive unavailable |") {
+		t.Errorf("comparison: expected n/a efficiency, got:\n%s", out)
+	}
+}
+
+func TestRender_VulnsNotScanned(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag: "test:latest",
+		Runners: []types.RunnerStatus{
+			{Name: "syft", Status: types.RunnerOK},
+			{Name: "grype", Status: types.RunnerFailed, Message: "command failed: exit status 1\nStderr: | `db` load failed\nretry later"},
+		},
+	}
+
+	for name, want := range map[string]string{
+		"default":  "| n/a — grype failed | n/a — grype failed | n/a — grype failed | n/a — grype failed |",
+		"detailed": "| n/a — grype failed | n/a — grype failed | n/a — grype failed | n/a — grype failed | n/a — grype failed |",
+		"compact":  "Vulns: n/a — grype failed",
+		"html":     "Vulns n/a — grype failed",
+	} {
+		out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, want) {
+			t.Errorf("%s: expected %q, got:\n%s", name, want, out)
+		}
+		if strings.Contains(out, "Stderr") {
+			t.Errorf("%s: tool stderr should not be rendered, got:\n%s", name, out)
+		}
+		if name == "default" || name == "detailed" {
+			if !strings.Contains(out, "**Incomplete analysis:** `grype` failed (command failed: exit status 1)\n") {
+				t.Errorf("%s: expected a one-line incomplete analysis note, got:\n%s", name, out)
+			}
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Security struct {
+				Summary map[string]*int `json:"summary"`
+			} `json:"security"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	for _, field := range []string{"critical", "high", "medium", "low", "total", "fixable"} {
+		if v, ok := parsed.Analysis.Security.Summary[field]; !ok || v != nil {
+			t.Errorf("analysis.security.summary.%s = %v, want null", field, v)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"compact": "| n/a — grype failed |",
+		"minimal": "| n/a — grype failed |",
+		"default": "| n/a — grype failed | n/a — grype failed | n/a — grype failed | n/a — grype failed |",
+		"html":    "<td>n/a — grype failed</td>",
+	} {
+		out, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderComparisonWithTemplate(%s) error = %v", name, err)
+		}
+		if !strings.Contains(out, want) {
+			t.Errorf("%s comparison: expected %q, got:\n%s", name, want, out)
+		}
+	}
+}
+
+func TestRender_ImageConfig(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag:   "nginx:1.25",
+		RepoDigest: "nginx@sha256:abc123",
+		ImageID:    "sha256:0123456789abcdef",
+		Created:    time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC),
+		Config: &types.ImageConfig{
+			Entrypoint:   []string{"/docker-entrypoint.sh"},
+			Cmd:          []string{"nginx", "-g", "daemon off;"},
+			Env:          []string{"NGINX_VERSION=1.25.4"},
+			ExposedPorts: []string{"80/tcp"},
+			Labels:       map[string]string{"maintainer": "NGINX \"Docker\""},
+			Healthcheck:  &types.Healthcheck{Test: []string{"CMD-SHELL", "curl -f http://localhost/"}, Interval: 30 * time.Second},
+		},
+	}
+
+	output, err := Render(doc, stats, RenderOptions{NoMoji: true})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"**Digest:** `nginx@sha256:abc123`",
+		"| **User** | `root` |",
+		"| **Cmd** | `nginx -g daemon off;` |",
+		"| **Healthcheck** | `curl -f http://localhost/` (every 30s) |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	detailed, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "detailed"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(detailed) error = %v", err)
+	}
+	for _, want := range []string{
+		"| **Image ID** | `0123456789ab` |",
+		"| **Created** | 2024-03-01T10:20:30Z |",
+		"| `NGINX_VERSION` | `1.25.4` |",
+	} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("expected detailed output to contain %q, got:\n%s", want, detailed)
+		}
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			RepoDigest string `json:"repo_digest"`
+			Created    string `json:"created"`
+			Config     struct {
+				Cmd         []string          `json:"cmd"`
+				Labels      map[string]string `json:"labels"`
+				Healthcheck struct {
+					Command string `json:"command"`
+				} `json:"healthcheck"`
+			} `json:"config"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	a := parsed.Analysis
+	if a.RepoDigest != "nginx@sha256:abc123" || a.Created != "2024-03-01T10:20:30Z" || len(a.Config.Cmd) != 3 {
+		t.Errorf("unexpected JSON analysis: %+v", a)
+	}
+	if a.Config.Labels["maintainer"] != `NGINX "Docker"` || a.Config.Healthcheck.Command != "curl -f http://localhost/" {
+		t.Errorf("unexpected JSON config: %+v", a.Config)
+	}
+
+	// Images without inspect data render "config": null.
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats, {ImageTag: "bare:latest"}}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_Drift(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag: "app:latest",
+		Drift: []types.DriftFinding{
+			{Kind: types.DriftChanged, Instruction: "ENV", Name: "LOG_LEVEL", Dockerfile: "info", Image: "debug"},
+			{Kind: types.DriftUndocumented, Instruction: "EXPOSE", Name: "9090/tcp", Image: "9090/tcp"},
+		},
+	}
+
+	for _, name := range []string{"default", "detailed"} {
+		output, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		for _, want := range []string{
+			"### Dockerfile Drift (2 findings)",
+			`| changed | ENV LOG_LEVEL is "info" in the Dockerfile but "debug" in the image |`,
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: expected output to contain %q, got:\n%s", name, want, output)
+			}
+		}
+	}
+
+	htmlOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "html"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(html) error = %v", err)
+	}
+	if !strings.Contains(htmlOut, "ENV LOG_LEVEL is &#34;info&#34; in the Dockerfile") {
+		t.Errorf("expected escaped drift message in HTML, got:\n%s", htmlOut)
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Drift []struct {
+				Kind    string `json:"kind"`
+				Message string `json:"message"`
+			} `json:"drift"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if len(parsed.Analysis.Drift) != 2 || parsed.Analysis.Drift[1].Kind != types.DriftUndocumented {
+		t.Errorf("unexpected drift in JSON: %+v", parsed.Analysis.Drift)
+	}
+
+	cmpOut, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderComparisonWithTemplate(json) error = %v", err)
+	}
+	if !json.Valid([]byte(cmpOut)) {
+		t.Fatalf("json comparison template produced invalid JSON:\n%s", cmpOut)
+	}
+}
+
+func TestRender_BaseImage(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag: "app:latest",
+		Base: &types.BaseImageReport{
+			Image:      "alpine:3.19",
+			RepoDigest: "alpine@sha256:abc",
+			Created:    time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Stale:      true,
+			Inherited:  types.ContentSummary{SizeBytes: 8 << 20, Layers: 1, Packages: 15, VulnSummary: map[string]int{"High": 2}},
+			Introduced: types.ContentSummary{SizeBytes: 12 << 20, Layers: 3, Packages: 1, VulnSummary: map[string]int{"Critical": 1}},
+			IntroducedPackages: []types.PackageSummary{
+				{Name: "curl", Version: "8.5.0-r0", Type: "apk"},
+			},
+			Recommendations: []string{`Rebuild on the current "alpine:3.19"`},
+		},
+	}
+
+	for _, name := range []string{"default", "detailed"} {
+		output, err := RenderWithTemplate(doc, stats, RenderOptions{NoMoji: true}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		for _, want := range []string{
+			"### Base Image: `alpine:3.19` [WARN] stale",
+			"**Digest:** `alpine@sha256:abc` (built 2026-01-02)",
+			"| **Inherited** from base | 8.00 MB | 1 | 15 | 0 | 2 | 0 | 0 |",
+			"| **Introduced** by this image | 12.00 MB | 3 | 1 | 1 | 0 | 0 | 0 |",
+			"**Recommendations:**\n\n- Rebuild on the current \"alpine:3.19\"",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: expected output to contain %q, got:\n%s", name, want, output)
+			}
+		}
+		if got := strings.Contains(output, "| curl | 8.5.0-r0 | apk |"); got != (name == "detailed") {
+			t.Errorf("%s: introduced packages listed = %v", name, got)
+		}
+	}
+
+	htmlOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "html"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(html) error = %v", err)
+	}
+	if !strings.Contains(htmlOut, "<li>Rebuild on the current &#34;alpine:3.19&#34;</li>") {
+		t.Errorf("expected escaped recommendation in HTML, got:\n%s", htmlOut)
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Base *struct {
+				Image      string `json:"image"`
+				Stale      bool   `json:"stale"`
+				Introduced struct {
+					SizeBytes       int64          `json:"size_bytes"`
+					Vulnerabilities map[string]int `json:"vulnerabilities"`
+				} `json:"introduced"`
+				Recommendations []string `json:"recommendations"`
+			} `json:"base"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	base := parsed.Analysis.Base
+	if base == nil || !base.Stale || base.Introduced.SizeBytes != 12<<20 || base.Introduced.Vulnerabilities["critical"] != 1 || len(base.Recommendations) != 1 {
+		t.Errorf("unexpected base image in JSON: %+v", base)
+	}
+
+	stats.Base = nil
+	jsonOut, err = RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	if !strings.Contains(jsonOut, `"base": null`) {
+		t.Errorf("expected null base without comparison, got:\n%s", jsonOut)
+	}
+}
+
+func TestRender_SBOMFormats(t *testing.T) {
+	stats := &types.ImageStats{
+		ImageTag: "test:latest",
+		Packages: []types.PackageSummary{{Name: "musl", Version: "1.2.4", Licenses: []string{"MIT"}}},
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-2024-0001", Severity: "High", Package: "musl", Version: "1.2.4"},
+		},
+	}
+
+	for name, want := range map[string]string{"cyclonedx": `"specVersion": "1.5"`, "spdx": `"spdxVersion": "SPDX-2.3"`} {
+		out, err := RenderWithTemplate(nil, stats, RenderOptions{}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		if !json.Valid([]byte(out)) || !strings.Contains(out, want) || !strings.Contains(out, "CVE-2024-0001") {
+			t.Errorf("unexpected %s output:\n%s", name, out)
+		}
+
+		if _, err := RenderWithTemplate(&parser.Documentation{}, nil, RenderOptions{}, TemplateSelection{Name: name}); err == nil {
+			t.Errorf("RenderWithTemplate(%s) without stats: expected error", name)
+		}
+		if _, err := RenderComparisonWithTemplate([]*types.ImageStats{stats}, RenderOptions{}, TemplateSelection{Name: name}); err == nil {
+			t.Errorf("RenderComparisonWithTemplate(%s): expected error", name)
+		}
+	}
+}
+
+func TestRender_SARIF(t *testing.T) {
+	doc := &parser.Documentation{
+		Path:         "Dockerfile",
+		Instructions: []parser.Instruction{{Command: "FROM", Original: "FROM alpine", StartLine: 1, EndLine: 1}},
+		Diagnostics:  []parser.Diagnostic{{Rule: "MaintainerDeprecated", Message: "deprecated", StartLine: 2, EndLine: 2}},
+	}
+	stats := &types.ImageStats{
+		ImageTag:        "test:latest",
+		Vulnerabilities: []types.Vulnerability{{ID: "CVE-2024-0001", Severity: "High", Package: "musl", Version: "1.2.4"}},
+	}
+
+	out, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "sarif"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(sarif) error = %v", err)
+	}
+	for _, want := range []string{`"version": "2.1.0"`, `"ruleId": "CVE-2024-0001"`, `"ruleId": "lint/MaintainerDeprecated"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("sarif output missing %s:\n%s", want, out)
+		}
+	}
+
+	// Lint findings are reported even without image analysis.
+	out, err = RenderWithTemplate(doc, nil, RenderOptions{}, TemplateSelection{Name: "sarif"})
+	if err != nil || !strings.Contains(out, "lint/MaintainerDeprecated") {
+		t.Errorf("RenderWithTemplate(sarif, no stats) = %q, %v", out, err)
+	}
+}
+
+func TestRender_Lifecycle(t *testing.T) {
+	doc := &parser.Documentation{}
+	stats := &types.ImageStats{
+		ImageTag: "app:latest",
+		OSDistro: "Alpine Linux 3.15.4",
+		Lifecycle: []types.LifecycleEntry{
+			{Kind: types.LifecycleDistro, Name: "Alpine Linux", Cycle: "3.15", Version: "3.15.4",
+				EOL: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), Status: types.LifecycleEOL},
+			{Kind: types.LifecycleRuntime, Name: "Node.js", Cycle: "20", Version: "20.11.1",
+				EOL: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), Status: types.LifecycleEOLSoon},
+			{Kind: types.LifecycleRuntime, Name: "Python", Cycle: "3.12", Version: "3.12.1",
+				EOL: time.Date(2028, 10, 31, 0, 0, 0, 0, time.UTC), Status: types.LifecycleSupported},
+		},
+	}
+
+	for _, name := range []string{"default", "detailed"} {
+		output, err := RenderWithTemplate(doc, stats, RenderOptions{NoMoji: true}, TemplateSelection{Name: name})
+		if err != nil {
+			t.Fatalf("RenderWithTemplate(%s) error = %v", name, err)
+		}
+		for _, want := range []string{
+			"> [WARN] **End of life:** Alpine Linux 3.15 — EOL since 2023-11-01; Node.js 20 — EOL on 2026-04-30",
+			"![Lifecycle](",
+			"message=Alpine+Linux+3.15+EOL&color=red",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: expected output to contain %q, got:\n%s", name, want, output)
+			}
+		}
+		if strings.Contains(output, "Python 3.12 —") {
+			t.Errorf("%s: supported runtimes should not be reported, got:\n%s", name, output)
+		}
+	}
+
+	htmlOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "html"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(html) error = %v", err)
+	}
+	if !strings.Contains(htmlOut, `<span class="badge badge-red">Alpine Linux 3.15 — EOL since 2023-11-01</span>`) {
+		t.Errorf("expected end-of-life badge in HTML, got:\n%s", htmlOut)
+	}
+
+	jsonOut, err := RenderWithTemplate(doc, stats, RenderOptions{}, TemplateSelection{Name: "json"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(json) error = %v", err)
+	}
+	var parsed struct {
+		Analysis struct {
+			Lifecycle []struct {
+				Name    string `json:"name"`
+				EOL     string `json:"eol"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"lifecycle"`
+		} `json:"analysis"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &parsed); err != nil {
+		t.Fatalf("json template produced invalid JSON: %v\n%s", err, jsonOut)
+	}
+	lc := parsed.Analysis.Lifecycle
+	if len(lc) != 3 || lc[0].EOL != "2023-11-01" || lc[0].Status != "eol" || lc[1].Message != "Node.js 20 — EOL on 2026-04-30" {
+		t.Errorf("unexpected lifecycle in JSON: %+v", lc)
+	}
+
+	stats.Lifecycle = nil
+	output, err := RenderWithTemplate(doc, stats, RenderOptions{NoMoji: true}, TemplateSelection{Name: "default"})
+	if err != nil {
+		t.Fatalf("RenderWithTemplate(default) error = %v", err)
+	}
+	if strings.Contains(output, "Lifecycle") || strings.Contains(output, "End of life") {
+		t.Errorf("expected no lifecycle output without data, got:\n%s", output)
+	}
+}
@@ -0,0 +1,82 @@
+// Package results saves analysis results to a JSON file and loads them back,
+// so the slow image analysis and the documentation rendering can run in
+// separate jobs (e.g., a privileged CI job with a container runtime and an
+// unprivileged docs job).
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// FormatVersion is the version of the results file format. Files with a
+// different version are rejected rather than misread.
+const FormatVersion = 1
+
+// File is a set of analysis results keyed by image reference, as written in
+// the config (e.g., "nginx:1.25").
+type File struct {
+	Version     int                          `json:"version"`
+	GeneratedAt time.Time                    `json:"generatedAt"`
+	Images      map[string]*types.ImageStats `json:"images"`
+}
+
+// New returns an empty results file.
+func New() *File {
+	return &File{
+		Version:     FormatVersion,
+		GeneratedAt: time.Now().UTC(),
+		Images:      make(map[string]*types.ImageStats),
+	}
+}
+
+// Add records the results for image, replacing any previous entry.
+func (f *File) Add(image string, stats *types.ImageStats) {
+	f.Images[image] = stats
+}
+
+// Lookup returns a copy of the results for image. Callers may modify the
+// copy (e.g., apply suppressions) without affecting other sections that
+// render the same image.
+func (f *File) Lookup(image string) (*types.ImageStats, error) {
+	stats, ok := f.Images[image]
+	if !ok || stats == nil {
+		return nil, fmt.Errorf("no saved results for image %s", image)
+	}
+	return stats.Clone()
+}
+
+// Load reads a results file written by Write.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse results file %s: %w", path, err)
+	}
+	if f.Version != FormatVersion {
+		return nil, fmt.Errorf("results file %s has unsupported version %d (want %d)", path, f.Version, FormatVersion)
+	}
+	if f.Images == nil {
+		f.Images = make(map[string]*types.ImageStats)
+	}
+	return &f, nil
+}
+
+// Write saves the results as indented JSON.
+func (f *File) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write results file %s: %w", path, err)
+	}
+	return nil
+}
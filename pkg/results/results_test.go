@@ -0,0 +1,75 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestWriteLoadRoundTrip(t *testing.T) {
+	f := New()
+	f.Add("app:1", &types.ImageStats{
+		ImageTag:        "app:1",
+		TotalPackages:   2,
+		Vulnerabilities: []types.Vulnerability{{ID: "CVE-1", Severity: "High"}},
+		Platforms:       map[string]*types.ImageStats{"linux/arm64": {ImageTag: "app@sha256:abc"}},
+	})
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := f.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	stats, err := loaded.Lookup("app:1")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if stats.TotalPackages != 2 || len(stats.Vulnerabilities) != 1 || stats.Platforms["linux/arm64"] == nil {
+		t.Errorf("Lookup() = %+v, want the saved stats", stats)
+	}
+
+	// Lookup returns independent copies.
+	stats.Vulnerabilities = nil
+	again, _ := loaded.Lookup("app:1")
+	if len(again.Vulnerabilities) != 1 {
+		t.Error("modifying a looked-up copy should not change the saved results")
+	}
+
+	if _, err := loaded.Lookup("other:1"); err == nil || !strings.Contains(err.Error(), "no saved results") {
+		t.Errorf("Lookup(missing) error = %v", err)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"invalid json", "{", "failed to parse"},
+		{"wrong version", `{"version": 99, "images": {}}`, "unsupported version 99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Load(missing) expected error")
+	}
+}
@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// dockerHubAuthKey is the key docker uses for Docker Hub credentials in
+// config.json.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// Credentials are the registry credentials used to pull images. They are
+// handed to every tool as a docker config file: docker, nerdctl, syft, grype
+// and dive read DOCKER_CONFIG, while podman, skopeo and buildah read the same
+// file through REGISTRY_AUTH_FILE.
+type Credentials struct {
+	// DockerConfig is the path of a docker config.json, or of the directory
+	// containing it.
+	DockerConfig string
+	// Registry is the registry host that Username and Password log in to
+	// (default "docker.io").
+	Registry string
+	// Username and Password log in to Registry. They take precedence over
+	// the credentials of DockerConfig for that registry.
+	Username string
+	Password string
+}
+
+var (
+	authMu sync.RWMutex
+	// authVars are the KEY=VALUE pairs that point tools at the credentials.
+	authVars []string
+)
+
+// SetCredentials applies c to every command run from now on. When a
+// temporary config file has to be written (for a username and password, or a
+// config file not named config.json), the returned function removes it.
+func SetCredentials(c Credentials) (func(), error) {
+	vars, cleanup, err := c.resolve()
+	if err != nil {
+		return nil, err
+	}
+	authMu.Lock()
+	defer authMu.Unlock()
+	authVars = vars
+	return cleanup, nil
+}
+
+// resolve returns the environment variables that point at the credentials.
+func (c Credentials) resolve() ([]string, func(), error) {
+	noop := func() {}
+	if c.DockerConfig == "" && c.Username == "" && c.Password == "" {
+		return nil, noop, nil
+	}
+	if (c.Username == "") != (c.Password == "") {
+		return nil, nil, errors.New("registry username and password must be set together")
+	}
+
+	path := c.DockerConfig
+	if path != "" {
+		if info, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("docker config: %w", err)
+		} else if info.IsDir() {
+			path = filepath.Join(path, "config.json")
+		}
+		if c.Username == "" && filepath.Base(path) == "config.json" {
+			return authFileVars(path), noop, nil
+		}
+	}
+
+	config, err := loadDockerConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Username != "" {
+		addAuth(config, c.Registry, c.Username, c.Password)
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode docker config: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "dock-docs-auth-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create docker config directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path = filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write docker config: %w", err)
+	}
+	return authFileVars(path), cleanup, nil
+}
+
+// authFileVars points docker-style and containers-style tools at the
+// config.json at path.
+func authFileVars(path string) []string {
+	return []string{"DOCKER_CONFIG=" + filepath.Dir(path), "REGISTRY_AUTH_FILE=" + path}
+}
+
+// loadDockerConfig reads a docker config.json as a generic object, so fields
+// other than the credentials are preserved. An empty path yields an empty
+// config.
+func loadDockerConfig(path string) (map[string]any, error) {
+	config := make(map[string]any)
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path from user config
+	if err != nil {
+		return nil, fmt.Errorf("docker config: %w", err)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// addAuth stores username and password for registry in config. Credential
+// stores would take precedence over the stored entry, so the global store
+// and the registry's helper are removed.
+func addAuth(config map[string]any, registry, username, password string) {
+	key := registry
+	if key == "" || key == "docker.io" || key == "index.docker.io" {
+		key = dockerHubAuthKey
+	}
+	auths, _ := config["auths"].(map[string]any)
+	if auths == nil {
+		auths = make(map[string]any)
+		config["auths"] = auths
+	}
+	auths[key] = map[string]any{"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password))}
+
+	delete(config, "credsStore")
+	if helpers, ok := config["credHelpers"].(map[string]any); ok {
+		delete(helpers, registry)
+		delete(helpers, key)
+	}
+}
@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Supported container runtime backends.
+const (
+	BackendDocker  = "docker"
+	BackendPodman  = "podman"
+	BackendNerdctl = "nerdctl"
+	BackendSkopeo  = "skopeo"
+	// BackendBuildah uses skopeo for inspection and buildah for pulls; both
+	// share the containers-storage image store.
+	BackendBuildah = "buildah"
+)
+
+// RuntimeEnv is the environment variable that selects the container runtime
+// backend, overriding the config file.
+const RuntimeEnv = "DOCK_DOCS_RUNTIME"
+
+// Backends lists the backend names accepted by NewBackend, in the order they
+// are tried when no backend is selected.
+var Backends = []string{BackendDocker, BackendPodman, BackendNerdctl, BackendSkopeo, BackendBuildah}
+
+// Backend is a container runtime or registry client used to inspect, pull
+// and list images. Every backend reports the same information for an image,
+// whichever tool produces it:
+//
+//   - Inspect returns the local image when present, otherwise the registry
+//     image (for backends that can read registries directly).
+//   - Exists reports whether the image is in the local image store.
+//   - Pull copies the image, for the requested platform when one is given,
+//     into the local image store.
+//   - Manifest returns the raw registry manifest (list) of the image.
+type Backend interface {
+	// Name identifies the backend (e.g., "docker").
+	Name() string
+	// Available reports whether the backend's tools are installed.
+	Available() bool
+	// Inspect returns the metadata and configuration of an image.
+	Inspect(ctx context.Context, image string, verbose bool) (*types.ImageStats, error)
+	// ImageID returns the image ID (the digest of the image config).
+	ImageID(ctx context.Context, image string, verbose bool) (string, error)
+	// Exists reports whether the image is available locally.
+	Exists(ctx context.Context, image string, verbose bool) bool
+	// Pull fetches the image into the local image store. A non-empty
+	// platform ("os/arch[/variant]") selects an image from a manifest list.
+	Pull(ctx context.Context, image, platform string, verbose bool) error
+	// Manifest returns the registry manifest or manifest list of the image.
+	Manifest(ctx context.Context, image string, verbose bool) ([]byte, error)
+	// ScanSource returns the image source that a scanner (syft, grype or
+	// dive) must use to read images from the backend's local store, e.g.
+	// "podman". An empty source leaves the choice to the scanner. It fails
+	// when the scanner cannot read that store.
+	ScanSource(tool string) (string, error)
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend
+)
+
+// NewBackend returns the backend with the given name.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case BackendDocker, BackendPodman, BackendNerdctl:
+		return &cliBackend{name: name, binary: name}, nil
+	case BackendSkopeo:
+		return &skopeoBackend{binary: BackendSkopeo}, nil
+	case BackendBuildah:
+		return &skopeoBackend{binary: BackendSkopeo, builder: BackendBuildah}, nil
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q (want one of %s)", name, strings.Join(Backends, ", "))
+	}
+}
+
+// SetBackend selects the backend used by the runtime and manifest runners,
+// EnsureImage, ImageID and ListPlatforms. A nil backend restores automatic
+// detection.
+func SetBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+// CurrentBackend returns the selected backend, or the first available one
+// when none is selected. It fails when the selected backend is not installed.
+func CurrentBackend() (Backend, error) {
+	backendMu.RLock()
+	b := backend
+	backendMu.RUnlock()
+	if b != nil {
+		if !b.Available() {
+			return nil, toolMissing(b.Name())
+		}
+		return b, nil
+	}
+	for _, name := range Backends {
+		b, _ := NewBackend(name)
+		if b.Available() {
+			return b, nil
+		}
+	}
+	return nil, &Error{Kind: ErrToolMissing, Tool: "container runtime", Err: fmt.Errorf("no container runtime found (%s)", strings.Join(Backends, ", "))}
+}
+
+// cliBackend drives a docker-compatible command line (docker, podman and
+// nerdctl share the commands used here).
+type cliBackend struct {
+	name   string
+	binary string
+}
+
+// Name returns the runtime name.
+func (b *cliBackend) Name() string { return b.name }
+
+// Available checks whether the runtime binary is installed.
+func (b *cliBackend) Available() bool {
+	_, err := exec.LookPath(b.binary)
+	return err == nil
+}
+
+// Inspect runs '<runtime> image inspect' and parses the result.
+func (b *cliBackend) Inspect(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	output, err := runCommand(commandContext(runCtx, b.binary, "image", "inspect", image), verbose)
+	if err != nil {
+		return nil, err
+	}
+	return parseRuntimeInspect(output, image, b.name)
+}
+
+// ImageID runs '<runtime> image inspect --format {{.Id}}'.
+func (b *cliBackend) ImageID(ctx context.Context, image string, verbose bool) (string, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	cmd := commandContext(runCtx, b.binary, "image", "inspect", "--format", "{{.Id}}", image)
+	output, err := runCommand(cmd, verbose)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Exists reports whether '<runtime> image inspect' finds the image.
+func (b *cliBackend) Exists(ctx context.Context, image string, verbose bool) bool {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	return commandContext(runCtx, b.binary, "image", "inspect", image).Run() == nil
+}
+
+// Pull runs '<runtime> pull [--platform <platform>]'.
+func (b *cliBackend) Pull(ctx context.Context, image, platform string, verbose bool) error {
+	runCtx, cancel := commandTimeout(ctx, TimeoutScan)
+	defer cancel()
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	_, err := runCommand(commandContext(runCtx, b.binary, append(args, image)...), verbose)
+	return err
+}
+
+// ScanSource returns the runtime's own image store: the default (the docker
+// daemon) for docker, "podman" for podman and "containerd" for nerdctl,
+// which dive cannot read.
+func (b *cliBackend) ScanSource(tool string) (string, error) {
+	switch b.name {
+	case BackendPodman:
+		return "podman", nil
+	case BackendNerdctl:
+		if tool == "dive" {
+			return "", unsupportedSource(tool, b.name)
+		}
+		return "containerd", nil
+	}
+	return "", nil
+}
+
+// unsupportedSource reports that tool cannot read the images of a backend.
+func unsupportedSource(tool, backend string) error {
+	return &Error{Kind: ErrToolMissing, Tool: tool, Err: fmt.Errorf(
+		"%s cannot read images stored by the %s runtime; select the docker or podman runtime, or disable the %s runner", tool, backend, tool)}
+}
+
+// Manifest runs '<runtime> manifest inspect'.
+func (b *cliBackend) Manifest(ctx context.Context, image string, verbose bool) ([]byte, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	cmd := commandContext(runCtx, b.binary, "manifest", "inspect", image)
+	// Older docker releases require experimental CLI features for manifests.
+	cmd.Env = append(cmd.Environ(), "DOCKER_CLI_EXPERIMENTAL=enabled")
+	return runCommand(cmd, verbose)
+}
@@ -0,0 +1,272 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Connection selects the daemon that the container runtime and the analysis
+// tools (syft, grype, dive and plugins) talk to. At most one of Context, Host
+// and PodmanConnection may be set.
+type Connection struct {
+	// Context is a docker context name. Its endpoint and TLS material are
+	// resolved with 'docker context inspect'.
+	Context string
+	// Host is the daemon address (e.g., "tcp://build:2376" or
+	// "unix:///run/podman/podman.sock").
+	Host string
+	// TLSVerify enables TLS verification of the daemon.
+	TLSVerify bool
+	// CertPath is a directory containing ca.pem, cert.pem and key.pem.
+	CertPath string
+	// PodmanConnection is a podman system connection name (see
+	// 'podman system connection list').
+	PodmanConnection string
+}
+
+var (
+	connectionMu sync.RWMutex
+	// connectionVars are the KEY=VALUE pairs added to the environment of
+	// every command.
+	connectionVars []string
+)
+
+// SetConnection resolves c and applies it to every command run from now on.
+// A zero Connection keeps the inherited environment, except with the podman
+// backend: when DOCKER_HOST is unset, the socket of the default podman
+// connection is exported so tools speaking the Docker API reach podman too.
+func SetConnection(ctx context.Context, c Connection) error {
+	vars, err := c.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	connectionMu.Lock()
+	defer connectionMu.Unlock()
+	connectionVars = vars
+	return nil
+}
+
+// resolve returns the environment variables that select the daemon of c.
+func (c Connection) resolve(ctx context.Context) ([]string, error) {
+	var vars []string
+	switch {
+	case c.Context != "":
+		v, err := dockerContextEnv(ctx, c.Context)
+		if err != nil {
+			return nil, err
+		}
+		vars = v
+	case c.PodmanConnection != "":
+		host, err := podmanSocket(ctx, c.PodmanConnection)
+		if err != nil {
+			return nil, err
+		}
+		vars = []string{"CONTAINER_CONNECTION=" + c.PodmanConnection, "DOCKER_HOST=" + host}
+	case c.Host != "":
+		vars = []string{"DOCKER_HOST=" + c.Host, "CONTAINER_HOST=" + c.Host}
+	default:
+		if b, err := CurrentBackend(); err == nil && b.Name() == BackendPodman && os.Getenv("DOCKER_HOST") == "" {
+			// Best effort: without a podman socket the tools fall back to
+			// their own defaults.
+			if host, err := podmanSocket(ctx, ""); err == nil {
+				vars = []string{"DOCKER_HOST=" + host}
+			}
+		}
+	}
+	if c.TLSVerify {
+		vars = append(vars, "DOCKER_TLS_VERIFY=1")
+	}
+	if c.CertPath != "" {
+		vars = append(vars, "DOCKER_CERT_PATH="+c.CertPath)
+	}
+	return vars, nil
+}
+
+// commandContext is exec.CommandContext with the environment of the
+// configured connection and credentials, so every tool targets the same
+// daemon and registries.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	connectionMu.RLock()
+	vars := append([]string(nil), connectionVars...)
+	connectionMu.RUnlock()
+	authMu.RLock()
+	vars = append(vars, authVars...)
+	authMu.RUnlock()
+	if len(vars) > 0 {
+		cmd.Env = append(os.Environ(), vars...)
+	}
+	return cmd
+}
+
+// dockerContextEnv returns DOCKER_HOST and, when the context stores TLS
+// material, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY for a docker context.
+// Exporting the endpoint (rather than DOCKER_CONTEXT) lets tools that do not
+// understand contexts reach the same daemon.
+func dockerContextEnv(ctx context.Context, name string) ([]string, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	output, err := runCommand(exec.CommandContext(runCtx, "docker", "context", "inspect", name), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect docker context %s: %w", name, err)
+	}
+	return parseDockerContext(output, name)
+}
+
+// parseDockerContext parses 'docker context inspect' output into
+// environment variables.
+func parseDockerContext(output []byte, name string) ([]string, error) {
+	var contexts []struct {
+		Endpoints struct {
+			Docker struct {
+				Host          string `json:"Host"`
+				SkipTLSVerify bool   `json:"SkipTLSVerify"`
+			} `json:"docker"`
+		} `json:"Endpoints"`
+		TLSMaterial struct {
+			Docker []string `json:"docker"`
+		} `json:"TLSMaterial"`
+		Storage struct {
+			TLSPath string `json:"TLSPath"`
+		} `json:"Storage"`
+	}
+	if err := json.Unmarshal(output, &contexts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal docker context %s: %w", name, err)
+	}
+	if len(contexts) == 0 || contexts[0].Endpoints.Docker.Host == "" {
+		return nil, fmt.Errorf("docker context %s has no docker endpoint", name)
+	}
+
+	c := contexts[0]
+	vars := []string{"DOCKER_HOST=" + c.Endpoints.Docker.Host}
+	if len(c.TLSMaterial.Docker) > 0 && c.Storage.TLSPath != "" {
+		vars = append(vars, "DOCKER_CERT_PATH="+filepath.Join(c.Storage.TLSPath, "docker"))
+		if !c.Endpoints.Docker.SkipTLSVerify {
+			vars = append(vars, "DOCKER_TLS_VERIFY=1")
+		}
+	}
+	return vars, nil
+}
+
+// podmanConnection is an entry of 'podman system connection list'.
+type podmanConnection struct {
+	Name    string `json:"Name"`
+	URI     string `json:"URI"`
+	Default bool   `json:"Default"`
+}
+
+// podmanSocket returns a DOCKER_HOST value for the podman connection with the
+// given name, or the default connection when name is empty. Connections to a
+// podman machine use the machine's forwarded local socket, which tools
+// without SSH support can reach; on hosts without connections the local
+// podman service socket is used.
+func podmanSocket(ctx context.Context, name string) (string, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+
+	output, err := runCommand(exec.CommandContext(runCtx, "podman", "system", "connection", "list", "--format", "json"), false)
+	if err != nil {
+		return "", fmt.Errorf("failed to list podman connections: %w", err)
+	}
+	conn, err := findPodmanConnection(output, name)
+	if err != nil {
+		return "", err
+	}
+
+	if conn == nil {
+		// No connections: podman runs locally.
+		output, err := runCommand(exec.CommandContext(runCtx, "podman", "info", "--format", "json"), false)
+		if err != nil {
+			return "", fmt.Errorf("failed to read podman info: %w", err)
+		}
+		return parsePodmanInfoSocket(output)
+	}
+
+	if strings.HasPrefix(conn.URI, "unix://") {
+		return conn.URI, nil
+	}
+	// Machine connections are named after the machine, with a "-root"
+	// suffix for the rootful connection.
+	machine := strings.TrimSuffix(conn.Name, "-root")
+	if output, err := runCommand(exec.CommandContext(runCtx, "podman", "machine", "inspect", machine), false); err == nil {
+		if socket := parseMachineSocket(output); socket != "" {
+			return socket, nil
+		}
+	}
+	return conn.URI, nil
+}
+
+// findPodmanConnection returns the named (or default) connection from
+// 'podman system connection list' output. It returns nil without an error
+// when there are no connections and no name was requested.
+func findPodmanConnection(output []byte, name string) (*podmanConnection, error) {
+	var conns []podmanConnection
+	if err := json.Unmarshal(output, &conns); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal podman connections: %w", err)
+	}
+	for i, c := range conns {
+		if (name != "" && c.Name == name) || (name == "" && c.Default) {
+			return &conns[i], nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("podman connection %s not found", name)
+	}
+	if len(conns) > 0 {
+		return &conns[0], nil
+	}
+	return nil, nil
+}
+
+// parseMachineSocket returns the host socket of the first machine in
+// 'podman machine inspect' output as a unix:// address, or "".
+func parseMachineSocket(output []byte) string {
+	var machines []struct {
+		ConnectionInfo struct {
+			PodmanSocket *struct {
+				Path string `json:"Path"`
+			} `json:"PodmanSocket"`
+		} `json:"ConnectionInfo"`
+	}
+	if json.Unmarshal(output, &machines) != nil || len(machines) == 0 {
+		return ""
+	}
+	socket := machines[0].ConnectionInfo.PodmanSocket
+	if socket == nil || socket.Path == "" {
+		return ""
+	}
+	return unixAddress(socket.Path)
+}
+
+// parsePodmanInfoSocket returns the API socket reported by 'podman info'.
+func parsePodmanInfoSocket(output []byte) (string, error) {
+	var info struct {
+		Host struct {
+			RemoteSocket struct {
+				Path   string `json:"path"`
+				Exists bool   `json:"exists"`
+			} `json:"remoteSocket"`
+		} `json:"host"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return "", fmt.Errorf("failed to unmarshal podman info: %w", err)
+	}
+	if !info.Host.RemoteSocket.Exists || info.Host.RemoteSocket.Path == "" {
+		return "", fmt.Errorf("podman API socket is not active (start it with 'podman system service')")
+	}
+	return unixAddress(info.Host.RemoteSocket.Path), nil
+}
+
+// unixAddress prefixes a socket path with unix:// unless it has a scheme.
+func unixAddress(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	return "unix://" + path
+}
@@ -6,14 +6,19 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
-	"strings"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
 // DiveRunner runs 'dive <image> --json output.json'
 type DiveRunner struct {
+	// Timeout overrides the timeout of each command (default: TimeoutScan
+	// for the scan, TimeoutInspect for version queries).
+	Timeout time.Duration
+	// Args are extra command-line arguments appended to the dive command.
+	Args []string
+
 	binary string
 }
 
@@ -29,40 +34,24 @@ func (r *DiveRunner) IsAvailable() bool {
 	return false
 }
 
-// detectPodmanSocket attempts to detect the Podman machine socket path.
-// It returns a DOCKER_HOST value (e.g. "unix:///path/to/socket") if found,
-// or an empty string if detection fails.
-func detectPodmanSocket(ctx context.Context) string {
-	socketCtx, cancel := context.WithTimeout(ctx, TimeoutInspect)
-	defer cancel()
-	pCmd := exec.CommandContext(socketCtx, "podman", "machine", "inspect")
-	out, err := pCmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	var machines []struct {
-		ConnectionInfo struct {
-			PodmanSocket *struct {
-				Path string `json:"Path"`
-			} `json:"PodmanSocket"`
-		} `json:"ConnectionInfo"`
+// CacheKey identifies the dive build, so cached results are invalidated
+// when dive is upgraded.
+func (r *DiveRunner) CacheKey(ctx context.Context) (string, error) {
+	if r.binary == "" && !r.IsAvailable() {
+		return "", toolMissing("dive")
 	}
-	if json.Unmarshal(out, &machines) != nil || len(machines) == 0 {
-		return ""
-	}
-
-	if machines[0].ConnectionInfo.PodmanSocket == nil || machines[0].ConnectionInfo.PodmanSocket.Path == "" {
-		return ""
-	}
-
-	socketPath := machines[0].ConnectionInfo.PodmanSocket.Path
-	if !strings.HasPrefix(socketPath, "unix://") {
-		socketPath = "unix://" + socketPath
+	ctx = withCommandTimeout(ctx, r.Timeout)
+	version, err := toolVersion(ctx, r.binary, "--version")
+	if err != nil {
+		return "", err
 	}
-	return socketPath
+	return version, nil
 }
 
+// ArgsKey identifies the extra arguments passed to dive, which may change
+// its output.
+func (r *DiveRunner) ArgsKey() string { return argsKey(r.Args) }
+
 // Run executes dive against the given image and parses the efficiency results.
 // The provided context is used as the parent for the command timeout.
 func (r *DiveRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
@@ -80,26 +69,23 @@ func (r *DiveRunner) Run(ctx context.Context, image string, verbose bool) (*type
 
 	if r.binary == "" {
 		if !r.IsAvailable() {
-			return nil, fmt.Errorf("dive not found")
+			return nil, toolMissing("dive")
 		}
 	}
 
-	runCtx, cancel := context.WithTimeout(ctx, TimeoutScan)
-	defer cancel()
-	cmd := exec.CommandContext(runCtx, r.binary, image, "--json", tmpFile.Name()) //nolint:gosec // binary resolved from trusted lookup
-
-	// Podman Support: If docker is missing but podman is present and DOCKER_HOST
-	// is not set, try to detect the Podman machine socket automatically.
-	if _, err := exec.LookPath("docker"); err != nil {
-		if _, err := exec.LookPath("podman"); err == nil && os.Getenv("DOCKER_HOST") == "" {
-			if socketPath := detectPodmanSocket(ctx); socketPath != "" {
-				env := os.Environ()
-				env = append(env, "DOCKER_HOST="+socketPath)
-				cmd.Env = env
-			}
-		}
+	args := []string{image, "--json", tmpFile.Name()}
+	source, err := scanSource("dive")
+	if err != nil {
+		return nil, err
+	}
+	if source != "" {
+		args = append(args, "--source", source)
 	}
 
+	runCtx, cancel := commandTimeout(withCommandTimeout(ctx, r.Timeout), TimeoutScan)
+	defer cancel()
+	cmd := commandContext(runCtx, r.binary, append(args, r.Args...)...) //nolint:gosec // binary resolved from trusted lookup
+
 	// Dive writes to file, but might output logs to stdout/stderr. capture or ignore?
 	// cmd.CombinedOutput() might be useful for debugging if it fails.
 
@@ -140,7 +126,7 @@ func parseDiveOutput(content []byte) (*types.ImageStats, error) {
 	}
 
 	if err := json.Unmarshal(content, &diveOutput); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal dive output: %w", err)
+		return nil, parseError("dive", fmt.Errorf("failed to unmarshal dive output: %w", err))
 	}
 
 	stats := &types.ImageStats{
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of analysis failure. An *Error matches its kind with errors.Is, so
+// callers can tell failures apart without parsing tool output:
+//
+//	if errors.Is(err, runner.ErrAuthRequired) { ... }
+var (
+	// ErrImageNotFound means the image does not exist locally or in its
+	// registry, or may not be pulled under the pull policy.
+	ErrImageNotFound = errors.New("image not found")
+	// ErrAuthRequired means the registry refused access without (valid)
+	// credentials.
+	ErrAuthRequired = errors.New("registry authentication required")
+	// ErrDaemonUnreachable means the container runtime's daemon or service
+	// could not be reached.
+	ErrDaemonUnreachable = errors.New("container runtime unreachable")
+	// ErrToolMissing means an external tool is not installed.
+	ErrToolMissing = errors.New("tool not installed")
+	// ErrTimeout means a command did not finish in time. Timeouts also
+	// match context.DeadlineExceeded.
+	ErrTimeout = errors.New("timed out")
+	// ErrParse means a tool's output could not be parsed.
+	ErrParse = errors.New("unreadable tool output")
+)
+
+// Error is a classified failure of an external tool.
+type Error struct {
+	// Kind is one of the Err* kinds above.
+	Kind error
+	// Tool is the tool that failed (e.g., "grype" or "docker").
+	Tool string
+	// Err is the underlying error, including the tool's stderr.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v: %v", e.Tool, e.Kind, e.Err)
+}
+
+// Unwrap returns the kind and the underlying error, so errors.Is matches
+// both (e.g., ErrTimeout and context.DeadlineExceeded).
+func (e *Error) Unwrap() []error {
+	errs := []error{e.Kind, e.Err}
+	if e.Kind == ErrTimeout && !errors.Is(e.Err, context.DeadlineExceeded) {
+		errs = append(errs, context.DeadlineExceeded)
+	}
+	return errs
+}
+
+// stderrKinds classifies command failures by fragments of their (lowercased)
+// error output. The first matching kind wins; "repository does not exist"
+// precedes the authentication patterns because docker reports missing
+// repositories as "pull access denied".
+var stderrKinds = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrDaemonUnreachable, []string{
+		"cannot connect to the docker daemon",
+		"is the docker daemon running",
+		"error during connect",
+		"cannot connect to podman",
+		"unable to connect to podman",
+		"containerd.sock",
+		"permission denied while trying to connect",
+	}},
+	{ErrImageNotFound, []string{
+		"no such image",
+		"image not known",
+		"manifest unknown",
+		"name unknown",
+		"repository does not exist",
+		"unable to find image",
+		"not found: manifest",
+	}},
+	{ErrAuthRequired, []string{
+		"unauthorized",
+		"authentication required",
+		"no basic auth credentials",
+		"denied: requested access",
+		"access denied",
+		"403 forbidden",
+	}},
+}
+
+// classify turns a failure of the command cmd into an *Error when its cause
+// is recognised, and returns err unchanged otherwise. timedOut reports
+// whether the command was killed because its context ended.
+func classify(cmd *exec.Cmd, err error, stderr string, timedOut bool) error {
+	tool := filepath.Base(cmd.Path)
+	switch {
+	case timedOut:
+		return &Error{Kind: ErrTimeout, Tool: tool, Err: err}
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return &Error{Kind: ErrToolMissing, Tool: tool, Err: err}
+	}
+	msg := strings.ToLower(stderr)
+	for _, k := range stderrKinds {
+		for _, p := range k.patterns {
+			if strings.Contains(msg, p) {
+				return &Error{Kind: k.kind, Tool: tool, Err: err}
+			}
+		}
+	}
+	return err
+}
+
+// toolMissing returns the error for a tool that is not installed.
+func toolMissing(tool string) error {
+	return &Error{Kind: ErrToolMissing, Tool: tool, Err: fmt.Errorf("%s not found", tool)}
+}
+
+// parseError returns the error for output of tool that could not be parsed.
+func parseError(tool string, err error) error {
+	return &Error{Kind: ErrParse, Tool: tool, Err: err}
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// FakeBackend is an in-memory Backend for tests. It behaves like a registry
+// client with a local image store: every image in Images can be inspected and
+// pulled, and pulling marks it as local.
+type FakeBackend struct {
+	// Images are the images known to the fake, keyed by reference.
+	Images map[string]*types.ImageStats
+	// Local marks the references present in the local image store.
+	Local map[string]bool
+	// Manifests are the raw manifests returned by Manifest, keyed by reference.
+	Manifests map[string][]byte
+	// PullErr, when set, is returned by every Pull.
+	PullErr error
+
+	mu    sync.Mutex
+	pulls []string
+	// platforms are the platforms requested by each pull.
+	platforms []string
+}
+
+// Name returns "fake".
+func (f *FakeBackend) Name() string { return "fake" }
+
+// Available always returns true.
+func (f *FakeBackend) Available() bool { return true }
+
+// Inspect returns a copy of the stats registered for image.
+func (f *FakeBackend) Inspect(_ context.Context, image string, _ bool) (*types.ImageStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats, ok := f.Images[image]
+	if !ok {
+		return nil, &Error{Kind: ErrImageNotFound, Tool: f.Name(), Err: fmt.Errorf("image %s not found", image)}
+	}
+	out := *stats
+	out.ImageTag = image
+	return &out, nil
+}
+
+// ImageID returns the ImageID of the stats registered for image.
+func (f *FakeBackend) ImageID(ctx context.Context, image string, verbose bool) (string, error) {
+	stats, err := f.Inspect(ctx, image, verbose)
+	if err != nil {
+		return "", err
+	}
+	return stats.ImageID, nil
+}
+
+// ScanSource returns "", leaving the source to the scanner.
+func (f *FakeBackend) ScanSource(string) (string, error) { return "", nil }
+
+// Exists reports whether image is marked as local.
+func (f *FakeBackend) Exists(_ context.Context, image string, _ bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Local[image]
+}
+
+// Pull records the pull and marks image as local.
+func (f *FakeBackend) Pull(_ context.Context, image, platform string, _ bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pulls = append(f.pulls, image)
+	f.platforms = append(f.platforms, platform)
+	if f.PullErr != nil {
+		return f.PullErr
+	}
+	if _, ok := f.Images[image]; !ok {
+		return &Error{Kind: ErrImageNotFound, Tool: f.Name(), Err: fmt.Errorf("image %s not found", image)}
+	}
+	if f.Local == nil {
+		f.Local = make(map[string]bool)
+	}
+	f.Local[image] = true
+	return nil
+}
+
+// Pulls returns the references passed to Pull, in order.
+func (f *FakeBackend) Pulls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.pulls...)
+}
+
+// PullPlatforms returns the platform requested by each pull, in order.
+func (f *FakeBackend) PullPlatforms() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.platforms...)
+}
+
+// Manifest returns the manifest registered for image.
+func (f *FakeBackend) Manifest(_ context.Context, image string, _ bool) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.Manifests[image]
+	if !ok {
+		return nil, &Error{Kind: ErrImageNotFound, Tool: f.Name(), Err: fmt.Errorf("manifest for %s not found", image)}
+	}
+	return m, nil
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os/exec"
 	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
@@ -13,6 +12,12 @@ import (
 
 // GrypeRunner runs 'grype <image> -o json'
 type GrypeRunner struct {
+	// Timeout overrides the timeout of each command (default: TimeoutScan
+	// for the scan, TimeoutInspect for version queries).
+	Timeout time.Duration
+	// Args are extra command-line arguments appended to the grype command.
+	Args []string
+
 	binary string
 }
 
@@ -28,17 +33,43 @@ func (r *GrypeRunner) IsAvailable() bool {
 	return false
 }
 
+// CacheKey identifies the grype build and its vulnerability database, so
+// cached results are invalidated when either is updated.
+func (r *GrypeRunner) CacheKey(ctx context.Context) (string, error) {
+	if r.binary == "" && !r.IsAvailable() {
+		return "", toolMissing("grype")
+	}
+	ctx = withCommandTimeout(ctx, r.Timeout)
+	version, err := toolVersion(ctx, r.binary, "version")
+	if err != nil {
+		return "", err
+	}
+	db, err := toolVersion(ctx, r.binary, "db", "status")
+	if err != nil {
+		return "", err
+	}
+	return version + "\n" + db, nil
+}
+
+// ArgsKey identifies the extra arguments passed to grype, which may change
+// its output.
+func (r *GrypeRunner) ArgsKey() string { return argsKey(r.Args) }
+
 // Run executes 'grype <image> -o json' and parses the result.
 // The provided context is used as the parent for the command timeout.
 func (r *GrypeRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
 	if r.binary == "" {
 		if !r.IsAvailable() {
-			return nil, fmt.Errorf("grype not found")
+			return nil, toolMissing("grype")
 		}
 	}
-	runCtx, cancel := context.WithTimeout(ctx, TimeoutScan)
+	target, err := scanImage("grype", image)
+	if err != nil {
+		return nil, err
+	}
+	runCtx, cancel := commandTimeout(withCommandTimeout(ctx, r.Timeout), TimeoutScan)
 	defer cancel()
-	cmd := exec.CommandContext(runCtx, r.binary, image, "-o", "json")
+	cmd := commandContext(runCtx, r.binary, append([]string{target, "-o", "json"}, r.Args...)...)
 	output, err := runCommand(cmd, verbose)
 	if err != nil {
 		return nil, err
@@ -47,8 +78,29 @@ func (r *GrypeRunner) Run(ctx context.Context, image string, verbose bool) (*typ
 	return parseGrypeOutput(output, verbose)
 }
 
+// grypeCVSS is a single CVSS entry in grype's JSON output.
+type grypeCVSS struct {
+	Version string `json:"version"`
+	Vector  string `json:"vector"`
+	Metrics struct {
+		BaseScore float64 `json:"baseScore"`
+	} `json:"metrics"`
+}
+
+// grypeVulnMetadata holds the vulnerability fields shared by a match's
+// primary vulnerability and its related vulnerabilities.
+type grypeVulnMetadata struct {
+	ID          string      `json:"id"`
+	DataSource  string      `json:"dataSource"`
+	Severity    string      `json:"severity"`
+	Description string      `json:"description"`
+	CVSS        []grypeCVSS `json:"cvss"`
+}
+
 // parseGrypeOutput parses JSON output from 'grype <image> -o json'
 // into ImageStats containing vulnerability summary, details, and scan time.
+// Description and CVSS data missing from the primary record (common for
+// distro advisories) are filled in from related vulnerabilities (e.g., NVD).
 func parseGrypeOutput(output []byte, verbose bool) (*types.ImageStats, error) {
 	var grypeOutput struct {
 		Descriptor struct {
@@ -56,18 +108,26 @@ func parseGrypeOutput(output []byte, verbose bool) (*types.ImageStats, error) {
 		} `json:"descriptor"`
 		Matches []struct {
 			Vulnerability struct {
-				ID       string `json:"id"`
-				Severity string `json:"severity"`
+				grypeVulnMetadata
+				Fix struct {
+					Versions []string `json:"versions"`
+					State    string   `json:"state"`
+				} `json:"fix"`
 			} `json:"vulnerability"`
-			Artifact struct {
-				Name    string `json:"name"`
-				Version string `json:"version"`
+			RelatedVulnerabilities []grypeVulnMetadata `json:"relatedVulnerabilities"`
+			Artifact               struct {
+				Name      string `json:"name"`
+				Version   string `json:"version"`
+				Type      string `json:"type"`
+				Locations []struct {
+					Path string `json:"path"`
+				} `json:"locations"`
 			} `json:"artifact"`
 		} `json:"matches"`
 	}
 
 	if err := json.Unmarshal(output, &grypeOutput); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal grype output: %w", err)
+		return nil, parseError("grype", fmt.Errorf("failed to unmarshal grype output: %w", err))
 	}
 
 	scanTime := time.Now()
@@ -86,18 +146,61 @@ func parseGrypeOutput(output []byte, verbose bool) (*types.ImageStats, error) {
 	}
 
 	for _, match := range grypeOutput.Matches {
-		sev := match.Vulnerability.Severity
+		meta := match.Vulnerability.grypeVulnMetadata
+		sev := meta.Severity
 		stats.VulnSummary[sev]++
 
-		stats.Vulnerabilities = append(stats.Vulnerabilities, types.Vulnerability{
-			ID:       match.Vulnerability.ID,
-			Severity: sev,
-			Package:  match.Artifact.Name,
-			Version:  match.Artifact.Version,
-		})
+		vuln := types.Vulnerability{
+			ID:              meta.ID,
+			Severity:        sev,
+			Package:         match.Artifact.Name,
+			Version:         match.Artifact.Version,
+			FixState:        match.Vulnerability.Fix.State,
+			FixedInVersions: match.Vulnerability.Fix.Versions,
+			DataSource:      meta.DataSource,
+			Description:     meta.Description,
+			PackageType:     match.Artifact.Type,
+		}
+		if len(match.Artifact.Locations) > 0 {
+			vuln.Location = match.Artifact.Locations[0].Path
+		}
+
+		cvss, ok := preferredCVSS(meta.CVSS)
+		for _, related := range match.RelatedVulnerabilities {
+			if vuln.Description == "" {
+				vuln.Description = related.Description
+			}
+			if !ok {
+				cvss, ok = preferredCVSS(related.CVSS)
+			}
+		}
+		if ok {
+			vuln.CVSSScore = cvss.Metrics.BaseScore
+			vuln.CVSSVector = cvss.Vector
+		}
+
+		stats.Vulnerabilities = append(stats.Vulnerabilities, vuln)
 	}
 
 	types.SortBySeverity(stats.Vulnerabilities)
 
 	return stats, nil
 }
+
+// preferredCVSS picks the CVSS entry to report: the newest CVSS version wins,
+// and the highest base score breaks ties between entries of the same version.
+func preferredCVSS(entries []grypeCVSS) (grypeCVSS, bool) {
+	var best grypeCVSS
+	found := false
+	for _, e := range entries {
+		if e.Metrics.BaseScore == 0 && e.Vector == "" {
+			continue
+		}
+		if !found || e.Version > best.Version ||
+			(e.Version == best.Version && e.Metrics.BaseScore > best.Metrics.BaseScore) {
+			best = e
+			found = true
+		}
+	}
+	return best, found
+}
@@ -4,62 +4,143 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
-// ManifestRunner runs 'docker manifest inspect <image>'
+// ManifestRunner reads the registry manifest of the image with the selected
+// container runtime backend (e.g., 'docker manifest inspect <image>').
 type ManifestRunner struct {
-	binary string
+	// Timeout overrides the timeout of the manifest command (default:
+	// TimeoutInspect).
+	Timeout time.Duration
+
+	backend Backend
 }
 
 // Name returns the display name for this runner.
 func (r *ManifestRunner) Name() string { return "manifest" }
 
-// IsAvailable checks whether a container runtime is installed for manifest inspection.
+// IsAvailable checks whether a container runtime backend is available for
+// manifest inspection.
 func (r *ManifestRunner) IsAvailable() bool {
-	// Check docker
-	if _, err := exec.LookPath("docker"); err == nil {
-		r.binary = "docker"
-		return true
-	}
-	// Podman manifest inspect also works
-	if _, err := exec.LookPath("podman"); err == nil {
-		r.binary = "podman"
-		return true
+	b, err := CurrentBackend()
+	if err != nil {
+		return false
 	}
-	return false
+	r.backend = b
+	return true
 }
 
-// Run executes 'docker manifest inspect' or 'podman manifest inspect' and parses the result.
+// Run reads the image manifest and parses the result.
 // The provided context is used as the parent for the command timeout.
 func (r *ManifestRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
-	if r.binary == "" {
-		if !r.IsAvailable() {
-			return nil, fmt.Errorf("no container runtime found")
+	if r.backend == nil {
+		b, err := CurrentBackend()
+		if err != nil {
+			return nil, err
 		}
+		r.backend = b
 	}
 
-	// Try standard manifest inspect
-	// Need DOCKER_CLI_EXPERIMENTAL=enabled for older docker to be safe
-	runCtx, cancel := context.WithTimeout(ctx, TimeoutInspect)
-	defer cancel()
-	cmd := exec.CommandContext(runCtx, r.binary, "manifest", "inspect", image)
-	cmd.Env = append(os.Environ(), "DOCKER_CLI_EXPERIMENTAL=enabled")
-
-	output, err := runCommand(cmd, verbose)
+	ctx = withCommandTimeout(ctx, r.Timeout)
+	output, err := r.backend.Manifest(ctx, image, verbose)
 	if err != nil {
-		// Fallback or just return empty stats (optional feature)
-		// We'll return error so analyzer can log warning
+		// Return the error so the analyzer can log a warning
 		return nil, fmt.Errorf("manifest inspect failed: %w", err)
 	}
 
 	return parseManifestInspect(output, image)
 }
 
+// Platform identifies one image in a multi-arch manifest list.
+type Platform struct {
+	OS           string
+	Architecture string
+	Variant      string
+	Digest       string
+}
+
+// String returns the platform as "os/arch" or "os/arch/variant".
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
+// Ref returns a reference that pins image to this platform's manifest digest
+// (e.g., "nginx@sha256:..."), so every tool analyzes the same platform.
+func (p Platform) Ref(image string) string {
+	return repository(image) + "@" + p.Digest
+}
+
+// ListPlatforms returns the platforms of a multi-arch image. A single-platform
+// image yields an empty list. Attestation manifests (platform "unknown/unknown")
+// are skipped.
+func ListPlatforms(ctx context.Context, image string, verbose bool) ([]Platform, error) {
+	b, err := CurrentBackend()
+	if err != nil {
+		return nil, err
+	}
+	output, err := b.Manifest(ctx, image, verbose)
+	if err != nil {
+		return nil, fmt.Errorf("manifest inspect failed: %w", err)
+	}
+	return parseManifestPlatforms(output), nil
+}
+
+// parseManifestPlatforms extracts the per-platform manifests from a manifest
+// list, sorted by platform. Output that is not a manifest list yields nil.
+func parseManifestPlatforms(output []byte) []Platform {
+	var index struct {
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				Architecture string `json:"architecture"`
+				OS           string `json:"os"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(output, &index); err != nil {
+		return nil
+	}
+
+	var platforms []Platform
+	seen := make(map[string]bool)
+	for _, m := range index.Manifests {
+		p := Platform{
+			OS:           m.Platform.OS,
+			Architecture: m.Platform.Architecture,
+			Variant:      m.Platform.Variant,
+			Digest:       m.Digest,
+		}
+		if p.Digest == "" || p.OS == "" || p.OS == "unknown" || seen[p.String()] {
+			continue
+		}
+		seen[p.String()] = true
+		platforms = append(platforms, p)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+	return platforms
+}
+
+// repository strips the tag and digest from an image reference, taking care
+// not to mistake a registry port ("localhost:5000/app") for a tag.
+func repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
 // parseManifestInspect parses JSON output from 'docker manifest inspect'
 // into ImageStats containing supported architectures for multi-arch images.
 // The error return is always nil because manifest data is optional and gracefully
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// PluginAPIVersion identifies the version of the plugin protocol. It is sent
+// to every plugin so that plugins can reject requests they do not understand.
+const PluginAPIVersion = "dock-docs.plugin/v1"
+
+// PluginRequest is the JSON document written to a plugin's stdin.
+type PluginRequest struct {
+	APIVersion string         `json:"apiVersion"`
+	Image      string         `json:"image"`
+	Options    map[string]any `json:"options,omitempty"`
+}
+
+// pluginResponse is the JSON document a plugin writes to stdout: any subset
+// of the ImageStats fields plus a free-form "custom" object that is exposed
+// to templates under the plugin's name.
+type pluginResponse struct {
+	types.ImageStats
+	Custom map[string]any `json:"custom,omitempty"`
+}
+
+// PluginRunner runs an external executable that speaks the dock-docs plugin
+// protocol: it receives a PluginRequest on stdin and prints a partial
+// ImageStats document on stdout.
+type PluginRunner struct {
+	// PluginName is the display name and the key under which custom fields
+	// are stored in ImageStats.Custom.
+	PluginName string
+	// Command is the executable to run (a name on PATH or a file path).
+	Command string
+	// Args are extra command-line arguments passed to the executable.
+	Args []string
+	// Options are passed through verbatim in the request's "options" field.
+	Options map[string]any
+	// Timeout bounds a single plugin invocation (default: TimeoutScan).
+	Timeout time.Duration
+
+	binary string
+}
+
+// Name returns the display name for this runner.
+func (r *PluginRunner) Name() string { return r.PluginName }
+
+// IsAvailable checks whether the plugin executable can be found.
+func (r *PluginRunner) IsAvailable() bool {
+	if path, err := exec.LookPath(r.Command); err == nil {
+		r.binary = path
+		return true
+	}
+	return false
+}
+
+// Run executes the plugin with the image reference and options on stdin and
+// parses the partial ImageStats it prints.
+// The provided context is used as the parent for the command timeout.
+func (r *PluginRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	if r.binary == "" {
+		if !r.IsAvailable() {
+			return nil, &Error{Kind: ErrToolMissing, Tool: r.PluginName, Err: fmt.Errorf("plugin %s: executable %q not found", r.PluginName, r.Command)}
+		}
+	}
+
+	payload, err := json.Marshal(PluginRequest{
+		APIVersion: PluginAPIVersion,
+		Image:      image,
+		Options:    r.Options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: failed to encode request: %w", r.PluginName, err)
+	}
+
+	runCtx, cancel := commandTimeout(withCommandTimeout(ctx, r.Timeout), TimeoutScan)
+	defer cancel()
+	cmd := commandContext(runCtx, r.binary, r.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	output, err := runCommand(cmd, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePluginOutput(output, r.PluginName)
+}
+
+// parsePluginOutput parses a plugin's stdout into ImageStats. Custom fields
+// are namespaced under the plugin name, and a vulnerability summary is
+// derived from the vulnerability list when the plugin omits one.
+func parsePluginOutput(output []byte, name string) (*types.ImageStats, error) {
+	var resp pluginResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, parseError(name, fmt.Errorf("failed to unmarshal plugin %s output: %w", name, err))
+	}
+
+	stats := resp.ImageStats
+	if len(resp.Custom) > 0 {
+		stats.Custom = map[string]map[string]any{name: resp.Custom}
+	}
+	if len(stats.Vulnerabilities) > 0 && len(stats.VulnSummary) == 0 {
+		stats.VulnSummary = make(map[string]int)
+		for _, v := range stats.Vulnerabilities {
+			stats.VulnSummary[v.Severity]++
+		}
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Pull policies accepted by SetPullOptions.
+const (
+	// PullIfNotPresent pulls images that are not available locally (default).
+	PullIfNotPresent = "if-not-present"
+	// PullAlways pulls every image before analysis, so a moved tag is
+	// always analyzed at its current digest.
+	PullAlways = "always"
+	// PullNever only analyzes local images and fails for missing ones.
+	PullNever = "never"
+)
+
+// PullOptions control how EnsureImage fetches images.
+type PullOptions struct {
+	// Policy is PullIfNotPresent (when empty), PullAlways or PullNever.
+	Policy string
+	// Platform requests a platform ("os/arch[/variant]") when pulling
+	// images by tag. Digest references already pin a platform or manifest
+	// list and are pulled as is.
+	Platform string
+}
+
+var (
+	pullMu      sync.RWMutex
+	pullOptions PullOptions
+)
+
+// SetPullOptions configures EnsureImage.
+func SetPullOptions(o PullOptions) error {
+	switch o.Policy {
+	case "", PullIfNotPresent, PullAlways, PullNever:
+	default:
+		return fmt.Errorf("unknown pull policy %q (want %s, %s or %s)", o.Policy, PullAlways, PullIfNotPresent, PullNever)
+	}
+	if o.Platform != "" {
+		if parts := strings.Split(o.Platform, "/"); len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid platform %q (want os/arch or os/arch/variant)", o.Platform)
+		}
+	}
+	pullMu.Lock()
+	defer pullMu.Unlock()
+	pullOptions = o
+	return nil
+}
+
+// currentPullOptions returns the configured pull options.
+func currentPullOptions() PullOptions {
+	pullMu.RLock()
+	defer pullMu.RUnlock()
+	return pullOptions
+}
+
+// pullPlatform returns the platform to request when pulling image: none for
+// digest references.
+func pullPlatform(image string, o PullOptions) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	return o.Platform
+}
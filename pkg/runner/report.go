@@ -0,0 +1,778 @@
+package runner
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/sbom"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Report formats accepted by ParseReport.
+const (
+	ReportSyft      = "syft"
+	ReportSPDX      = "spdx"
+	ReportCycloneDX = "cyclonedx"
+	ReportGrype     = "grype"
+	ReportTrivy     = "trivy"
+	ReportSARIF     = "sarif"
+)
+
+// ReadReport reads and parses an SBOM or scan report file.
+func ReadReport(path string) (*types.ImageStats, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read report: %w", err)
+	}
+	stats, format, err := ParseReport(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return stats, format, nil
+}
+
+// ParseReport detects the format of an existing SBOM or scan report (syft,
+// SPDX or CycloneDX JSON, grype or trivy JSON, or SARIF) and converts it to
+// ImageStats, so artifacts produced elsewhere in a pipeline can be documented
+// without re-running the tools. ImageTag is set to the scanned image when the
+// report records it.
+func ParseReport(data []byte) (*types.ImageStats, string, error) {
+	format, err := DetectReportFormat(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var stats *types.ImageStats
+	switch format {
+	case ReportSyft:
+		stats, err = parseSyftOutput(data)
+	case ReportSPDX:
+		stats, err = parseSPDXReport(data)
+	case ReportCycloneDX:
+		stats, err = parseCycloneDXReport(data)
+	case ReportGrype:
+		stats, err = parseGrypeOutput(data, false)
+	case ReportTrivy:
+		stats, err = parseTrivyReport(data)
+	case ReportSARIF:
+		stats, err = parseSARIFReport(data)
+	}
+	if err != nil {
+		return nil, format, err
+	}
+	if stats.ImageTag == "" {
+		stats.ImageTag = reportSubject(format, data)
+	}
+	stats.Runners = reportRunners(format, data, stats)
+	return stats, format, nil
+}
+
+// reportRunners returns the runners whose results a report stands in for:
+// syft for a package inventory and grype for a vulnerability scan. A
+// CycloneDX BOM is a scan only if it has a vulnerabilities section, and a
+// trivy report lists packages only when run with --list-all-pkgs.
+func reportRunners(format string, data []byte, stats *types.ImageStats) []types.RunnerStatus {
+	var packages, vulns bool
+	switch format {
+	case ReportSyft, ReportSPDX:
+		packages = true
+	case ReportCycloneDX:
+		var probe struct {
+			Vulnerabilities json.RawMessage `json:"vulnerabilities"`
+		}
+		packages = true
+		vulns = json.Unmarshal(data, &probe) == nil && probe.Vulnerabilities != nil
+	case ReportTrivy:
+		packages, vulns = len(stats.Packages) > 0, true
+	case ReportGrype, ReportSARIF:
+		vulns = true
+	}
+
+	var runners []types.RunnerStatus
+	if packages {
+		runners = append(runners, types.RunnerStatus{Name: "syft", Status: types.RunnerOK})
+	}
+	if vulns {
+		runners = append(runners, types.RunnerStatus{Name: "grype", Status: types.RunnerOK})
+	}
+	return runners
+}
+
+// DetectReportFormat identifies a report by the top-level fields that each
+// format requires.
+func DetectReportFormat(data []byte) (string, error) {
+	var probe struct {
+		SPDXVersion   string          `json:"spdxVersion"`
+		BOMFormat     string          `json:"bomFormat"`
+		Schema        string          `json:"$schema"`
+		Runs          json.RawMessage `json:"runs"`
+		SchemaVersion json.RawMessage `json:"SchemaVersion"`
+		Results       json.RawMessage `json:"Results"`
+		Matches       json.RawMessage `json:"matches"`
+		Artifacts     json.RawMessage `json:"artifacts"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", fmt.Errorf("report is not valid JSON: %w", err)
+	}
+	switch {
+	case strings.HasPrefix(probe.SPDXVersion, "SPDX-"):
+		return ReportSPDX, nil
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		return ReportCycloneDX, nil
+	case probe.Runs != nil && (strings.Contains(strings.ToLower(probe.Schema), "sarif") || probe.Schema == ""):
+		return ReportSARIF, nil
+	case probe.SchemaVersion != nil && probe.Results != nil:
+		return ReportTrivy, nil
+	case probe.Matches != nil:
+		return ReportGrype, nil
+	case probe.Artifacts != nil:
+		return ReportSyft, nil
+	}
+	return "", fmt.Errorf("unrecognized report format (want SPDX, CycloneDX, syft, grype, trivy or SARIF JSON)")
+}
+
+// reportSubject returns the image a report describes, if recorded.
+func reportSubject(format string, data []byte) string {
+	switch format {
+	case ReportSyft, ReportGrype:
+		var doc struct {
+			Source struct {
+				Target json.RawMessage `json:"target"`
+			} `json:"source"`
+		}
+		if json.Unmarshal(data, &doc) != nil {
+			return ""
+		}
+		var target struct {
+			UserInput string `json:"userInput"`
+		}
+		if json.Unmarshal(doc.Source.Target, &target) == nil {
+			return target.UserInput
+		}
+	case ReportTrivy:
+		var doc struct {
+			ArtifactName string `json:"ArtifactName"`
+		}
+		if json.Unmarshal(data, &doc) == nil {
+			return doc.ArtifactName
+		}
+	case ReportCycloneDX:
+		var doc struct {
+			Metadata struct {
+				Component struct {
+					Name string `json:"name"`
+				} `json:"component"`
+			} `json:"metadata"`
+		}
+		if json.Unmarshal(data, &doc) == nil {
+			return doc.Metadata.Component.Name
+		}
+	}
+	return ""
+}
+
+// normalizeSeverity maps the severity spellings used by the supported
+// formats ("HIGH", "moderate", "info") to the names used by grype.
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical":
+		return "Critical"
+	case "high":
+		return "High"
+	case "medium", "moderate":
+		return "Medium"
+	case "low":
+		return "Low"
+	case "negligible", "info", "none":
+		return "Negligible"
+	default:
+		return "Unknown"
+	}
+}
+
+// severityFromScore derives a severity from a CVSS v3 base score.
+func severityFromScore(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Medium"
+	case score > 0:
+		return "Low"
+	default:
+		return "Unknown"
+	}
+}
+
+// purlTypes maps package URL types to the syft package types used elsewhere.
+var purlTypes = map[string]string{
+	"apk":       "apk",
+	"deb":       "deb",
+	"rpm":       "rpm",
+	"alpm":      "alpm",
+	"npm":       "npm",
+	"pypi":      "python",
+	"maven":     "java-archive",
+	"golang":    "go-module",
+	"gem":       "gem",
+	"cargo":     "rust-crate",
+	"nuget":     "dotnet",
+	"composer":  "php-composer",
+	"hex":       "hex",
+	"pub":       "dart-pub",
+	"cocoapods": "pod",
+	"swift":     "swift",
+}
+
+// purlType returns the syft package type for a package URL, or "" if the
+// PURL is missing or of an unknown type.
+func purlType(purl string) string {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return ""
+	}
+	typ, _, _ := strings.Cut(rest, "/")
+	return purlTypes[strings.ToLower(typ)]
+}
+
+// finishPackages deduplicates packages by name@version, sorts them by name
+// and sets TotalPackages, matching parseSyftOutput.
+func finishPackages(stats *types.ImageStats) {
+	seen := make(map[string]bool)
+	pkgs := stats.Packages[:0]
+	for _, p := range stats.Packages {
+		key := p.Name + "@" + p.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		pkgs = append(pkgs, p)
+	}
+	stats.Packages = pkgs
+	sort.Slice(stats.Packages, func(i, j int) bool {
+		return stats.Packages[i].Name < stats.Packages[j].Name
+	})
+	stats.TotalPackages = len(stats.Packages)
+}
+
+// finishVulnerabilities rebuilds the severity summary and sorts findings.
+func finishVulnerabilities(stats *types.ImageStats) {
+	stats.VulnSummary = make(map[string]int)
+	for _, v := range stats.Vulnerabilities {
+		stats.VulnSummary[v.Severity]++
+	}
+	types.SortBySeverity(stats.Vulnerabilities)
+}
+
+// parseSPDXReport parses an SPDX 2.x JSON document. Packages that describe
+// the image itself are skipped; an operating-system package sets OSDistro.
+func parseSPDXReport(data []byte) (*types.ImageStats, error) {
+	var doc struct {
+		Packages []struct {
+			Name                  string `json:"name"`
+			VersionInfo           string `json:"versionInfo"`
+			LicenseConcluded      string `json:"licenseConcluded"`
+			LicenseDeclared       string `json:"licenseDeclared"`
+			PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+			ExternalRefs          []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SPDX document: %w", err)
+	}
+
+	stats := &types.ImageStats{Packages: make([]types.PackageSummary, 0, len(doc.Packages))}
+	for _, p := range doc.Packages {
+		switch p.PrimaryPackagePurpose {
+		case "CONTAINER":
+			continue
+		case "OPERATING-SYSTEM":
+			stats.OSDistro = strings.TrimSpace(p.Name + " " + p.VersionInfo)
+			continue
+		}
+
+		pkg := types.PackageSummary{Name: p.Name, Version: p.VersionInfo}
+		for _, ref := range p.ExternalRefs {
+			switch ref.ReferenceType {
+			case "purl":
+				pkg.PURL = ref.ReferenceLocator
+			case "cpe23Type", "cpe22Type":
+				pkg.CPEs = append(pkg.CPEs, ref.ReferenceLocator)
+			}
+		}
+		pkg.Type = purlType(pkg.PURL)
+		if l := spdxLicense(p.LicenseConcluded); l != "" {
+			pkg.Licenses = []string{l}
+		} else if l := spdxLicense(p.LicenseDeclared); l != "" {
+			pkg.Licenses = []string{l}
+		}
+		stats.Packages = append(stats.Packages, pkg)
+	}
+	finishPackages(stats)
+	return stats, nil
+}
+
+// spdxLicense returns an SPDX license expression, or "" for the
+// NOASSERTION and NONE placeholders.
+func spdxLicense(expr string) string {
+	switch expr {
+	case "", "NOASSERTION", "NONE":
+		return ""
+	}
+	return expr
+}
+
+// parseCycloneDXReport parses a CycloneDX JSON BOM, including its
+// vulnerabilities section (as written by grype, trivy and dock-docs).
+// Findings analysed as not_affected, false_positive or will_not_fix are
+// imported as suppressed; resolved findings are dropped.
+func parseCycloneDXReport(data []byte) (*types.ImageStats, error) {
+	var bom struct {
+		Components []struct {
+			BOMRef   string `json:"bom-ref"`
+			Type     string `json:"type"`
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			PURL     string `json:"purl"`
+			CPE      string `json:"cpe"`
+			Licenses []struct {
+				License struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"license"`
+				Expression string `json:"expression"`
+			} `json:"licenses"`
+			Properties []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"properties"`
+		} `json:"components"`
+		Vulnerabilities []struct {
+			ID     string `json:"id"`
+			Source struct {
+				URL string `json:"url"`
+			} `json:"source"`
+			Ratings []struct {
+				Score    float64 `json:"score"`
+				Severity string  `json:"severity"`
+				Method   string  `json:"method"`
+				Vector   string  `json:"vector"`
+			} `json:"ratings"`
+			Description string `json:"description"`
+			Affects     []struct {
+				Ref string `json:"ref"`
+			} `json:"affects"`
+			Analysis struct {
+				State         string   `json:"state"`
+				Justification string   `json:"justification"`
+				Response      []string `json:"response"`
+				Detail        string   `json:"detail"`
+			} `json:"analysis"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal CycloneDX document: %w", err)
+	}
+
+	stats := &types.ImageStats{
+		Packages:        make([]types.PackageSummary, 0, len(bom.Components)),
+		Vulnerabilities: make([]types.Vulnerability, 0, len(bom.Vulnerabilities)),
+	}
+	byRef := make(map[string]types.PackageSummary)
+	for _, c := range bom.Components {
+		if c.Type == "operating-system" {
+			stats.OSDistro = strings.TrimSpace(c.Name + " " + c.Version)
+			continue
+		}
+		if c.Type == "container" {
+			continue
+		}
+		pkg := types.PackageSummary{Name: c.Name, Version: c.Version, PURL: c.PURL, Type: purlType(c.PURL)}
+		for _, prop := range c.Properties {
+			if prop.Name == "syft:package:type" && prop.Value != "" {
+				pkg.Type = prop.Value
+			}
+		}
+		if c.CPE != "" {
+			pkg.CPEs = []string{c.CPE}
+		}
+		for _, l := range c.Licenses {
+			switch {
+			case l.Expression != "":
+				pkg.Licenses = append(pkg.Licenses, l.Expression)
+			case l.License.ID != "":
+				pkg.Licenses = append(pkg.Licenses, l.License.ID)
+			case l.License.Name != "":
+				pkg.Licenses = append(pkg.Licenses, l.License.Name)
+			}
+		}
+		stats.Packages = append(stats.Packages, pkg)
+		if c.BOMRef != "" {
+			byRef[c.BOMRef] = pkg
+		}
+	}
+
+	for _, v := range bom.Vulnerabilities {
+		vuln := types.Vulnerability{
+			ID:          v.ID,
+			Severity:    "Unknown",
+			DataSource:  v.Source.URL,
+			Description: v.Description,
+		}
+		// Prefer the rating with the highest score; scanners list one per source.
+		for _, r := range v.Ratings {
+			if vuln.Severity == "Unknown" || r.Score > vuln.CVSSScore {
+				vuln.Severity = normalizeSeverity(r.Severity)
+				vuln.CVSSScore = r.Score
+				vuln.CVSSVector = r.Vector
+			}
+		}
+		// Triaged findings are suppressed and resolved ones dropped, so a BOM
+		// written by dock-docs keeps its accepted risks out of the findings.
+		status, suppressed := "", false
+		switch a := v.Analysis; {
+		case a.State == "resolved" || a.State == "resolved_with_pedigree":
+			continue
+		case a.State == "not_affected" || a.State == "false_positive":
+			status, suppressed = a.State, true
+		case slices.Contains(a.Response, "will_not_fix"):
+			suppressed = true
+		}
+
+		// A finding is reported once per affected component.
+		refs := []string{""}
+		if len(v.Affects) > 0 {
+			refs = refs[:0]
+			for _, a := range v.Affects {
+				refs = append(refs, a.Ref)
+			}
+		}
+		for _, ref := range refs {
+			vv := vuln
+			if pkg, ok := byRef[ref]; ok {
+				vv.Package = pkg.Name
+				vv.Version = pkg.Version
+				vv.PackageType = pkg.Type
+			}
+			if suppressed {
+				stats.Suppressed = append(stats.Suppressed, cdxSuppressed(vv, status, v.Analysis.Justification, v.Analysis.Detail))
+				continue
+			}
+			stats.Vulnerabilities = append(stats.Vulnerabilities, vv)
+		}
+	}
+
+	finishPackages(stats)
+	finishVulnerabilities(stats)
+	return stats, nil
+}
+
+// cdxSuppressed builds a suppressed finding from a CycloneDX analysis. The
+// detail is the reason; without one the justification or state is used.
+func cdxSuppressed(v types.Vulnerability, status, justification, detail string) types.SuppressedVulnerability {
+	reason := detail
+	if reason == "" {
+		reason = strings.ReplaceAll(cmp.Or(justification, status, "will_not_fix"), "_", " ")
+	}
+	s := types.SuppressedVulnerability{Vulnerability: v, Justification: reason, Status: status}
+	if status == "not_affected" {
+		s.VEXJustification = sbom.OpenVEXJustification(justification)
+	}
+	return s
+}
+
+// trivyTypes maps trivy result types to syft package types for results
+// without a PURL.
+var trivyTypes = map[string]string{
+	"alpine": "apk", "wolfi": "apk", "chainguard": "apk",
+	"debian": "deb", "ubuntu": "deb",
+	"redhat": "rpm", "centos": "rpm", "rocky": "rpm", "alma": "rpm", "amazon": "rpm",
+	"oracle": "rpm", "fedora": "rpm", "photon": "rpm", "suse": "rpm", "opensuse": "rpm", "cbl-mariner": "rpm",
+	"npm": "npm", "node-pkg": "npm", "yarn": "npm", "pnpm": "npm",
+	"pip": "python", "pipenv": "python", "poetry": "python", "python-pkg": "python",
+	"jar": "java-archive", "pom": "java-archive", "gradle": "java-archive",
+	"gobinary": "go-module", "gomod": "go-module",
+	"gemspec": "gem", "bundler": "gem",
+	"cargo": "rust-crate", "rustbinary": "rust-crate",
+	"nuget": "dotnet", "dotnet-core": "dotnet",
+	"composer": "php-composer",
+}
+
+// trivyFixStates maps trivy vulnerability statuses to grype fix states.
+var trivyFixStates = map[string]string{
+	"fixed":        types.FixStateFixed,
+	"affected":     types.FixStateNotFixed,
+	"fix_deferred": types.FixStateNotFixed,
+	"will_not_fix": types.FixStateWontFix,
+	"end_of_life":  types.FixStateWontFix,
+}
+
+// parseTrivyReport parses 'trivy image -f json' output. Packages are only
+// present when trivy was run with --list-all-pkgs.
+func parseTrivyReport(data []byte) (*types.ImageStats, error) {
+	var report struct {
+		ArtifactName string `json:"ArtifactName"`
+		Metadata     struct {
+			OS struct {
+				Family string `json:"Family"`
+				Name   string `json:"Name"`
+			} `json:"OS"`
+		} `json:"Metadata"`
+		Results []struct {
+			Type     string `json:"Type"`
+			Packages []struct {
+				Name       string   `json:"Name"`
+				Version    string   `json:"Version"`
+				Licenses   []string `json:"Licenses"`
+				Identifier struct {
+					PURL string `json:"PURL"`
+				} `json:"Identifier"`
+			} `json:"Packages"`
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				PkgPath          string `json:"PkgPath"`
+				InstalledVersion string `json:"InstalledVersion"`
+				FixedVersion     string `json:"FixedVersion"`
+				Status           string `json:"Status"`
+				Severity         string `json:"Severity"`
+				Title            string `json:"Title"`
+				Description      string `json:"Description"`
+				PrimaryURL       string `json:"PrimaryURL"`
+				PkgIdentifier    struct {
+					PURL string `json:"PURL"`
+				} `json:"PkgIdentifier"`
+				CVSS map[string]struct {
+					V3Vector string  `json:"V3Vector"`
+					V3Score  float64 `json:"V3Score"`
+				} `json:"CVSS"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trivy report: %w", err)
+	}
+
+	stats := &types.ImageStats{
+		ImageTag:        report.ArtifactName,
+		Packages:        make([]types.PackageSummary, 0),
+		Vulnerabilities: make([]types.Vulnerability, 0),
+	}
+	if distro := report.Metadata.OS; distro.Family != "" {
+		stats.OSDistro = strings.TrimSpace(distro.Family + " " + distro.Name)
+	}
+
+	for _, result := range report.Results {
+		for _, p := range result.Packages {
+			typ := purlType(p.Identifier.PURL)
+			if typ == "" {
+				typ = trivyTypes[result.Type]
+			}
+			stats.Packages = append(stats.Packages, types.PackageSummary{
+				Name:     p.Name,
+				Version:  p.Version,
+				Type:     typ,
+				Licenses: p.Licenses,
+				PURL:     p.Identifier.PURL,
+			})
+		}
+
+		for _, v := range result.Vulnerabilities {
+			typ := purlType(v.PkgIdentifier.PURL)
+			if typ == "" {
+				typ = trivyTypes[result.Type]
+			}
+			vuln := types.Vulnerability{
+				ID:          v.VulnerabilityID,
+				Severity:    normalizeSeverity(v.Severity),
+				Package:     v.PkgName,
+				Version:     v.InstalledVersion,
+				FixState:    trivyFixStates[v.Status],
+				DataSource:  v.PrimaryURL,
+				Description: v.Description,
+				PackageType: typ,
+				Location:    v.PkgPath,
+			}
+			if vuln.Description == "" {
+				vuln.Description = v.Title
+			}
+			if v.FixedVersion != "" {
+				vuln.FixState = types.FixStateFixed
+				for _, fixed := range strings.Split(v.FixedVersion, ",") {
+					vuln.FixedInVersions = append(vuln.FixedInVersions, strings.TrimSpace(fixed))
+				}
+			}
+			if vuln.FixState == "" {
+				vuln.FixState = types.FixStateUnknown
+			}
+
+			// NVD scores are preferred; otherwise the first vendor by name.
+			sources := make([]string, 0, len(v.CVSS))
+			for source := range v.CVSS {
+				sources = append(sources, source)
+			}
+			sort.Slice(sources, func(i, j int) bool {
+				if (sources[i] == "nvd") != (sources[j] == "nvd") {
+					return sources[i] == "nvd"
+				}
+				return sources[i] < sources[j]
+			})
+			for _, source := range sources {
+				if cvss := v.CVSS[source]; cvss.V3Score > 0 {
+					vuln.CVSSScore = cvss.V3Score
+					vuln.CVSSVector = cvss.V3Vector
+					break
+				}
+			}
+			stats.Vulnerabilities = append(stats.Vulnerabilities, vuln)
+		}
+	}
+
+	finishPackages(stats)
+	finishVulnerabilities(stats)
+	return stats, nil
+}
+
+// vulnIDPattern extracts advisory identifiers from SARIF rule IDs such as
+// grype's "CVE-2023-1234-musl".
+var vulnIDPattern = regexp.MustCompile(`(?i)\b(CVE-\d{4}-\d{4,}|GHSA(?:-[0-9a-z]{4}){3})`)
+
+// sarifPackagePatterns extract the package name and version from result
+// messages written by trivy ("Package: musl\nInstalled Version: 1.2.4") and
+// grype ("... package: musl, version 1.2.4 was found ...").
+var sarifPackagePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^Package: (\S+)\s*\nInstalled Version: (\S+)`),
+	regexp.MustCompile(`package: (\S+), version (\S+)`),
+}
+
+// parseSARIFReport parses a SARIF 2.1.0 log. Each vulnerability result (see
+// sarifVulnerability) becomes a vulnerability and other results, such as lint
+// or drift findings, are skipped. Severity comes from the rule's
+// "security-severity" score when present, otherwise from the result level.
+func parseSARIFReport(data []byte) (*types.ImageStats, error) {
+	type sarifText struct {
+		Text string `json:"text"`
+	}
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string    `json:"id"`
+						ShortDescription sarifText `json:"shortDescription"`
+						FullDescription  sarifText `json:"fullDescription"`
+						HelpURI          string    `json:"helpUri"`
+						Properties       struct {
+							SecuritySeverity string   `json:"security-severity"`
+							Tags             []string `json:"tags"`
+						} `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string    `json:"ruleId"`
+				Level     string    `json:"level"`
+				Message   sarifText `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SARIF log: %w", err)
+	}
+
+	stats := &types.ImageStats{Vulnerabilities: make([]types.Vulnerability, 0)}
+	for _, run := range log.Runs {
+		rules := make(map[string]int, len(run.Tool.Driver.Rules))
+		for i, rule := range run.Tool.Driver.Rules {
+			rules[rule.ID] = i
+		}
+
+		for _, res := range run.Results {
+			var tags []string
+			if i, ok := rules[res.RuleID]; ok {
+				tags = run.Tool.Driver.Rules[i].Properties.Tags
+			}
+			if !sarifVulnerability(res.RuleID, tags) {
+				continue
+			}
+			vuln := types.Vulnerability{ID: res.RuleID, FixState: types.FixStateUnknown}
+			if m := vulnIDPattern.FindString(res.RuleID); m != "" {
+				vuln.ID = strings.ToUpper(m)
+			}
+			for _, re := range sarifPackagePatterns {
+				if m := re.FindStringSubmatch(res.Message.Text); m != nil {
+					vuln.Package, vuln.Version = m[1], m[2]
+					break
+				}
+			}
+			if len(res.Locations) > 0 {
+				vuln.Location = res.Locations[0].PhysicalLocation.ArtifactLocation.URI
+			}
+
+			vuln.Severity = sarifLevelSeverity(res.Level)
+			if i, ok := rules[res.RuleID]; ok {
+				rule := run.Tool.Driver.Rules[i]
+				if score, err := strconv.ParseFloat(rule.Properties.SecuritySeverity, 64); err == nil && score > 0 {
+					vuln.Severity = severityFromScore(score)
+					vuln.CVSSScore = score
+				}
+				vuln.Description = rule.FullDescription.Text
+				if vuln.Description == "" {
+					vuln.Description = rule.ShortDescription.Text
+				}
+				vuln.DataSource = rule.HelpURI
+			}
+			if vuln.Description == "" {
+				vuln.Description = res.Message.Text
+			}
+			stats.Vulnerabilities = append(stats.Vulnerabilities, vuln)
+		}
+	}
+
+	finishVulnerabilities(stats)
+	return stats, nil
+}
+
+// sarifVulnerability reports whether a SARIF result describes a vulnerability:
+// its rule ID contains an advisory ID, or its rule is tagged "vulnerability",
+// or "security" without being a misconfiguration or secret (as trivy tags them).
+func sarifVulnerability(ruleID string, tags []string) bool {
+	if vulnIDPattern.MatchString(ruleID) || slices.Contains(tags, "vulnerability") {
+		return true
+	}
+	return slices.Contains(tags, "security") &&
+		!slices.Contains(tags, "misconfiguration") && !slices.Contains(tags, "secret")
+}
+
+// sarifLevelSeverity maps a SARIF result level to a severity.
+func sarifLevelSeverity(level string) string {
+	switch level {
+	case "error":
+		return "High"
+	case "warning":
+		return "Medium"
+	case "note":
+		return "Low"
+	default:
+		return "Unknown"
+	}
+}
@@ -1,5 +1,5 @@
 // Package runner provides concrete implementations for invoking external
-// analysis tools (docker/podman, syft, grype, dive) and parsing their output.
+// analysis tools (container runtimes, syft, grype, dive) and parsing their output.
 package runner
 
 import (
@@ -8,8 +8,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/northcutted/dock-docs/pkg/events"
 	"github.com/northcutted/dock-docs/pkg/installer"
 )
 
@@ -25,6 +29,79 @@ const (
 	TimeoutScan = 5 * time.Minute
 )
 
+// timeoutKey is the context key of a command timeout override.
+type timeoutKey struct{}
+
+// withCommandTimeout returns ctx carrying timeout as the override of the
+// default timeout of every command run with it. Runners pass their
+// configured Timeout this way, so it also reaches the commands of the
+// shared backend and version queries. A timeout that is not positive leaves
+// ctx unchanged.
+func withCommandTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// commandTimeout derives the context of a single command from ctx, bounded
+// by the timeout override carried by ctx or, without one, by def.
+func commandTimeout(ctx context.Context, def time.Duration) (context.Context, context.CancelFunc) {
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && timeout > 0 {
+		def = timeout
+	}
+	return context.WithTimeout(ctx, def)
+}
+
+// argsKey returns the part of a cache key identifying extra command-line
+// arguments, which may change a tool's output.
+func argsKey(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return "\nargs: " + strings.Join(args, " ")
+}
+
+// transientPatterns are error fragments (usually from a tool's stderr) that
+// indicate a failure worth retrying: registry rate limits, unavailable
+// services and network hiccups. Status codes are matched with their status
+// phrase, so digits in digests, sizes or ports do not match.
+var transientPatterns = []string{
+	"too many requests",
+	"toomanyrequests",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway",
+	"connection reset",
+	"i/o timeout",
+	"tls handshake timeout",
+	"temporary failure in name resolution",
+	"unexpected eof",
+}
+
+// IsTransient reports whether err looks like a transient failure (a command
+// timeout, a registry rate limit or a network error) that may succeed when
+// retried. Failures classified as an unreachable daemon, a missing tool or a
+// missing image are never transient, so they fail fast with their hint.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrDaemonUnreachable) || errors.Is(err, ErrToolMissing) || errors.Is(err, ErrImageNotFound) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, p := range transientPatterns {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // lookupTool resolves the path to an external tool binary. It checks
 // the system PATH first and falls back to the dock-docs install
 // directory (~/.dock-docs/bin/).
@@ -44,6 +121,16 @@ func runCommand(cmd *exec.Cmd, verbose bool) ([]byte, error) {
 	// But sometimes tools write useful info to stderr even on success.
 	// Let's capture both if we can, but Output() is easiest for data.
 
+	// Commands created with a context are killed when it ends; note it so
+	// the failure is reported as a timeout rather than a crash.
+	var timedOut atomic.Bool
+	if cancel := cmd.Cancel; cancel != nil {
+		cmd.Cancel = func() error {
+			timedOut.Store(true)
+			return cancel()
+		}
+	}
+
 	output, err := cmd.Output()
 	if err != nil {
 		var stderr []byte
@@ -51,7 +138,8 @@ func runCommand(cmd *exec.Cmd, verbose bool) ([]byte, error) {
 		if errors.As(err, &exitErr) {
 			stderr = exitErr.Stderr
 		}
-		return nil, fmt.Errorf("command failed: %w\nStderr: %s", err, string(stderr))
+		err = fmt.Errorf("command failed: %w\nStderr: %s", err, string(stderr))
+		return nil, classify(cmd, err, string(stderr), timedOut.Load())
 	}
 
 	if verbose {
@@ -62,37 +150,90 @@ func runCommand(cmd *exec.Cmd, verbose bool) ([]byte, error) {
 	return output, nil
 }
 
-// EnsureImage checks if an image exists locally, and pulls it if not.
+// toolVersion runs a version command (e.g., 'syft version') and returns its
+// trimmed output, which identifies the tool build for cache keys.
+func toolVersion(ctx context.Context, binary string, args ...string) (string, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	output, err := runCommand(commandContext(runCtx, binary, args...), false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s version: %w", filepath.Base(binary), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// scanSource returns the source tool must read images from so it scans the
+// copy EnsureImage made available (see Backend.ScanSource). Without a usable
+// backend the source is empty and the scanner resolves images itself.
+func scanSource(tool string) (string, error) {
+	b, err := CurrentBackend()
+	if err != nil {
+		return "", nil
+	}
+	return b.ScanSource(tool)
+}
+
+// scanImage returns the image argument of syft or grype: image prefixed with
+// its scan source, e.g. "podman:app:1".
+func scanImage(tool, image string) (string, error) {
+	source, err := scanSource(tool)
+	if err != nil || source == "" {
+		return image, err
+	}
+	return source + ":" + image, nil
+}
+
+// ImageID returns the local image ID of image (the digest of its config).
+// Identical image content always has the same ID, regardless of tag.
+func ImageID(ctx context.Context, image string, verbose bool) (string, error) {
+	b, err := CurrentBackend()
+	if err != nil {
+		return "", err
+	}
+	id, err := b.ImageID(ctx, image, verbose)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image ID of %s: %w", image, err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("empty image ID for %s", image)
+	}
+	return id, nil
+}
+
+// EnsureImage makes image available locally according to the pull policy
+// (see SetPullOptions): by default it pulls the image only when it is missing.
 // The provided context is used as the parent for command timeouts.
 func EnsureImage(ctx context.Context, image string, verbose bool) error {
-	// Detect which container runtime is available
-	binary := ""
-	if _, err := exec.LookPath("docker"); err == nil {
-		binary = "docker"
-	} else if _, err := exec.LookPath("podman"); err == nil {
-		binary = "podman"
-	} else {
-		return fmt.Errorf("no container runtime found (docker or podman)")
-	}
-
-	// Check if image exists
-	inspectCtx, cancel := context.WithTimeout(ctx, TimeoutInspect)
-	defer cancel()
-	checkCmd := exec.CommandContext(inspectCtx, binary, "inspect", "--type=image", image)
-	if err := checkCmd.Run(); err == nil {
+	b, err := CurrentBackend()
+	if err != nil {
+		return err
+	}
+	opts := currentPullOptions()
+
+	if opts.Policy != PullAlways && b.Exists(ctx, image, verbose) {
 		if verbose {
-			slog.Debug("image found locally", "image", image)
+			slog.Debug("image found locally", "image", image, "runtime", b.Name())
 		}
 		return nil
 	}
+	if opts.Policy == PullNever {
+		return &Error{Kind: ErrImageNotFound, Tool: b.Name(), Err: fmt.Errorf("image %s is not available locally and the pull policy is %q", image, PullNever)}
+	}
 
-	// Image not found, pull it (pull can be slow, use scan timeout)
-	slog.Info("pulling image", "image", image)
-	pullCtx, pullCancel := context.WithTimeout(ctx, TimeoutScan)
-	defer pullCancel()
-	pullCmd := exec.CommandContext(pullCtx, binary, "pull", image)
-	if _, err := runCommand(pullCmd, verbose); err != nil {
-		return fmt.Errorf("failed to pull image %s: %w", image, err)
+	platform := pullPlatform(image, opts)
+	attrs := []any{"image", image, "runtime", b.Name()}
+	if platform != "" {
+		attrs = append(attrs, "platform", platform)
+	}
+	slog.Info("pulling image", attrs...)
+	events.Emit(ctx, events.Event{Kind: events.PullStarted, Image: image})
+	start := time.Now()
+	err = b.Pull(ctx, image, platform, verbose)
+	pulled := events.Event{Kind: events.ImagePulled, Image: image, Duration: time.Since(start).Round(time.Millisecond)}
+	if err != nil {
+		err = fmt.Errorf("failed to pull image %s: %w", image, err)
+		pulled.Error = err.Error()
 	}
-	return nil
+	events.Emit(ctx, pulled)
+	return err
 }
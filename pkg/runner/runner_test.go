@@ -3,13 +3,20 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/northcutted/dock-docs/pkg/events"
+	"github.com/northcutted/dock-docs/pkg/sbom"
+	"github.com/northcutted/dock-docs/pkg/types"
 )
 
 // fakeExecHelper is a test helper function used by TestHelperProcess.
@@ -30,6 +37,13 @@ func TestHelperProcess(t *testing.T) {
 		fmt.Print(`{"manifests":[{"platform":{"architecture":"amd64","os":"linux"}}]}`)
 	case "syft-ok":
 		fmt.Print(`{"distro":{"name":"alpine","version":"3.18"},"artifacts":[{"name":"musl","version":"1.2","type":"apk"}]}`)
+	case "syft-args":
+		// Succeeds only when the extra arguments follow the defaults.
+		if !strings.HasSuffix(strings.Join(os.Args, " "), "-o json --exclude /tmp") {
+			fmt.Fprint(os.Stderr, "missing extra args: ", os.Args)
+			os.Exit(1)
+		}
+		fmt.Print(`{"artifacts":[]}`)
 	case "grype-ok":
 		fmt.Print(`{"descriptor":{"timestamp":"2024-01-01T00:00:00Z"},"matches":[{"vulnerability":{"id":"CVE-1","severity":"High"},"artifact":{"name":"pkg","version":"1.0"}}]}`)
 	case "dive-ok":
@@ -46,13 +60,62 @@ func TestHelperProcess(t *testing.T) {
 				break
 			}
 		}
+	case "plugin-ok":
+		// Echo the requested image back as a custom field
+		var req PluginRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			fmt.Fprintf(os.Stderr, "bad request: %v", err)
+			os.Exit(1)
+		}
+		fmt.Printf(`{"osDistro":"plugin-os","custom":{"image":%q,"apiVersion":%q,"level":%v}}`,
+			req.Image, req.APIVersion, req.Options["level"])
+	case "podman-ok":
+		args := strings.Join(os.Args, " ")
+		switch {
+		case strings.Contains(args, "connection list"):
+			fmt.Print(`[{"Name":"local","URI":"unix:///run/podman/podman.sock"},` +
+				`{"Name":"podman-machine-default","URI":"ssh://core@127.0.0.1:5000/run/podman.sock","Default":true}]`)
+		case strings.Contains(args, "machine inspect podman-machine-default"):
+			fmt.Print(`[{"ConnectionInfo":{"PodmanSocket":{"Path":"/tmp/machine.sock"}}}]`)
+		default:
+			os.Exit(1)
+		}
 	case "pull-ok":
 		fmt.Print("pulled successfully")
+	case "skopeo-ok":
+		// Nothing is in containers-storage, so every image is read from the registry.
+		args := strings.Join(os.Args, " ")
+		switch {
+		case strings.Contains(args, " copy "):
+			fmt.Print("copied")
+		case strings.Contains(args, "containers-storage:"):
+			os.Exit(1)
+		case strings.Contains(args, "--config"):
+			fmt.Print(skopeoConfig)
+		case strings.Contains(args, "--raw"):
+			fmt.Print(`{"manifests":[{"digest":"sha256:a","platform":{"architecture":"arm64","os":"linux"}}]}`)
+		default:
+			fmt.Print(`{"Name":"docker.io/library/app","Digest":"sha256:d","LayersData":[{"Size":100},{"Size":50}]}`)
+		}
 	case "echo":
 		fmt.Print("ok")
 	case "error":
 		fmt.Fprint(os.Stderr, "something went wrong")
 		os.Exit(1)
+	case "no-such-image":
+		fmt.Fprint(os.Stderr, "Error response from daemon: No such image: app:1")
+		os.Exit(1)
+	case "pull-denied":
+		fmt.Fprint(os.Stderr, "Error response from daemon: pull access denied for app, repository does not exist or may require 'docker login'")
+		os.Exit(1)
+	case "unauthorized":
+		fmt.Fprint(os.Stderr, "Error: unauthorized: authentication required")
+		os.Exit(1)
+	case "daemon-down":
+		fmt.Fprint(os.Stderr, "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?")
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(10 * time.Second)
 	default:
 		fmt.Fprintf(os.Stderr, "unknown helper cmd: %s\n", cmd)
 		os.Exit(2)
@@ -60,6 +123,11 @@ func TestHelperProcess(t *testing.T) {
 	os.Exit(0)
 }
 
+// skopeoConfig is the raw image config printed by the skopeo-ok helper.
+const skopeoConfig = `{"created":"2024-01-02T03:04:05Z","architecture":"arm64","os":"linux",` +
+	`"config":{"User":"app","Env":["A=1"],"ExposedPorts":{"8080/tcp":{}},"Healthcheck":{"Test":["CMD","true"],"Retries":3}},` +
+	`"rootfs":{"type":"layers","diff_ids":["sha256:1","sha256:2"]}}`
+
 // helperCmd builds an exec.Cmd that re-invokes the test binary as a
 // subprocess, setting GO_WANT_HELPER_PROCESS=1 so TestHelperProcess
 // runs the scenario identified by helperCmd.
@@ -136,18 +204,18 @@ func TestRunCommand_FailureVerbose(t *testing.T) {
 	}
 }
 
-// TestRuntimeRunner_IsAvailable tests IsAvailable with system docker/podman.
+// TestRuntimeRunner_IsAvailable tests IsAvailable with the system runtimes.
 func TestRuntimeRunner_IsAvailable(t *testing.T) {
 	r := &RuntimeRunner{}
 	result := r.IsAvailable()
-	// On any dev machine with docker or podman, this should be true.
-	// We just verify it doesn't panic and sets binary appropriately.
+	// On any dev machine with a container runtime, this should be true.
+	// We just verify it doesn't panic and sets the backend appropriately.
 	if result {
-		if r.binary != "docker" && r.binary != "podman" {
-			t.Errorf("IsAvailable() set binary to %q, expected docker or podman", r.binary)
+		if !slices.Contains(Backends, r.backend.Name()) {
+			t.Errorf("IsAvailable() selected backend %q, expected one of %v", r.backend.Name(), Backends)
 		}
 	}
-	// If neither is installed, result is false - that's fine too.
+	// If none is installed, result is false - that's fine too.
 }
 
 // TestRuntimeRunner_Run tests RuntimeRunner.Run with a mock binary.
@@ -155,7 +223,7 @@ func TestRuntimeRunner_Run(t *testing.T) {
 	dir := t.TempDir()
 	fakeBin := createFakeBinary(t, dir, "docker", "inspect-ok")
 
-	r := &RuntimeRunner{binary: fakeBin}
+	r := &RuntimeRunner{backend: &cliBackend{name: "docker", binary: fakeBin}}
 	stats, err := r.Run(context.Background(), "test:latest", false)
 	if err != nil {
 		t.Fatalf("RuntimeRunner.Run() error: %v", err)
@@ -171,9 +239,9 @@ func TestRuntimeRunner_Run(t *testing.T) {
 	}
 }
 
-// TestRuntimeRunner_Run_NoBinary tests RuntimeRunner.Run when binary is empty and IsAvailable fails.
+// TestRuntimeRunner_Run_NoBinary tests RuntimeRunner.Run when no backend is set and none is available.
 func TestRuntimeRunner_Run_NoBinary(t *testing.T) {
-	// Override PATH to ensure neither docker nor podman is found
+	// Override PATH to ensure no container runtime is found
 	t.Setenv("PATH", t.TempDir())
 	r := &RuntimeRunner{}
 	_, err := r.Run(context.Background(), "test:latest", false)
@@ -190,8 +258,8 @@ func TestManifestRunner_IsAvailable(t *testing.T) {
 	r := &ManifestRunner{}
 	result := r.IsAvailable()
 	if result {
-		if r.binary != "docker" && r.binary != "podman" {
-			t.Errorf("IsAvailable() set binary to %q, expected docker or podman", r.binary)
+		if !slices.Contains(Backends, r.backend.Name()) {
+			t.Errorf("IsAvailable() selected backend %q, expected one of %v", r.backend.Name(), Backends)
 		}
 	}
 }
@@ -201,7 +269,7 @@ func TestManifestRunner_Run(t *testing.T) {
 	dir := t.TempDir()
 	fakeBin := createFakeBinary(t, dir, "docker", "manifest-ok")
 
-	r := &ManifestRunner{binary: fakeBin}
+	r := &ManifestRunner{backend: &cliBackend{name: "docker", binary: fakeBin}}
 	stats, err := r.Run(context.Background(), "test:latest", false)
 	if err != nil {
 		t.Fatalf("ManifestRunner.Run() error: %v", err)
@@ -211,7 +279,7 @@ func TestManifestRunner_Run(t *testing.T) {
 	}
 }
 
-// TestManifestRunner_Run_NoBinary tests ManifestRunner.Run without binary.
+// TestManifestRunner_Run_NoBinary tests ManifestRunner.Run without a container runtime.
 func TestManifestRunner_Run_NoBinary(t *testing.T) {
 	t.Setenv("PATH", t.TempDir())
 	r := &ManifestRunner{}
@@ -471,13 +539,570 @@ func TestEnsureImage_NoRuntime(t *testing.T) {
 	}
 }
 
-// TestDetectPodmanSocket tests the detectPodmanSocket helper function.
-func TestDetectPodmanSocket(t *testing.T) {
-	// When podman is not in PATH, should return empty string.
+// TestImageID tests ImageID with a fake runtime.
+func TestImageID(t *testing.T) {
+	dir := t.TempDir()
+	createFakeBinary(t, dir, "docker", "echo")
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+
+	id, err := ImageID(context.Background(), "test:latest", false)
+	if err != nil {
+		t.Fatalf("ImageID() error: %v", err)
+	}
+	if id != "ok" {
+		t.Errorf("ImageID() = %q, want ok", id)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := ImageID(context.Background(), "test:latest", false); err == nil {
+		t.Error("ImageID() expected error without a container runtime")
+	}
+}
+
+// TestNewBackend tests backend selection by name.
+func TestNewBackend(t *testing.T) {
+	for _, name := range Backends {
+		b, err := NewBackend(name)
+		if err != nil {
+			t.Fatalf("NewBackend(%q) error: %v", name, err)
+		}
+		if b.Name() != name {
+			t.Errorf("NewBackend(%q).Name() = %q", name, b.Name())
+		}
+	}
+	if _, err := NewBackend("lxc"); err == nil || !strings.Contains(err.Error(), "unknown container runtime") {
+		t.Errorf("NewBackend(lxc) error = %v, want unknown container runtime", err)
+	}
+}
+
+// TestCurrentBackend tests explicit selection and automatic detection.
+func TestCurrentBackend(t *testing.T) {
+	defer SetBackend(nil)
+
+	fake := &FakeBackend{}
+	SetBackend(fake)
+	if b, err := CurrentBackend(); err != nil || b != fake {
+		t.Errorf("CurrentBackend() = (%v, %v), want the selected backend", b, err)
+	}
+
+	// Only nerdctl is installed: it is detected after docker and podman.
+	SetBackend(nil)
+	dir := t.TempDir()
+	createFakeBinary(t, dir, "nerdctl", "echo")
+	t.Setenv("PATH", dir)
+	b, err := CurrentBackend()
+	if err != nil || b.Name() != BackendNerdctl {
+		t.Errorf("CurrentBackend() = (%v, %v), want nerdctl", b, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := CurrentBackend(); err == nil {
+		t.Error("CurrentBackend() expected error without a container runtime")
+	}
+}
+
+// TestEnsureImage_Pull tests that EnsureImage pulls only missing images.
+func TestEnsureImage_Pull(t *testing.T) {
+	defer SetBackend(nil)
+	fake := &FakeBackend{
+		Images: map[string]*types.ImageStats{"app:1": {}, "app:2": {}},
+		Local:  map[string]bool{"app:1": true},
+	}
+	SetBackend(fake)
+
+	for _, image := range []string{"app:1", "app:2", "app:2"} {
+		if err := EnsureImage(context.Background(), image, false); err != nil {
+			t.Fatalf("EnsureImage(%s) error: %v", image, err)
+		}
+	}
+	if got := fake.Pulls(); !reflect.DeepEqual(got, []string{"app:2"}) {
+		t.Errorf("pulls = %v, want [app:2]", got)
+	}
+
+	fake.PullErr = fmt.Errorf("denied")
+	if err := EnsureImage(context.Background(), "app:3", false); err == nil || !strings.Contains(err.Error(), "failed to pull image app:3") {
+		t.Errorf("EnsureImage() error = %v, want pull failure", err)
+	}
+}
+
+// TestEnsureImage_PullPolicy tests the pull policies and platform selection.
+func TestEnsureImage_PullPolicy(t *testing.T) {
+	defer SetBackend(nil)
+	defer func() { _ = SetPullOptions(PullOptions{}) }()
+	digestRef := "app@sha256:abc"
+
+	tests := []struct {
+		name          string
+		opts          PullOptions
+		image         string
+		wantPulls     []string
+		wantPlatforms []string
+		wantErr       string
+	}{
+		{name: "if-not-present skips local images", opts: PullOptions{Policy: PullIfNotPresent}, image: "app:local"},
+		{name: "if-not-present pulls missing images", opts: PullOptions{}, image: "app:remote", wantPulls: []string{"app:remote"}, wantPlatforms: []string{""}},
+		{name: "always pulls local images", opts: PullOptions{Policy: PullAlways}, image: "app:local", wantPulls: []string{"app:local"}, wantPlatforms: []string{""}},
+		{name: "never uses local images", opts: PullOptions{Policy: PullNever}, image: "app:local"},
+		{name: "never fails for missing images", opts: PullOptions{Policy: PullNever}, image: "app:remote", wantErr: "pull policy is \"never\""},
+		{name: "platform", opts: PullOptions{Platform: "linux/arm64"}, image: "app:remote", wantPulls: []string{"app:remote"}, wantPlatforms: []string{"linux/arm64"}},
+		{name: "digest references ignore the platform", opts: PullOptions{Platform: "linux/arm64"}, image: digestRef, wantPulls: []string{digestRef}, wantPlatforms: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &FakeBackend{
+				Images: map[string]*types.ImageStats{"app:local": {}, "app:remote": {}, digestRef: {}},
+				Local:  map[string]bool{"app:local": true},
+			}
+			SetBackend(fake)
+			if err := SetPullOptions(tt.opts); err != nil {
+				t.Fatalf("SetPullOptions() error: %v", err)
+			}
+			var kinds []events.Kind
+			ctx := events.WithHandler(context.Background(), func(e events.Event) { kinds = append(kinds, e.Kind) })
+			err := EnsureImage(ctx, tt.image, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("EnsureImage() error = %v, want %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, ErrImageNotFound) {
+					t.Errorf("EnsureImage() error = %v, want ErrImageNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureImage() error: %v", err)
+			}
+			if got := fake.Pulls(); !reflect.DeepEqual(got, tt.wantPulls) {
+				t.Errorf("pulls = %v, want %v", got, tt.wantPulls)
+			}
+			if len(tt.wantPulls) > 0 && !reflect.DeepEqual(kinds, []events.Kind{events.PullStarted, events.ImagePulled}) {
+				t.Errorf("events = %v, want pull started and image pulled", kinds)
+			}
+			if got := fake.PullPlatforms(); !reflect.DeepEqual(got, tt.wantPlatforms) {
+				t.Errorf("platforms = %v, want %v", got, tt.wantPlatforms)
+			}
+		})
+	}
+}
+
+func TestSetPullOptions_Invalid(t *testing.T) {
+	defer func() { _ = SetPullOptions(PullOptions{}) }()
+	for _, opts := range []PullOptions{{Policy: "sometimes"}, {Platform: "linux"}, {Platform: "linux/"}, {Platform: "a/b/c/d"}} {
+		if err := SetPullOptions(opts); err == nil {
+			t.Errorf("SetPullOptions(%+v) expected error", opts)
+		}
+	}
+}
+
+// TestSetCredentials tests the docker config handed to tools for each
+// credential source.
+func TestSetCredentials(t *testing.T) {
+	defer func() { _, _ = SetCredentials(Credentials{}) }()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"auths":{"ghcr.io":{"auth":"b2xkOm9sZA=="}},"credsStore":"desktop","credHelpers":{"ghcr.io":"gh","gcr.io":"gcloud"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	custom := filepath.Join(dir, "ci-config.json")
+	if err := os.WriteFile(custom, []byte(`{"auths":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// authFile returns the config file tools are pointed at, and its content.
+	authFile := func(t *testing.T) (string, map[string]any) {
+		t.Helper()
+		env := commandContext(context.Background(), "true").Env
+		path := ""
+		for _, v := range env {
+			if p, ok := strings.CutPrefix(v, "REGISTRY_AUTH_FILE="); ok {
+				path = p
+			}
+		}
+		if path == "" {
+			return "", nil
+		}
+		if !slices.Contains(env, "DOCKER_CONFIG="+filepath.Dir(path)) {
+			t.Errorf("DOCKER_CONFIG does not point at %s", filepath.Dir(path))
+		}
+		var config map[string]any
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			t.Fatal(err)
+		}
+		return path, config
+	}
+
+	t.Run("no credentials", func(t *testing.T) {
+		cleanup, err := SetCredentials(Credentials{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		if path, _ := authFile(t); path != "" {
+			t.Errorf("auth file = %q, want none", path)
+		}
+	})
+
+	t.Run("config directory used as is", func(t *testing.T) {
+		cleanup, err := SetCredentials(Credentials{DockerConfig: dir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		if path, _ := authFile(t); path != filepath.Join(dir, "config.json") {
+			t.Errorf("auth file = %q, want the configured file", path)
+		}
+	})
+
+	t.Run("config file copied to config.json", func(t *testing.T) {
+		cleanup, err := SetCredentials(Credentials{DockerConfig: custom})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, config := authFile(t)
+		if filepath.Base(path) != "config.json" || config["auths"] == nil {
+			t.Errorf("auth file = %q (%v), want a config.json copy", path, config)
+		}
+		cleanup()
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("cleanup did not remove %s", path)
+		}
+	})
+
+	t.Run("username and password", func(t *testing.T) {
+		cleanup, err := SetCredentials(Credentials{DockerConfig: dir, Registry: "ghcr.io", Username: "bot", Password: "s3cret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		path, config := authFile(t)
+		if path == filepath.Join(dir, "config.json") {
+			t.Fatal("the configured file must not be modified")
+		}
+		auths := config["auths"].(map[string]any)
+		if got := auths["ghcr.io"].(map[string]any)["auth"]; got != "Ym90OnMzY3JldA==" {
+			t.Errorf("ghcr.io auth = %v, want bot:s3cret", got)
+		}
+		if _, ok := config["credsStore"]; ok {
+			t.Error("credsStore should be removed")
+		}
+		if helpers := config["credHelpers"].(map[string]any); helpers["ghcr.io"] != nil || helpers["gcr.io"] != "gcloud" {
+			t.Errorf("credHelpers = %v, want only ghcr.io removed", helpers)
+		}
+	})
+
+	t.Run("docker hub by default", func(t *testing.T) {
+		cleanup, err := SetCredentials(Credentials{Username: "bot", Password: "s3cret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		_, config := authFile(t)
+		if _, ok := config["auths"].(map[string]any)[dockerHubAuthKey]; !ok {
+			t.Errorf("auths = %v, want a Docker Hub entry", config["auths"])
+		}
+	})
+
+	for _, c := range []Credentials{
+		{Username: "bot"},
+		{DockerConfig: filepath.Join(dir, "missing.json")},
+	} {
+		if _, err := SetCredentials(c); err == nil {
+			t.Errorf("SetCredentials(%+v) expected error", c)
+		}
+	}
+}
+
+// TestFakeBackend_Runners tests the runtime and manifest runners and
+// ListPlatforms against the fake backend.
+func TestFakeBackend_Runners(t *testing.T) {
+	defer SetBackend(nil)
+	SetBackend(&FakeBackend{
+		Images:    map[string]*types.ImageStats{"app:1": {ImageID: "sha256:abc", Architecture: "amd64"}},
+		Manifests: map[string][]byte{"app:1": []byte(`{"manifests":[{"digest":"sha256:a","platform":{"architecture":"amd64","os":"linux"}}]}`)},
+	})
+	ctx := context.Background()
+
+	stats, err := (&RuntimeRunner{}).Run(ctx, "app:1", false)
+	if err != nil || stats.Architecture != "amd64" || stats.ImageTag != "app:1" {
+		t.Errorf("RuntimeRunner.Run() = (%+v, %v)", stats, err)
+	}
+	if id, err := ImageID(ctx, "app:1", false); err != nil || id != "sha256:abc" {
+		t.Errorf("ImageID() = (%q, %v), want sha256:abc", id, err)
+	}
+	stats, err = (&ManifestRunner{}).Run(ctx, "app:1", false)
+	if err != nil || !reflect.DeepEqual(stats.SupportedArchitectures, []string{"linux/amd64"}) {
+		t.Errorf("ManifestRunner.Run() = (%+v, %v)", stats, err)
+	}
+	platforms, err := ListPlatforms(ctx, "app:1", false)
+	if err != nil || len(platforms) != 1 || platforms[0].Digest != "sha256:a" {
+		t.Errorf("ListPlatforms() = (%v, %v)", platforms, err)
+	}
+	if _, err := (&ManifestRunner{}).Run(ctx, "app:2", false); err == nil {
+		t.Error("ManifestRunner.Run() expected error for an unknown image")
+	}
+}
+
+// TestSkopeoBackend tests inspecting a registry image with skopeo.
+func TestSkopeoBackend(t *testing.T) {
+	dir := t.TempDir()
+	b := &skopeoBackend{binary: createFakeBinary(t, dir, "skopeo", "skopeo-ok")}
+	ctx := context.Background()
+
+	if b.Exists(ctx, "app:1", false) {
+		t.Error("Exists() = true, want false")
+	}
+	stats, err := b.Inspect(ctx, "app:1", false)
+	if err != nil {
+		t.Fatalf("Inspect() error: %v", err)
+	}
+	want := &types.ImageStats{
+		ImageTag:     "app:1",
+		RepoDigest:   "docker.io/library/app@sha256:d",
+		ImageID:      configDigest([]byte(skopeoConfig)),
+		Architecture: "arm64",
+		OS:           "linux",
+		SizeBytes:    150,
+		TotalLayers:  2,
+		Created:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Config: &types.ImageConfig{
+			User:         "app",
+			Env:          []string{"A=1"},
+			ExposedPorts: []string{"8080/tcp"},
+			Healthcheck:  &types.Healthcheck{Test: []string{"CMD", "true"}, Retries: 3},
+		},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Inspect() = %+v, want %+v", stats, want)
+	}
+
+	id, err := b.ImageID(ctx, "app:1", false)
+	if err != nil || id != want.ImageID {
+		t.Errorf("ImageID() = (%q, %v), want %q", id, err, want.ImageID)
+	}
+	manifest, err := b.Manifest(ctx, "app:1", false)
+	if err != nil || len(parseManifestPlatforms(manifest)) != 1 {
+		t.Errorf("Manifest() = (%s, %v)", manifest, err)
+	}
+	if err := b.Pull(ctx, "app:1", "", false); err != nil {
+		t.Errorf("Pull() error: %v", err)
+	}
+	if err := b.Pull(ctx, "app:1", "linux/arm/v7", false); err != nil {
+		t.Errorf("Pull() with platform error: %v", err)
+	}
+
+	if _, err := parseSkopeoInspect([]byte("{}"), []byte("not json"), "app:1"); err == nil {
+		t.Error("parseSkopeoInspect() expected error for an invalid config")
+	}
+}
+
+// TestCacheKey tests the tool version keys used by the analysis cache.
+func TestCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		runner interface {
+			CacheKey(ctx context.Context) (string, error)
+		}
+		want string
+	}{
+		{&SyftRunner{binary: createFakeBinary(t, dir, "syft", "echo")}, "ok"},
+		// grype keys include the vulnerability database status.
+		{&GrypeRunner{binary: createFakeBinary(t, dir, "grype", "echo")}, "ok\nok"},
+		{&DiveRunner{binary: createFakeBinary(t, dir, "dive", "echo")}, "ok"},
+	}
+	for _, tt := range tests {
+		got, err := tt.runner.CacheKey(context.Background())
+		if err != nil || got != tt.want {
+			t.Errorf("%T.CacheKey() = (%q, %v), want %q", tt.runner, got, err, tt.want)
+		}
+	}
+
+	t.Setenv("GO_TEST_HELPER_CMD", "error")
+	if _, err := (&SyftRunner{binary: filepath.Join(dir, "syft")}).CacheKey(context.Background()); err == nil {
+		t.Error("CacheKey() expected error when the version command fails")
+	}
+}
+
+// TestSetConnection tests the environment applied to commands for each
+// connection setting.
+func TestSetConnection(t *testing.T) {
+	defer func() { _ = SetConnection(context.Background(), Connection{}) }()
 	t.Setenv("PATH", t.TempDir())
-	result := detectPodmanSocket(context.Background())
-	if result != "" {
-		t.Errorf("detectPodmanSocket(context.Background()) = %q, want empty string", result)
+
+	tests := []struct {
+		name string
+		conn Connection
+		want []string
+	}{
+		{name: "inherited environment", conn: Connection{}, want: nil},
+		{
+			name: "host with TLS",
+			conn: Connection{Host: "tcp://build:2376", TLSVerify: true, CertPath: "/certs"},
+			want: []string{"DOCKER_HOST=tcp://build:2376", "CONTAINER_HOST=tcp://build:2376", "DOCKER_TLS_VERIFY=1", "DOCKER_CERT_PATH=/certs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetConnection(context.Background(), tt.conn); err != nil {
+				t.Fatalf("SetConnection() error: %v", err)
+			}
+			cmd := commandContext(context.Background(), "true")
+			if tt.want == nil {
+				if cmd.Env != nil {
+					t.Errorf("Env = %v, want inherited environment", cmd.Env)
+				}
+				return
+			}
+			if got := cmd.Env[len(cmd.Env)-len(tt.want):]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Env suffix = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := SetConnection(context.Background(), Connection{Context: "remote"}); err == nil {
+		t.Error("SetConnection() expected error without docker")
+	}
+	if err := SetConnection(context.Background(), Connection{PodmanConnection: "remote"}); err == nil {
+		t.Error("SetConnection() expected error without podman")
+	}
+}
+
+// TestSetConnection_Podman tests resolving podman connections with a fake
+// podman binary.
+func TestSetConnection_Podman(t *testing.T) {
+	defer SetBackend(nil)
+	defer func() { _ = SetConnection(context.Background(), Connection{}) }()
+	dir := t.TempDir()
+	createFakeBinary(t, dir, "podman", "podman-ok")
+	t.Setenv("PATH", dir)
+	t.Setenv("DOCKER_HOST", "")
+
+	// The default connection of a podman machine uses its local socket.
+	SetBackend(&cliBackend{name: BackendPodman, binary: "podman"})
+	if err := SetConnection(context.Background(), Connection{}); err != nil {
+		t.Fatalf("SetConnection() error: %v", err)
+	}
+	env := commandContext(context.Background(), "true").Env
+	if got := env[len(env)-1]; got != "DOCKER_HOST=unix:///tmp/machine.sock" {
+		t.Errorf("DOCKER_HOST = %q, want the machine socket", got)
+	}
+
+	// A named unix connection is used as is.
+	if err := SetConnection(context.Background(), Connection{PodmanConnection: "local"}); err != nil {
+		t.Fatalf("SetConnection() error: %v", err)
+	}
+	env = commandContext(context.Background(), "true").Env
+	want := []string{"CONTAINER_CONNECTION=local", "DOCKER_HOST=unix:///run/podman/podman.sock"}
+	if got := env[len(env)-2:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Env suffix = %v, want %v", got, want)
+	}
+
+	if err := SetConnection(context.Background(), Connection{PodmanConnection: "missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("SetConnection() error = %v, want not found", err)
+	}
+}
+
+func TestParseDockerContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "plain endpoint",
+			json: `[{"Endpoints":{"docker":{"Host":"ssh://builder"}},"TLSMaterial":{},"Storage":{"TLSPath":"/ctx/tls/abc"}}]`,
+			want: []string{"DOCKER_HOST=ssh://builder"},
+		},
+		{
+			name: "TLS endpoint",
+			json: `[{"Endpoints":{"docker":{"Host":"tcp://build:2376"}},"TLSMaterial":{"docker":["ca.pem","cert.pem","key.pem"]},"Storage":{"TLSPath":"/ctx/tls/abc"}}]`,
+			want: []string{"DOCKER_HOST=tcp://build:2376", "DOCKER_CERT_PATH=/ctx/tls/abc/docker", "DOCKER_TLS_VERIFY=1"},
+		},
+		{
+			name: "TLS without verification",
+			json: `[{"Endpoints":{"docker":{"Host":"tcp://build:2376","SkipTLSVerify":true}},"TLSMaterial":{"docker":["ca.pem"]},"Storage":{"TLSPath":"/ctx/tls/abc"}}]`,
+			want: []string{"DOCKER_HOST=tcp://build:2376", "DOCKER_CERT_PATH=/ctx/tls/abc/docker"},
+		},
+		{name: "no endpoint", json: `[{}]`, wantErr: true},
+		{name: "invalid JSON", json: `nope`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDockerContext([]byte(tt.json), "ctx")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDockerContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDockerContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPodmanConnection(t *testing.T) {
+	list := `[{"Name":"podman-machine-default","URI":"ssh://core@127.0.0.1:5000/run/podman.sock","Default":false},` +
+		`{"Name":"podman-machine-default-root","URI":"ssh://root@127.0.0.1:5000/run/podman.sock","Default":true}]`
+	tests := []struct {
+		name     string
+		json     string
+		conn     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "default connection", json: list, wantName: "podman-machine-default-root"},
+		{name: "named connection", json: list, conn: "podman-machine-default", wantName: "podman-machine-default"},
+		{name: "first without default", json: `[{"Name":"a"},{"Name":"b"}]`, wantName: "a"},
+		{name: "no connections", json: `[]`},
+		{name: "unknown name", json: list, conn: "other", wantErr: true},
+		{name: "invalid JSON", json: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findPodmanConnection([]byte(tt.json), tt.conn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findPodmanConnection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.wantName {
+				t.Errorf("findPodmanConnection() = %q, want %q", name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseMachineSocket(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "socket path", json: `[{"ConnectionInfo":{"PodmanSocket":{"Path":"/var/run/podman.sock"}}}]`, want: "unix:///var/run/podman.sock"},
+		{name: "no socket", json: `[{"ConnectionInfo":{}}]`, want: ""},
+		{name: "no machines", json: `[]`, want: ""},
+		{name: "invalid JSON", json: `x`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMachineSocket([]byte(tt.json)); got != tt.want {
+				t.Errorf("parseMachineSocket() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePodmanInfoSocket(t *testing.T) {
+	got, err := parsePodmanInfoSocket([]byte(`{"host":{"remoteSocket":{"path":"/run/user/1000/podman/podman.sock","exists":true}}}`))
+	if err != nil || got != "unix:///run/user/1000/podman/podman.sock" {
+		t.Errorf("parsePodmanInfoSocket() = (%q, %v)", got, err)
+	}
+	if _, err := parsePodmanInfoSocket([]byte(`{"host":{"remoteSocket":{"path":"/run/podman.sock"}}}`)); err == nil {
+		t.Error("parsePodmanInfoSocket() expected error for an inactive socket")
 	}
 }
 
@@ -502,29 +1127,34 @@ func TestLookupTool_Swappable(t *testing.T) {
 func TestRuntimeRunner_Name(t *testing.T) {
 	tests := []struct {
 		name     string
-		binary   string
+		backend  Backend
 		expected string
 	}{
 		{
 			name:     "default name",
-			binary:   "",
+			backend:  nil,
 			expected: "runtime",
 		},
 		{
-			name:     "docker binary",
-			binary:   "docker",
+			name:     "docker backend",
+			backend:  &cliBackend{name: "docker", binary: "docker"},
 			expected: "docker",
 		},
 		{
-			name:     "podman binary",
-			binary:   "podman",
+			name:     "podman backend",
+			backend:  &cliBackend{name: "podman", binary: "podman"},
 			expected: "podman",
 		},
+		{
+			name:     "buildah backend",
+			backend:  &skopeoBackend{binary: "skopeo", builder: "buildah"},
+			expected: "buildah",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &RuntimeRunner{binary: tt.binary}
+			r := &RuntimeRunner{backend: tt.backend}
 			if got := r.Name(); got != tt.expected {
 				t.Errorf("RuntimeRunner.Name() = %v, want %v", got, tt.expected)
 			}
@@ -1443,6 +2073,63 @@ func TestParseSyftOutput_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestParseSyftOutput_PackageMetadata(t *testing.T) {
+	output := `{
+		"artifacts": [
+			{
+				"name": "musl", "version": "1.2.4-r2", "type": "apk",
+				"purl": "pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64",
+				"licenses": [
+					{"value": "MIT", "spdxExpression": "MIT", "type": "declared"},
+					{"value": "MIT", "spdxExpression": "MIT", "type": "concluded"}
+				],
+				"cpes": [{"cpe": "cpe:2.3:a:musl-libc:musl:1.2.4-r2:*:*:*:*:*:*:*", "source": "syft-generated"}]
+			},
+			{
+				"name": "left-pad", "version": "1.3.0", "type": "npm",
+				"licenses": ["WTFPL"],
+				"cpes": ["cpe:2.3:a:left-pad:left-pad:1.3.0:*:*:*:*:*:*:*"]
+			},
+			{
+				"name": "custom-lib", "version": "0.1", "type": "go-module",
+				"licenses": [{"value": "Proprietary"}]
+			}
+		]
+	}`
+
+	stats, err := parseSyftOutput([]byte(output))
+	if err != nil {
+		t.Fatalf("parseSyftOutput() error = %v", err)
+	}
+	if len(stats.Packages) != 3 {
+		t.Fatalf("expected 3 packages, got %d", len(stats.Packages))
+	}
+
+	byName := make(map[string]types.PackageSummary)
+	for _, p := range stats.Packages {
+		byName[p.Name] = p
+	}
+
+	musl := byName["musl"]
+	if musl.Type != "apk" || musl.PURL != "pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64" {
+		t.Errorf("unexpected musl metadata: %+v", musl)
+	}
+	if len(musl.Licenses) != 1 || musl.Licenses[0] != "MIT" {
+		t.Errorf("musl licenses = %v, want [MIT] (deduplicated)", musl.Licenses)
+	}
+	if len(musl.CPEs) != 1 || !strings.HasPrefix(musl.CPEs[0], "cpe:2.3:a:musl-libc") {
+		t.Errorf("musl CPEs = %v", musl.CPEs)
+	}
+
+	leftPad := byName["left-pad"]
+	if len(leftPad.Licenses) != 1 || leftPad.Licenses[0] != "WTFPL" || len(leftPad.CPEs) != 1 {
+		t.Errorf("legacy string forms not parsed: %+v", leftPad)
+	}
+	if lib := byName["custom-lib"]; len(lib.Licenses) != 1 || lib.Licenses[0] != "Proprietary" {
+		t.Errorf("license value fallback not used: %+v", lib)
+	}
+}
+
 func TestParseGrypeOutput_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -1686,72 +2373,769 @@ func TestParseDiveOutput_EdgeCases(t *testing.T) {
 	}
 }
 
-func TestPodmanSocketDetection(t *testing.T) {
-	// Tests the JSON parsing logic used in DiveRunner.Run() for Podman socket detection.
-	testJSON := `[{
-		"ConnectionInfo": {
-			"PodmanSocket": {
-				"Path": "/var/run/podman.sock"
+func TestTempFileCleanup(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test-dive-output-*.json")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+
+	tmpPath := tmpFile.Name()
+	if err := tmpFile.Close(); err != nil {
+		t.Fatalf("failed to close temp file: %v", err)
+	}
+
+	if _, err := os.Stat(tmpPath); os.IsNotExist(err) {
+		t.Fatal("temp file should exist after creation")
+	}
+
+	if err := os.Remove(tmpPath); err != nil {
+		t.Fatalf("failed to remove temp file: %v", err)
+	}
+
+	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+		t.Error("temp file should not exist after removal")
+	}
+}
+
+// TestPluginRunner_Run tests PluginRunner.Run with a mock plugin executable.
+func TestPluginRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	fakeBin := createFakeBinary(t, dir, "license-check", "plugin-ok")
+
+	r := &PluginRunner{
+		PluginName: "licenses",
+		Command:    fakeBin,
+		Options:    map[string]any{"level": 3},
+	}
+	if !r.IsAvailable() {
+		t.Fatal("PluginRunner.IsAvailable() = false, want true")
+	}
+	if got := r.Name(); got != "licenses" {
+		t.Errorf("PluginRunner.Name() = %q, want %q", got, "licenses")
+	}
+
+	stats, err := r.Run(context.Background(), "test:latest", false)
+	if err != nil {
+		t.Fatalf("PluginRunner.Run() error: %v", err)
+	}
+	if stats.OSDistro != "plugin-os" {
+		t.Errorf("OSDistro = %q, want %q", stats.OSDistro, "plugin-os")
+	}
+	if got := stats.CustomField("licenses", "image"); got != "test:latest" {
+		t.Errorf("custom image = %v, want test:latest", got)
+	}
+	if got := stats.CustomField("licenses", "apiVersion"); got != PluginAPIVersion {
+		t.Errorf("custom apiVersion = %v, want %s", got, PluginAPIVersion)
+	}
+	if got := stats.CustomField("licenses", "level"); got != float64(3) {
+		t.Errorf("custom level = %v, want 3", got)
+	}
+}
+
+// TestPluginRunner_Run_NotFound tests PluginRunner.Run with a missing executable.
+func TestPluginRunner_Run_NotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	r := &PluginRunner{PluginName: "missing", Command: "no-such-plugin"}
+	if r.IsAvailable() {
+		t.Error("PluginRunner.IsAvailable() = true, want false")
+	}
+	_, err := r.Run(context.Background(), "test:latest", false)
+	if err == nil {
+		t.Fatal("PluginRunner.Run() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParsePluginOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantErr     bool
+		wantSummary map[string]int
+		wantCustom  bool
+		wantSize    int64
+	}{
+		{
+			name:     "stats fields only",
+			json:     `{"sizeBytes": 2048, "architecture": "arm64"}`,
+			wantSize: 2048,
+		},
+		{
+			name:        "vulnerabilities without summary",
+			json:        `{"vulnerabilities": [{"id": "LIC-1", "severity": "High", "package": "gpl-lib", "version": "1.0"}, {"id": "LIC-2", "severity": "High"}]}`,
+			wantSummary: map[string]int{"High": 2},
+		},
+		{
+			name:        "explicit summary is kept",
+			json:        `{"vulnerabilities": [{"id": "X", "severity": "Low"}], "vulnSummary": {"Low": 5}}`,
+			wantSummary: map[string]int{"Low": 5},
+		},
+		{
+			name:       "custom fields",
+			json:       `{"custom": {"approved": true}}`,
+			wantCustom: true,
+		},
+		{
+			name:    "invalid json",
+			json:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := parsePluginOutput([]byte(tt.json), "plug")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.SizeBytes != tt.wantSize {
+				t.Errorf("SizeBytes = %d, want %d", stats.SizeBytes, tt.wantSize)
+			}
+			for sev, want := range tt.wantSummary {
+				if got := stats.VulnSummary[sev]; got != want {
+					t.Errorf("VulnSummary[%s] = %d, want %d", sev, got, want)
+				}
+			}
+			if tt.wantCustom {
+				if got := stats.CustomField("plug", "approved"); got != true {
+					t.Errorf("custom approved = %v, want true", got)
+				}
+			} else if stats.Custom != nil {
+				t.Errorf("Custom = %v, want nil", stats.Custom)
+			}
+		})
+	}
+}
+
+func TestParseGrypeOutput_RichDetails(t *testing.T) {
+	output := `{
+		"descriptor": {"timestamp": "2024-02-15T14:30:00Z"},
+		"matches": [
+			{
+				"vulnerability": {
+					"id": "CVE-2024-0001",
+					"dataSource": "https://security.alpinelinux.org/vuln/CVE-2024-0001",
+					"severity": "High",
+					"cvss": [],
+					"fix": {"versions": ["3.0.2-r0"], "state": "fixed"}
+				},
+				"relatedVulnerabilities": [
+					{
+						"id": "CVE-2024-0001",
+						"dataSource": "https://nvd.nist.gov/vuln/detail/CVE-2024-0001",
+						"description": "A flaw in the parser",
+						"cvss": [
+							{"version": "2.0", "vector": "AV:N/AC:L/Au:N/C:P/I:P/A:P", "metrics": {"baseScore": 7.5}},
+							{"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", "metrics": {"baseScore": 7.5}},
+							{"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N", "metrics": {"baseScore": 8.2}}
+						]
+					}
+				],
+				"artifact": {
+					"name": "openssl", "version": "3.0.1-r0", "type": "apk",
+					"locations": [{"path": "/lib/apk/db/installed", "layerID": "sha256:abc"}]
+				}
+			},
+			{
+				"vulnerability": {
+					"id": "GHSA-xxxx-yyyy-zzzz",
+					"severity": "High",
+					"description": "Prototype pollution",
+					"cvss": [{"version": "3.1", "vector": "CVSS:3.1/AV:N", "metrics": {"baseScore": 9.1}}],
+					"fix": {"versions": [], "state": "not-fixed"}
+				},
+				"artifact": {"name": "lodash", "version": "4.17.0", "type": "npm"}
 			}
+		]
+	}`
+
+	stats, err := parseGrypeOutput([]byte(output), false)
+	if err != nil {
+		t.Fatalf("parseGrypeOutput() error: %v", err)
+	}
+	if len(stats.Vulnerabilities) != 2 {
+		t.Fatalf("expected 2 vulnerabilities, got %d", len(stats.Vulnerabilities))
+	}
+
+	// Same severity: higher CVSS score sorts first
+	ghsa, cve := stats.Vulnerabilities[0], stats.Vulnerabilities[1]
+	if ghsa.ID != "GHSA-xxxx-yyyy-zzzz" {
+		t.Fatalf("expected GHSA (CVSS 9.1) first, got %s", ghsa.ID)
+	}
+	if ghsa.Fixable() || ghsa.FixState != "not-fixed" {
+		t.Errorf("GHSA should not be fixable, got state %q", ghsa.FixState)
+	}
+	if ghsa.Description != "Prototype pollution" || ghsa.PackageType != "npm" {
+		t.Errorf("unexpected GHSA details: %+v", ghsa)
+	}
+
+	if !cve.Fixable() || cve.FixedInVersions[0] != "3.0.2-r0" {
+		t.Errorf("CVE should be fixable in 3.0.2-r0, got %+v", cve)
+	}
+	if cve.CVSSScore != 8.2 || !strings.HasSuffix(cve.CVSSVector, "I:H/A:N") {
+		t.Errorf("expected highest CVSS v3.1 entry from related vuln, got %.1f %s", cve.CVSSScore, cve.CVSSVector)
+	}
+	if cve.DataSource != "https://security.alpinelinux.org/vuln/CVE-2024-0001" {
+		t.Errorf("DataSource = %q, want primary data source", cve.DataSource)
+	}
+	if cve.Description != "A flaw in the parser" {
+		t.Errorf("Description = %q, want related description", cve.Description)
+	}
+	if cve.PackageType != "apk" || cve.Location != "/lib/apk/db/installed" {
+		t.Errorf("unexpected package type/location: %q %q", cve.PackageType, cve.Location)
+	}
+	if stats.FixableVulns() != 1 {
+		t.Errorf("FixableVulns() = %d, want 1", stats.FixableVulns())
+	}
+}
+
+func TestParseManifestPlatforms(t *testing.T) {
+	output := []byte(`{
+		"manifests": [
+			{"digest": "sha256:arm64", "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}},
+			{"digest": "sha256:amd64", "platform": {"architecture": "amd64", "os": "linux"}},
+			{"digest": "sha256:armv7", "platform": {"architecture": "arm", "os": "linux", "variant": "v7"}},
+			{"digest": "sha256:att", "platform": {"architecture": "unknown", "os": "unknown"}}
+		]
+	}`)
+	got := parseManifestPlatforms(output)
+	want := []string{"linux/amd64", "linux/arm/v7", "linux/arm64/v8"}
+	if len(got) != len(want) {
+		t.Fatalf("parseManifestPlatforms() = %+v, want %v", got, want)
+	}
+	for i, p := range got {
+		if p.String() != want[i] {
+			t.Errorf("platform[%d] = %q, want %q", i, p.String(), want[i])
 		}
-	}]`
+	}
+	if got[0].Digest != "sha256:amd64" {
+		t.Errorf("linux/amd64 digest = %q, want sha256:amd64", got[0].Digest)
+	}
 
-	var machines []struct {
-		ConnectionInfo struct {
-			PodmanSocket *struct {
-				Path string `json:"Path"`
-			} `json:"PodmanSocket"`
-		} `json:"ConnectionInfo"`
+	if got := parseManifestPlatforms([]byte(`[{"Id": "sha256:single"}]`)); got != nil {
+		t.Errorf("expected nil for non-manifest-list output, got %+v", got)
 	}
+}
 
-	if err := json.Unmarshal([]byte(testJSON), &machines); err != nil {
-		t.Fatalf("failed to unmarshal: %v", err)
+func TestPlatformRef(t *testing.T) {
+	p := Platform{OS: "linux", Architecture: "arm64", Digest: "sha256:abc"}
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.25", "nginx@sha256:abc"},
+		{"nginx", "nginx@sha256:abc"},
+		{"localhost:5000/app", "localhost:5000/app@sha256:abc"},
+		{"localhost:5000/app:v1", "localhost:5000/app@sha256:abc"},
+		{"ghcr.io/org/app:v1@sha256:index", "ghcr.io/org/app@sha256:abc"},
+	}
+	for _, tt := range tests {
+		if got := p.Ref(tt.image); got != tt.want {
+			t.Errorf("Ref(%q) = %q, want %q", tt.image, got, tt.want)
+		}
 	}
+}
 
-	if len(machines) == 0 {
-		t.Fatal("expected at least one machine")
+func TestParseRuntimeInspect_Config(t *testing.T) {
+	output := []byte(`[{
+		"Id": "sha256:0123456789abcdef0123456789abcdef",
+		"RepoDigests": ["mirror.local/nginx@sha256:aaa", "nginx@sha256:bbb"],
+		"Created": "2024-03-01T10:20:30.123456789Z",
+		"Architecture": "amd64",
+		"Os": "linux",
+		"Size": 1024,
+		"RootFS": {"Layers": ["sha256:l1"]},
+		"Config": {
+			"User": "nginx",
+			"WorkingDir": "/usr/share/nginx",
+			"Entrypoint": ["/docker-entrypoint.sh"],
+			"Cmd": ["nginx", "-g", "daemon off;"],
+			"Env": ["PATH=/usr/bin", "NGINX_VERSION=1.25.4"],
+			"ExposedPorts": {"80/tcp": {}, "443/tcp": {}},
+			"Volumes": {"/var/cache/nginx": {}},
+			"Labels": {"maintainer": "NGINX"},
+			"Healthcheck": {"Test": ["CMD-SHELL", "curl -f http://localhost/"], "Interval": 30000000000, "Retries": 3}
+		}
+	}]`)
+	stats, err := parseRuntimeInspect(output, "nginx:1.25", "docker")
+	if err != nil {
+		t.Fatalf("parseRuntimeInspect() error = %v", err)
+	}
+	if stats.RepoDigest != "nginx@sha256:bbb" {
+		t.Errorf("RepoDigest = %q, want the digest of the analyzed repository", stats.RepoDigest)
+	}
+	if stats.ShortImageID() != "0123456789ab" {
+		t.Errorf("ShortImageID() = %q", stats.ShortImageID())
+	}
+	if want := time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC); !stats.Created.Equal(want) {
+		t.Errorf("Created = %v, want %v", stats.Created, want)
+	}
+	cfg := stats.Config
+	if cfg.User != "nginx" || cfg.WorkingDir != "/usr/share/nginx" || len(cfg.Entrypoint) != 1 || len(cfg.Cmd) != 3 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if strings.Join(cfg.ExposedPorts, ",") != "443/tcp,80/tcp" || len(cfg.Volumes) != 1 || cfg.Labels["maintainer"] != "NGINX" {
+		t.Errorf("unexpected ports/volumes/labels: %+v", cfg)
+	}
+	if cfg.Healthcheck == nil || cfg.Healthcheck.Interval != 30*time.Second || cfg.Healthcheck.Command() != "curl -f http://localhost/" {
+		t.Errorf("unexpected healthcheck: %+v", cfg.Healthcheck)
 	}
 
-	if machines[0].ConnectionInfo.PodmanSocket == nil {
-		t.Fatal("PodmanSocket is nil")
+	// Podman reports fully qualified digests and a top-level HealthCheck.
+	podman := []byte(`[{
+		"Id": "abc",
+		"RepoDigests": ["docker.io/library/nginx@sha256:ccc"],
+		"Created": "not-a-date",
+		"HealthCheck": {"Test": ["CMD", "/healthz"]}
+	}]`)
+	stats, err = parseRuntimeInspect(podman, "nginx:1.25", "podman")
+	if err != nil {
+		t.Fatalf("parseRuntimeInspect(podman) error = %v", err)
+	}
+	if stats.RepoDigest != "docker.io/library/nginx@sha256:ccc" || !stats.Created.IsZero() {
+		t.Errorf("unexpected podman digest/created: %q %v", stats.RepoDigest, stats.Created)
+	}
+	if stats.Config.Healthcheck == nil || stats.Config.Healthcheck.Command() != "/healthz" {
+		t.Errorf("unexpected podman healthcheck: %+v", stats.Config.Healthcheck)
 	}
+}
 
-	socketPath := machines[0].ConnectionInfo.PodmanSocket.Path
-	if socketPath != "/var/run/podman.sock" {
-		t.Errorf("socket path = %v, want /var/run/podman.sock", socketPath)
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantFormat string
+		wantImage  string
+		wantDistro string
+		wantPkgs   []types.PackageSummary
+		wantVulns  []types.Vulnerability
+	}{
+		{
+			name: "spdx",
+			data: `{"spdxVersion": "SPDX-2.3", "packages": [
+				{"name": "app:1", "primaryPackagePurpose": "CONTAINER"},
+				{"name": "alpine", "versionInfo": "3.19.1", "primaryPackagePurpose": "OPERATING-SYSTEM"},
+				{"name": "musl", "versionInfo": "1.2.4-r2", "licenseConcluded": "NOASSERTION", "licenseDeclared": "MIT",
+				 "externalRefs": [
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/alpine/musl@1.2.4-r2"},
+					{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:musl:musl:1.2.4:*:*:*:*:*:*:*"}
+				 ]},
+				{"name": "express", "versionInfo": "4.18.2", "licenseConcluded": "MIT",
+				 "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:npm/express@4.18.2"}]}
+			]}`,
+			wantFormat: ReportSPDX,
+			wantDistro: "alpine 3.19.1",
+			wantPkgs: []types.PackageSummary{
+				{Name: "express", Version: "4.18.2", Type: "npm", Licenses: []string{"MIT"}, PURL: "pkg:npm/express@4.18.2"},
+				{Name: "musl", Version: "1.2.4-r2", Type: "apk", Licenses: []string{"MIT"}, PURL: "pkg:apk/alpine/musl@1.2.4-r2",
+					CPEs: []string{"cpe:2.3:a:musl:musl:1.2.4:*:*:*:*:*:*:*"}},
+			},
+		},
+		{
+			name: "cyclonedx",
+			data: `{"bomFormat": "CycloneDX", "specVersion": "1.5",
+				"metadata": {"component": {"type": "container", "name": "app:1"}},
+				"components": [
+					{"bom-ref": "os", "type": "operating-system", "name": "debian", "version": "12"},
+					{"bom-ref": "pkg1", "type": "library", "name": "openssl", "version": "3.0.11", "purl": "pkg:deb/debian/openssl@3.0.11",
+					 "licenses": [{"expression": "Apache-2.0"}], "properties": [{"name": "syft:package:type", "value": "deb"}]}
+				],
+				"vulnerabilities": [{"id": "CVE-2023-5678", "source": {"url": "https://nvd.nist.gov/vuln/detail/CVE-2023-5678"},
+					"ratings": [{"score": 5.3, "severity": "medium", "vector": "CVSS:3.1/AV:N"}, {"severity": "low"}],
+					"description": "DoS", "affects": [{"ref": "pkg1"}]}]
+			}`,
+			wantFormat: ReportCycloneDX,
+			wantImage:  "app:1",
+			wantDistro: "debian 12",
+			wantPkgs: []types.PackageSummary{
+				{Name: "openssl", Version: "3.0.11", Type: "deb", Licenses: []string{"Apache-2.0"}, PURL: "pkg:deb/debian/openssl@3.0.11"},
+			},
+			wantVulns: []types.Vulnerability{
+				{ID: "CVE-2023-5678", Severity: "Medium", Package: "openssl", Version: "3.0.11", CVSSScore: 5.3, CVSSVector: "CVSS:3.1/AV:N",
+					DataSource: "https://nvd.nist.gov/vuln/detail/CVE-2023-5678", Description: "DoS", PackageType: "deb"},
+			},
+		},
+		{
+			name: "trivy",
+			data: `{"SchemaVersion": 2, "ArtifactName": "app:1", "Metadata": {"OS": {"Family": "alpine", "Name": "3.19.1"}},
+				"Results": [{"Target": "app:1 (alpine 3.19.1)", "Type": "alpine",
+					"Packages": [{"Name": "busybox", "Version": "1.36.1-r15", "Licenses": ["GPL-2.0-only"]}],
+					"Vulnerabilities": [
+						{"VulnerabilityID": "CVE-2023-42363", "PkgName": "busybox", "InstalledVersion": "1.36.1-r15",
+						 "FixedVersion": "1.36.1-r16, 1.37.0-r0", "Status": "fixed", "Severity": "MEDIUM", "Title": "use-after-free",
+						 "PrimaryURL": "https://avd.aquasec.com/nvd/cve-2023-42363",
+						 "CVSS": {"redhat": {"V3Score": 5.0}, "nvd": {"V3Vector": "CVSS:3.1/AV:L", "V3Score": 5.5}}},
+						{"VulnerabilityID": "CVE-2024-0001", "PkgName": "busybox", "InstalledVersion": "1.36.1-r15",
+						 "Status": "will_not_fix", "Severity": "LOW"}
+					]}]}`,
+			wantFormat: ReportTrivy,
+			wantImage:  "app:1",
+			wantDistro: "alpine 3.19.1",
+			wantPkgs: []types.PackageSummary{
+				{Name: "busybox", Version: "1.36.1-r15", Type: "apk", Licenses: []string{"GPL-2.0-only"}},
+			},
+			wantVulns: []types.Vulnerability{
+				{ID: "CVE-2023-42363", Severity: "Medium", Package: "busybox", Version: "1.36.1-r15", FixState: types.FixStateFixed,
+					FixedInVersions: []string{"1.36.1-r16", "1.37.0-r0"}, CVSSScore: 5.5, CVSSVector: "CVSS:3.1/AV:L",
+					DataSource: "https://avd.aquasec.com/nvd/cve-2023-42363", Description: "use-after-free", PackageType: "apk"},
+				{ID: "CVE-2024-0001", Severity: "Low", Package: "busybox", Version: "1.36.1-r15", FixState: types.FixStateWontFix, PackageType: "apk"},
+			},
+		},
+		{
+			name: "sarif",
+			data: `{"$schema": "https://json.schemastore.org/sarif-2.1.0.json", "version": "2.1.0", "runs": [{
+				"tool": {"driver": {"name": "grype", "rules": [{"id": "CVE-2023-1234-musl", "helpUri": "https://example.com/CVE-2023-1234",
+					"fullDescription": {"text": "overflow"}, "properties": {"security-severity": "9.8"}}]}},
+				"results": [
+					{"ruleId": "CVE-2023-1234-musl", "level": "error",
+					 "message": {"text": "A critical vulnerability in apk package: musl, version 1.2.4-r2 was found at: /lib/apk/db/installed"},
+					 "locations": [{"physicalLocation": {"artifactLocation": {"uri": "/lib/apk/db/installed"}}}]},
+					{"ruleId": "GHSA-abcd-efgh-ijkl", "level": "warning",
+					 "message": {"text": "Package: lodash\nInstalled Version: 4.17.20\nVulnerability GHSA-abcd-efgh-ijkl"}}
+				]}, {
+				"tool": {"driver": {"name": "dock-docs", "rules": [
+					{"id": "ALAS-2024-001", "properties": {"tags": ["security", "vulnerability"]}},
+					{"id": "lint/DL3007", "properties": {"tags": ["dockerfile", "lint"]}},
+					{"id": "drift/missing", "properties": {"tags": ["drift"]}},
+					{"id": "AVD-DS-0002", "properties": {"tags": ["misconfiguration", "security", "HIGH"]}}]}},
+				"results": [
+					{"ruleId": "ALAS-2024-001", "level": "warning", "message": {"text": "openssl 3.0.8 is affected"}},
+					{"ruleId": "lint/DL3007", "level": "warning", "message": {"text": "Using latest"}},
+					{"ruleId": "drift/missing", "level": "error", "message": {"text": "ENV FOO is missing"}},
+					{"ruleId": "AVD-DS-0002", "level": "error", "message": {"text": "Image user should not be root"}}
+				]}]}`,
+			wantFormat: ReportSARIF,
+			wantVulns: []types.Vulnerability{
+				{ID: "CVE-2023-1234", Severity: "Critical", Package: "musl", Version: "1.2.4-r2", FixState: types.FixStateUnknown,
+					CVSSScore: 9.8, DataSource: "https://example.com/CVE-2023-1234", Description: "overflow", Location: "/lib/apk/db/installed"},
+				{ID: "ALAS-2024-001", Severity: "Medium", FixState: types.FixStateUnknown, Description: "openssl 3.0.8 is affected"},
+				{ID: "GHSA-ABCD-EFGH-IJKL", Severity: "Medium", Package: "lodash", Version: "4.17.20", FixState: types.FixStateUnknown,
+					Description: "Package: lodash\nInstalled Version: 4.17.20\nVulnerability GHSA-abcd-efgh-ijkl"},
+			},
+		},
 	}
 
-	// Test unix:// prefix logic
-	if !strings.HasPrefix(socketPath, "unix://") {
-		socketPath = "unix://" + socketPath
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, format, err := ParseReport([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseReport() error: %v", err)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			if stats.ImageTag != tt.wantImage {
+				t.Errorf("ImageTag = %q, want %q", stats.ImageTag, tt.wantImage)
+			}
+			if stats.OSDistro != tt.wantDistro {
+				t.Errorf("OSDistro = %q, want %q", stats.OSDistro, tt.wantDistro)
+			}
+			if len(tt.wantPkgs) > 0 && !reflect.DeepEqual(stats.Packages, tt.wantPkgs) {
+				t.Errorf("Packages =\n%+v\nwant\n%+v", stats.Packages, tt.wantPkgs)
+			}
+			if stats.TotalPackages != len(tt.wantPkgs) {
+				t.Errorf("TotalPackages = %d, want %d", stats.TotalPackages, len(tt.wantPkgs))
+			}
+			if len(tt.wantVulns) > 0 && !reflect.DeepEqual(stats.Vulnerabilities, tt.wantVulns) {
+				t.Errorf("Vulnerabilities =\n%+v\nwant\n%+v", stats.Vulnerabilities, tt.wantVulns)
+			}
+			if got := stats.TotalVulns(); got != len(tt.wantVulns) {
+				t.Errorf("TotalVulns() = %d, want %d", got, len(tt.wantVulns))
+			}
+		})
 	}
+}
 
-	expectedPath := "unix:///var/run/podman.sock"
-	if socketPath != expectedPath {
-		t.Errorf("formatted socket path = %v, want %v", socketPath, expectedPath)
+func TestParseReport_Runners(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"spdx", `{"spdxVersion": "SPDX-2.3", "packages": []}`, []string{"syft"}},
+		{"cyclonedx sbom", `{"bomFormat": "CycloneDX", "components": []}`, []string{"syft"}},
+		{"cyclonedx clean scan", `{"bomFormat": "CycloneDX", "components": [], "vulnerabilities": []}`, []string{"syft", "grype"}},
+		{"grype", `{"matches": []}`, []string{"grype"}},
+		{"trivy without packages", `{"SchemaVersion": 2, "Results": []}`, []string{"grype"}},
+		{"sarif", `{"runs": []}`, []string{"grype"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, _, err := ParseReport([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseReport() error: %v", err)
+			}
+			var got []string
+			for _, r := range stats.Runners {
+				if r.Status != types.RunnerOK {
+					t.Errorf("runner %s status = %q, want ok", r.Name, r.Status)
+				}
+				got = append(got, r.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Runners = %v, want %v", got, tt.want)
+			}
+		})
 	}
 }
 
-func TestTempFileCleanup(t *testing.T) {
-	tmpFile, err := os.CreateTemp("", "test-dive-output-*.json")
+// TestParseReport_CycloneDXRoundTrip tests that triaged findings in a BOM
+// written by dock-docs are imported as suppressed rather than active.
+func TestParseReport_CycloneDXRoundTrip(t *testing.T) {
+	pkg := types.Vulnerability{Package: "openssl", Version: "3.0.11", Severity: "High"}
+	withID := func(id string) types.Vulnerability {
+		v := pkg
+		v.ID = id
+		return v
+	}
+	in := &types.ImageStats{
+		ImageTag:        "app:1",
+		Packages:        []types.PackageSummary{{Name: "openssl", Version: "3.0.11", Type: "deb"}},
+		Vulnerabilities: []types.Vulnerability{withID("CVE-2024-0001")},
+		Suppressed: []types.SuppressedVulnerability{
+			{Vulnerability: withID("CVE-2024-0002"), Justification: "behind the proxy"},
+			{Vulnerability: withID("CVE-2024-0003"), Justification: "never loaded", Status: "not_affected", VEXJustification: "vulnerable_code_not_in_execute_path"},
+			{Vulnerability: withID("CVE-2024-0004"), Justification: "wrong package", Status: "false_positive"},
+			{Vulnerability: withID("CVE-2024-0005"), Justification: "patched", Status: "fixed"},
+		},
+	}
+	data, err := sbom.CycloneDX(in)
 	if err != nil {
-		t.Fatalf("failed to create temp file: %v", err)
+		t.Fatalf("CycloneDX() error: %v", err)
 	}
 
-	tmpPath := tmpFile.Name()
-	if err := tmpFile.Close(); err != nil {
-		t.Fatalf("failed to close temp file: %v", err)
+	stats, err := parseCycloneDXReport(data)
+	if err != nil {
+		t.Fatalf("parseCycloneDXReport() error: %v", err)
+	}
+	if len(stats.Vulnerabilities) != 1 || stats.Vulnerabilities[0].ID != "CVE-2024-0001" || stats.VulnSummary["High"] != 1 {
+		t.Errorf("Vulnerabilities = %+v, want only CVE-2024-0001", stats.Vulnerabilities)
+	}
+	type triage struct{ ID, Justification, Status, VEXJustification string }
+	var got []triage
+	for _, s := range stats.Suppressed {
+		got = append(got, triage{s.ID, s.Justification, s.Status, s.VEXJustification})
 	}
+	want := []triage{
+		{"CVE-2024-0002", "behind the proxy", "", ""},
+		{"CVE-2024-0003", "never loaded", "not_affected", "vulnerable_code_not_in_execute_path"},
+		{"CVE-2024-0004", "wrong package", "false_positive", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Suppressed =\n%+v\nwant\n%+v", got, want)
+	}
+}
 
-	if _, err := os.Stat(tmpPath); os.IsNotExist(err) {
-		t.Fatal("temp file should exist after creation")
+func TestParseReport_ToolFormats(t *testing.T) {
+	grype := `{"matches": [{"vulnerability": {"id": "CVE-1", "severity": "High"}, "artifact": {"name": "a", "version": "1"}}],
+		"source": {"target": {"userInput": "app:1"}}}`
+	stats, format, err := ParseReport([]byte(grype))
+	if err != nil || format != ReportGrype || stats.ImageTag != "app:1" || stats.VulnSummary["High"] != 1 {
+		t.Errorf("ParseReport(grype) = (%+v, %q, %v)", stats, format, err)
 	}
 
-	if err := os.Remove(tmpPath); err != nil {
-		t.Fatalf("failed to remove temp file: %v", err)
+	syft := `{"artifacts": [{"name": "a", "version": "1", "type": "npm"}], "source": {"target": {"userInput": "app:2"}}}`
+	stats, format, err = ParseReport([]byte(syft))
+	if err != nil || format != ReportSyft || stats.ImageTag != "app:2" || stats.TotalPackages != 1 {
+		t.Errorf("ParseReport(syft) = (%+v, %q, %v)", stats, format, err)
 	}
 
-	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
-		t.Error("temp file should not exist after removal")
+	for _, bad := range []string{"not json", `{"foo": 1}`} {
+		if _, _, err := ParseReport([]byte(bad)); err == nil {
+			t.Errorf("ParseReport(%q) expected error", bad)
+		}
+	}
+}
+
+func TestSyftRunner_Args(t *testing.T) {
+	dir := t.TempDir()
+	fakeBin := createFakeBinary(t, dir, "syft", "syft-args")
+
+	r := &SyftRunner{binary: fakeBin, Args: []string{"--exclude", "/tmp"}}
+	if _, err := r.Run(context.Background(), "test:latest", false); err != nil {
+		t.Fatalf("SyftRunner.Run() error: %v", err)
+	}
+	if _, err := (&SyftRunner{binary: fakeBin}).Run(context.Background(), "test:latest", false); err == nil {
+		t.Error("expected the helper to reject a run without extra args")
+	}
+	if r.ArgsKey() == (&SyftRunner{}).ArgsKey() {
+		t.Error("ArgsKey() should distinguish runners with extra args")
+	}
+}
+
+// TestScanSource tests that scanners read images from the selected backend's
+// store, and that unsupported combinations are rejected.
+func TestScanSource(t *testing.T) {
+	defer SetBackend(nil)
+	dir := t.TempDir()
+	bin := func(name string) string { return createFakeBinary(t, dir, name, "echo") }
+	docker := &cliBackend{name: BackendDocker, binary: bin("docker")}
+	podman := &cliBackend{name: BackendPodman, binary: bin("podman")}
+	nerdctl := &cliBackend{name: BackendNerdctl, binary: bin("nerdctl")}
+	buildah := &skopeoBackend{binary: bin("skopeo"), builder: bin("buildah")}
+
+	tests := []struct {
+		name    string
+		backend Backend
+		path    string
+		tool    string
+		want    string
+		wantErr bool
+	}{
+		{"docker", docker, dir, "syft", "app:1", false},
+		{"podman", podman, dir, "grype", "podman:app:1", false},
+		{"nerdctl", nerdctl, dir, "syft", "containerd:app:1", false},
+		{"buildah with podman", buildah, dir, "grype", "podman:app:1", false},
+		{"buildah without podman", buildah, t.TempDir(), "syft", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.path)
+			SetBackend(tt.backend)
+			got, err := scanImage(tt.tool, "app:1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scanImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrToolMissing) {
+				t.Errorf("scanImage() error = %v, want ErrToolMissing", err)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("scanImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// dive has no containerd source.
+	SetBackend(nerdctl)
+	if _, err := (&DiveRunner{binary: bin("dive")}).Run(context.Background(), "app:1", false); !errors.Is(err, ErrToolMissing) {
+		t.Errorf("DiveRunner.Run() with nerdctl = %v, want ErrToolMissing", err)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	ctx, cancel := commandTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) < 59*time.Minute {
+		t.Errorf("default deadline in %v, want about 1h", time.Until(deadline))
+	}
+
+	ctx, cancel = commandTimeout(withCommandTimeout(context.Background(), time.Second), time.Hour)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) > time.Second {
+		t.Errorf("overridden deadline in %v, want at most 1s", time.Until(deadline))
+	}
+
+	base := context.Background()
+	if withCommandTimeout(base, 0) != base {
+		t.Error("withCommandTimeout(0) should leave the context unchanged")
+	}
+}
+
+// TestRunnerTimeout tests that a runner's Timeout bounds both its scan and
+// the version query of its cache key.
+func TestRunnerTimeout(t *testing.T) {
+	fakeBin := createFakeBinary(t, t.TempDir(), "syft", "sleep")
+	r := &SyftRunner{binary: fakeBin, Timeout: 50 * time.Millisecond}
+
+	if _, err := r.CacheKey(context.Background()); !errors.Is(err, ErrTimeout) {
+		t.Errorf("CacheKey() error = %v, want ErrTimeout", err)
+	}
+	if _, err := r.Run(context.Background(), "test:latest", false); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("command failed: exit status 1\nStderr: GET https://index.docker.io: TOOMANYREQUESTS: rate limit"), true},
+		{fmt.Errorf("received unexpected HTTP status: 503 Service Unavailable"), true},
+		{fmt.Errorf("dial tcp: i/o timeout"), true},
+		{fmt.Errorf("scan: %w", context.DeadlineExceeded), true},
+		{fmt.Errorf("command failed: exit status 1\nStderr: manifest unknown"), false},
+		{fmt.Errorf("received unexpected HTTP status: 429 Too Many Requests"), true},
+		{fmt.Errorf("command failed: exit status 1\nStderr: layer sha256:4290ab not found on port 4291"), false},
+		{fmt.Errorf("dial tcp 10.0.0.1:5000: connect: connection refused"), false},
+		{&Error{Kind: ErrDaemonUnreachable, Tool: "docker", Err: errors.New("Cannot connect to the Docker daemon: i/o timeout")}, false},
+		{&Error{Kind: ErrToolMissing, Tool: "grype", Err: errors.New("grype not found")}, false},
+		{&Error{Kind: ErrImageNotFound, Tool: "docker", Err: errors.New("503 Service Unavailable")}, false},
+		{&Error{Kind: ErrTimeout, Tool: "grype", Err: errors.New("signal: killed")}, true},
+		{fmt.Errorf("failed to unmarshal syft output: invalid character"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRunCommand_Classified(t *testing.T) {
+	tests := []struct {
+		helper string
+		want   error
+	}{
+		{"no-such-image", ErrImageNotFound},
+		{"pull-denied", ErrImageNotFound},
+		{"unauthorized", ErrAuthRequired},
+		{"daemon-down", ErrDaemonUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.helper, func(t *testing.T) {
+			_, err := runCommand(helperCmd(tt.helper), false)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("runCommand() error = %v, want %v", err, tt.want)
+			}
+			var e *Error
+			if !errors.As(err, &e) || !strings.Contains(e.Err.Error(), "Stderr:") {
+				t.Errorf("runCommand() error = %v, want *Error keeping the stderr", err)
+			}
+		})
+	}
+
+	// Unrecognised failures stay unclassified.
+	_, err := runCommand(helperCmd("error"), false)
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		t.Errorf("runCommand(error) = %v, want an unclassified error", err)
+	}
+
+	for _, name := range []string{"dock-docs-missing-tool", filepath.Join(t.TempDir(), "missing-tool")} {
+		if _, err := runCommand(exec.Command(name), false); !errors.Is(err, ErrToolMissing) {
+			t.Errorf("runCommand(%s) = %v, want ErrToolMissing", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=TestHelperProcess", "--")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1", "GO_TEST_HELPER_CMD=sleep")
+	_, err = runCommand(cmd, false)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) || !IsTransient(err) {
+		t.Errorf("runCommand(sleep) = %v, want a transient ErrTimeout", err)
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	_, err := parseSyftOutput([]byte("not json"))
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("parseSyftOutput() = %v, want ErrParse", err)
+	}
+
+	origLookup := lookupTool
+	defer func() { lookupTool = origLookup }()
+	lookupTool = func(name string) (string, error) { return "", exec.ErrNotFound }
+	if _, err := (&GrypeRunner{}).Run(context.Background(), "app:1", false); !errors.Is(err, ErrToolMissing) {
+		t.Errorf("GrypeRunner.Run() = %v, want ErrToolMissing", err)
 	}
 }
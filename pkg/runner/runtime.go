@@ -4,74 +4,98 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
-// RuntimeRunner runs 'docker inspect' or 'podman inspect'
+// RuntimeRunner inspects the image with the selected container runtime
+// backend (e.g., 'docker image inspect').
 type RuntimeRunner struct {
-	binary string
+	// Timeout overrides the timeout of each runtime command (default:
+	// TimeoutInspect).
+	Timeout time.Duration
+
+	backend Backend
 }
 
 // Name returns the display name for this runner.
 func (r *RuntimeRunner) Name() string {
-	if r.binary != "" {
-		return r.binary
+	if r.backend != nil {
+		return r.backend.Name()
 	}
 	return "runtime"
 }
 
-// IsAvailable checks whether a container runtime (docker or podman) is installed.
+// IsAvailable checks whether a container runtime backend is available.
 func (r *RuntimeRunner) IsAvailable() bool {
-	// Check docker first
-	if _, err := exec.LookPath("docker"); err == nil {
-		r.binary = "docker"
-		return true
-	}
-	// Fallback to podman
-	if _, err := exec.LookPath("podman"); err == nil {
-		r.binary = "podman"
-		return true
+	b, err := CurrentBackend()
+	if err != nil {
+		return false
 	}
-	return false
+	r.backend = b
+	return true
 }
 
-// Run executes 'docker inspect' or 'podman inspect' and parses the result.
+// Run inspects the image and parses the result.
 // The provided context is used as the parent for the command timeout.
 func (r *RuntimeRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
-	// Ensure binary is set if IsAvailable wasn't called (though it should be)
-	if r.binary == "" {
-		if !r.IsAvailable() {
-			return nil, fmt.Errorf("no container runtime found (docker or podman)")
+	// Ensure the backend is set if IsAvailable wasn't called (though it should be)
+	if r.backend == nil {
+		b, err := CurrentBackend()
+		if err != nil {
+			return nil, err
 		}
+		r.backend = b
 	}
+	ctx = withCommandTimeout(ctx, r.Timeout)
+	return r.backend.Inspect(ctx, image, verbose)
+}
 
-	runCtx, cancel := context.WithTimeout(ctx, TimeoutInspect)
-	defer cancel()
-	cmd := exec.CommandContext(runCtx, r.binary, "inspect", image)
-	output, err := runCommand(cmd, verbose)
-	if err != nil {
-		return nil, err
-	}
-
-	return parseRuntimeInspect(output, image, r.binary)
+// inspectHealthcheck mirrors the HEALTHCHECK block of 'docker inspect'.
+// Durations are reported in nanoseconds.
+type inspectHealthcheck struct {
+	Test        []string      `json:"Test"`
+	Interval    time.Duration `json:"Interval"`
+	Timeout     time.Duration `json:"Timeout"`
+	StartPeriod time.Duration `json:"StartPeriod"`
+	Retries     int           `json:"Retries"`
 }
 
-// parseRuntimeInspect parses JSON output from 'docker inspect' or 'podman inspect'
-// into ImageStats containing architecture, OS, size, and layer count.
+// parseRuntimeInspect parses JSON output from 'docker image inspect' (or the
+// compatible podman and nerdctl output)
+// into ImageStats containing architecture, OS, size, layer count, digests,
+// creation time and the image's runtime configuration.
 func parseRuntimeInspect(output []byte, image string, binary string) (*types.ImageStats, error) {
 	var inspect []struct {
-		Architecture string `json:"Architecture"`
-		Os           string `json:"Os"`
-		Size         int64  `json:"Size"`
+		ID           string   `json:"Id"`
+		RepoDigests  []string `json:"RepoDigests"`
+		Created      string   `json:"Created"`
+		Architecture string   `json:"Architecture"`
+		Os           string   `json:"Os"`
+		Size         int64    `json:"Size"`
 		RootFS       struct {
 			Layers []string `json:"Layers"`
 		} `json:"RootFS"`
+		Config struct {
+			User         string              `json:"User"`
+			WorkingDir   string              `json:"WorkingDir"`
+			Entrypoint   []string            `json:"Entrypoint"`
+			Cmd          []string            `json:"Cmd"`
+			Env          []string            `json:"Env"`
+			ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+			Volumes      map[string]struct{} `json:"Volumes"`
+			Labels       map[string]string   `json:"Labels"`
+			Healthcheck  *inspectHealthcheck `json:"Healthcheck"`
+		} `json:"Config"`
+		// Podman reports the health check at the top level.
+		HealthCheck *inspectHealthcheck `json:"HealthCheck"`
 	}
 
 	if err := json.Unmarshal(output, &inspect); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal %s inspect output: %w", binary, err)
+		return nil, parseError(binary, fmt.Errorf("failed to unmarshal %s inspect output: %w", binary, err))
 	}
 
 	if len(inspect) == 0 {
@@ -81,11 +105,76 @@ func parseRuntimeInspect(output []byte, image string, binary string) (*types.Ima
 	data := inspect[0]
 	stats := &types.ImageStats{
 		ImageTag:     image,
+		RepoDigest:   pickRepoDigest(data.RepoDigests, image),
+		ImageID:      data.ID,
 		Architecture: data.Architecture,
 		OS:           data.Os,
 		SizeBytes:    data.Size,
 		TotalLayers:  len(data.RootFS.Layers),
+		Config: &types.ImageConfig{
+			User:         data.Config.User,
+			WorkingDir:   data.Config.WorkingDir,
+			Entrypoint:   data.Config.Entrypoint,
+			Cmd:          data.Config.Cmd,
+			Env:          data.Config.Env,
+			ExposedPorts: sortedKeys(data.Config.ExposedPorts),
+			Volumes:      sortedKeys(data.Config.Volumes),
+			Labels:       data.Config.Labels,
+		},
+	}
+	if created, err := time.Parse(time.RFC3339Nano, data.Created); err == nil {
+		stats.Created = created
+	}
+
+	hc := data.Config.Healthcheck
+	if hc == nil {
+		hc = data.HealthCheck
+	}
+	if hc != nil && len(hc.Test) > 0 {
+		stats.Config.Healthcheck = &types.Healthcheck{
+			Test:        hc.Test,
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
 	}
 
 	return stats, nil
 }
+
+// pickRepoDigest returns the repo digest that belongs to the analyzed
+// repository, falling back to the first one. Podman fully qualifies names
+// ("docker.io/library/nginx@sha256:..."), so a suffix match is accepted when
+// there is no exact one.
+func pickRepoDigest(digests []string, image string) string {
+	repo := repository(image)
+	for _, d := range digests {
+		if repository(d) == repo {
+			return d
+		}
+	}
+	for _, d := range digests {
+		if strings.HasSuffix(repository(d), "/"+repo) {
+			return d
+		}
+	}
+	if len(digests) > 0 {
+		return digests[0]
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of a JSON object used as a set
+// (e.g., ExposedPorts), in sorted order.
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,222 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// skopeoBackend reads images with skopeo, without a daemon. Local images live
+// in containers-storage (shared with buildah and podman); images that are not
+// stored locally are read from the registry.
+type skopeoBackend struct {
+	binary string
+	// builder, when set, is the buildah binary used to check for and pull
+	// images instead of 'skopeo inspect' and 'skopeo copy'.
+	builder string
+}
+
+// Name returns "buildah" when pulls use buildah, otherwise "skopeo".
+func (b *skopeoBackend) Name() string {
+	if b.builder != "" {
+		return BackendBuildah
+	}
+	return BackendSkopeo
+}
+
+// Available checks whether skopeo (and buildah, when used) is installed.
+func (b *skopeoBackend) Available() bool {
+	if _, err := exec.LookPath(b.binary); err != nil {
+		return false
+	}
+	if b.builder != "" {
+		if _, err := exec.LookPath(b.builder); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ref returns the skopeo reference of image: the local copy when there is
+// one, otherwise the registry image.
+func (b *skopeoBackend) ref(ctx context.Context, image string, verbose bool) string {
+	if b.Exists(ctx, image, verbose) {
+		return "containers-storage:" + image
+	}
+	return "docker://" + image
+}
+
+// inspect runs 'skopeo inspect' with args against ref.
+func (b *skopeoBackend) inspect(ctx context.Context, ref string, verbose bool, args ...string) ([]byte, error) {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	args = append(append([]string{"inspect"}, args...), ref)
+	return runCommand(commandContext(runCtx, b.binary, args...), verbose)
+}
+
+// Inspect combines 'skopeo inspect' (digest, size, layers) with
+// 'skopeo inspect --config' (runtime configuration).
+func (b *skopeoBackend) Inspect(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
+	ref := b.ref(ctx, image, verbose)
+	summary, err := b.inspect(ctx, ref, verbose)
+	if err != nil {
+		return nil, err
+	}
+	config, err := b.inspect(ctx, ref, verbose, "--config", "--raw")
+	if err != nil {
+		return nil, err
+	}
+	return parseSkopeoInspect(summary, config, image)
+}
+
+// ImageID returns the digest of the raw image config.
+func (b *skopeoBackend) ImageID(ctx context.Context, image string, verbose bool) (string, error) {
+	config, err := b.inspect(ctx, b.ref(ctx, image, verbose), verbose, "--config", "--raw")
+	if err != nil {
+		return "", err
+	}
+	return configDigest(config), nil
+}
+
+// Exists reports whether the image is in containers-storage.
+func (b *skopeoBackend) Exists(ctx context.Context, image string, verbose bool) bool {
+	runCtx, cancel := commandTimeout(ctx, TimeoutInspect)
+	defer cancel()
+	cmd := commandContext(runCtx, b.binary, "inspect", "--raw", "containers-storage:"+image)
+	if b.builder != "" {
+		cmd = commandContext(runCtx, b.builder, "inspect", "--type", "image", image)
+	}
+	return cmd.Run() == nil
+}
+
+// Pull copies the registry image into containers-storage.
+func (b *skopeoBackend) Pull(ctx context.Context, image, platform string, verbose bool) error {
+	runCtx, cancel := commandTimeout(ctx, TimeoutScan)
+	defer cancel()
+	var args []string
+	if b.builder != "" {
+		args = []string{"pull"}
+		if platform != "" {
+			args = append(args, "--platform", platform)
+		}
+		_, err := runCommand(commandContext(runCtx, b.builder, append(args, image)...), verbose)
+		return err
+	}
+
+	args = []string{"copy"}
+	if platform != "" {
+		parts := strings.Split(platform, "/")
+		args = append(args, "--override-os", parts[0], "--override-arch", parts[1])
+		if len(parts) > 2 {
+			args = append(args, "--override-variant", parts[2])
+		}
+	}
+	args = append(args, "docker://"+image, "containers-storage:"+image)
+	_, err := runCommand(commandContext(runCtx, b.binary, args...), verbose)
+	return err
+}
+
+// ScanSource returns "podman": scanners read containers-storage through
+// podman, which shares the store with skopeo and buildah. Without podman no
+// scanner can read the pulled images.
+func (b *skopeoBackend) ScanSource(tool string) (string, error) {
+	if _, err := exec.LookPath(BackendPodman); err != nil {
+		return "", unsupportedSource(tool, b.Name()+" (without podman)")
+	}
+	return "podman", nil
+}
+
+// Manifest returns the raw registry manifest. Manifest lists only exist in
+// registries, so the local copy is never consulted.
+func (b *skopeoBackend) Manifest(ctx context.Context, image string, verbose bool) ([]byte, error) {
+	return b.inspect(ctx, "docker://"+image, verbose, "--raw")
+}
+
+// parseSkopeoInspect combines the output of 'skopeo inspect' and the raw image
+// config into the ImageStats produced by 'docker image inspect'. The size is
+// the sum of the (compressed) layer sizes, as skopeo does not unpack layers.
+func parseSkopeoInspect(summary, config []byte, image string) (*types.ImageStats, error) {
+	var s struct {
+		Name       string `json:"Name"`
+		Digest     string `json:"Digest"`
+		LayersData []struct {
+			Size int64 `json:"Size"`
+		} `json:"LayersData"`
+	}
+	if err := json.Unmarshal(summary, &s); err != nil {
+		return nil, parseError(BackendSkopeo, fmt.Errorf("failed to unmarshal skopeo inspect output: %w", err))
+	}
+
+	var c struct {
+		Created      string `json:"created"`
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+		Config       struct {
+			User         string              `json:"User"`
+			WorkingDir   string              `json:"WorkingDir"`
+			Entrypoint   []string            `json:"Entrypoint"`
+			Cmd          []string            `json:"Cmd"`
+			Env          []string            `json:"Env"`
+			ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+			Volumes      map[string]struct{} `json:"Volumes"`
+			Labels       map[string]string   `json:"Labels"`
+			Healthcheck  *inspectHealthcheck `json:"Healthcheck"`
+		} `json:"config"`
+		RootFS struct {
+			DiffIDs []string `json:"diff_ids"`
+		} `json:"rootfs"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, parseError(BackendSkopeo, fmt.Errorf("failed to unmarshal image config of %s: %w", image, err))
+	}
+
+	stats := &types.ImageStats{
+		ImageTag:     image,
+		ImageID:      configDigest(config),
+		Architecture: c.Architecture,
+		OS:           c.OS,
+		TotalLayers:  len(c.RootFS.DiffIDs),
+		Config: &types.ImageConfig{
+			User:         c.Config.User,
+			WorkingDir:   c.Config.WorkingDir,
+			Entrypoint:   c.Config.Entrypoint,
+			Cmd:          c.Config.Cmd,
+			Env:          c.Config.Env,
+			ExposedPorts: sortedKeys(c.Config.ExposedPorts),
+			Volumes:      sortedKeys(c.Config.Volumes),
+			Labels:       c.Config.Labels,
+		},
+	}
+	if s.Name != "" && s.Digest != "" {
+		stats.RepoDigest = s.Name + "@" + s.Digest
+	}
+	for _, l := range s.LayersData {
+		stats.SizeBytes += l.Size
+	}
+	if created, err := time.Parse(time.RFC3339Nano, c.Created); err == nil {
+		stats.Created = created
+	}
+	if hc := c.Config.Healthcheck; hc != nil && len(hc.Test) > 0 {
+		stats.Config.Healthcheck = &types.Healthcheck{
+			Test:        hc.Test,
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
+	}
+	return stats, nil
+}
+
+// configDigest returns the image ID of a raw image config: its sha256 digest.
+func configDigest(config []byte) string {
+	sum := sha256.Sum256(config)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
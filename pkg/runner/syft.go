@@ -4,14 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"sort"
+	"time"
 
 	"github.com/northcutted/dock-docs/pkg/types"
 )
 
 // SyftRunner runs 'syft <image> -o json'
 type SyftRunner struct {
+	// Timeout overrides the timeout of each command (default: TimeoutScan
+	// for the scan, TimeoutInspect for version queries).
+	Timeout time.Duration
+	// Args are extra command-line arguments appended to the syft command.
+	Args []string
+
 	binary string
 }
 
@@ -27,17 +33,39 @@ func (r *SyftRunner) IsAvailable() bool {
 	return false
 }
 
+// CacheKey identifies the syft build, so cached results are invalidated
+// when syft is upgraded.
+func (r *SyftRunner) CacheKey(ctx context.Context) (string, error) {
+	if r.binary == "" && !r.IsAvailable() {
+		return "", toolMissing("syft")
+	}
+	ctx = withCommandTimeout(ctx, r.Timeout)
+	version, err := toolVersion(ctx, r.binary, "version")
+	if err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// ArgsKey identifies the extra arguments passed to syft, which may change
+// its output.
+func (r *SyftRunner) ArgsKey() string { return argsKey(r.Args) }
+
 // Run executes 'syft <image> -o json' and parses the result.
 // The provided context is used as the parent for the command timeout.
 func (r *SyftRunner) Run(ctx context.Context, image string, verbose bool) (*types.ImageStats, error) {
 	if r.binary == "" {
 		if !r.IsAvailable() {
-			return nil, fmt.Errorf("syft not found")
+			return nil, toolMissing("syft")
 		}
 	}
-	runCtx, cancel := context.WithTimeout(ctx, TimeoutScan)
+	target, err := scanImage("syft", image)
+	if err != nil {
+		return nil, err
+	}
+	runCtx, cancel := commandTimeout(withCommandTimeout(ctx, r.Timeout), TimeoutScan)
 	defer cancel()
-	cmd := exec.CommandContext(runCtx, r.binary, image, "-o", "json")
+	cmd := commandContext(runCtx, r.binary, append([]string{target, "-o", "json"}, r.Args...)...)
 	output, err := runCommand(cmd, verbose)
 	if err != nil {
 		return nil, err
@@ -55,14 +83,17 @@ func parseSyftOutput(output []byte) (*types.ImageStats, error) {
 			Version string `json:"version"`
 		} `json:"distro"`
 		Artifacts []struct {
-			Name    string `json:"name"`
-			Version string `json:"version"`
-			Type    string `json:"type"`
+			Name     string            `json:"name"`
+			Version  string            `json:"version"`
+			Type     string            `json:"type"`
+			PURL     string            `json:"purl"`
+			Licenses []json.RawMessage `json:"licenses"`
+			CPEs     []json.RawMessage `json:"cpes"`
 		} `json:"artifacts"`
 	}
 
 	if err := json.Unmarshal(output, &syftOutput); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal syft output: %w", err)
+		return nil, parseError("syft", fmt.Errorf("failed to unmarshal syft output: %w", err))
 	}
 
 	stats := &types.ImageStats{
@@ -86,8 +117,12 @@ func parseSyftOutput(output []byte) (*types.ImageStats, error) {
 		}
 		seen[key] = true
 		stats.Packages = append(stats.Packages, types.PackageSummary{
-			Name:    artifact.Name,
-			Version: artifact.Version,
+			Name:     artifact.Name,
+			Version:  artifact.Version,
+			Type:     artifact.Type,
+			Licenses: syftLicenses(artifact.Licenses),
+			PURL:     artifact.PURL,
+			CPEs:     syftCPEs(artifact.CPEs),
 		})
 	}
 
@@ -99,3 +134,55 @@ func parseSyftOutput(output []byte) (*types.ImageStats, error) {
 
 	return stats, nil
 }
+
+// syftLicenses extracts license identifiers from a syft artifact. Older syft
+// releases emit plain strings; newer ones emit objects with "value" and an
+// optional normalized "spdxExpression", which is preferred.
+func syftLicenses(raw []json.RawMessage) []string {
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		var name string
+		if err := json.Unmarshal(r, &name); err != nil {
+			var obj struct {
+				Value          string `json:"value"`
+				SPDXExpression string `json:"spdxExpression"`
+			}
+			if json.Unmarshal(r, &obj) != nil {
+				continue
+			}
+			name = obj.SPDXExpression
+			if name == "" {
+				name = obj.Value
+			}
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		licenses = append(licenses, name)
+	}
+	return licenses
+}
+
+// syftCPEs extracts CPE strings from either the legacy string form or the
+// newer {"cpe": ..., "source": ...} object form.
+func syftCPEs(raw []json.RawMessage) []string {
+	var cpes []string
+	for _, r := range raw {
+		var cpe string
+		if err := json.Unmarshal(r, &cpe); err != nil {
+			var obj struct {
+				CPE string `json:"cpe"`
+			}
+			if json.Unmarshal(r, &obj) != nil {
+				continue
+			}
+			cpe = obj.CPE
+		}
+		if cpe != "" {
+			cpes = append(cpes, cpe)
+		}
+	}
+	return cpes
+}
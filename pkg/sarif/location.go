@@ -0,0 +1,108 @@
+package sarif
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// installCommands are the package manager invocations that add packages to
+// an image. A RUN instruction containing one of them and naming a package is
+// taken to be where that package was introduced.
+var installCommands = []string{
+	"apk add", "apt-get install", "apt install", "yum install", "dnf install", "microdnf install",
+	"zypper install", "zypper in ", "pip install", "pip3 install", "pipx install", "poetry add",
+	"npm install", "npm i ", "yarn add", "pnpm add", "gem install", "bundle add",
+	"go install", "go get", "cargo install", "composer require",
+}
+
+// artifactURI returns path relative to the working directory with forward
+// slashes, as code-scanning tools resolve locations against the repository
+// root. Paths outside the working directory are kept absolute.
+func artifactURI(path string) string {
+	if filepath.IsAbs(path) {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// finalStage returns the instructions of the last build stage, starting with
+// its FROM, since only that stage's packages end up in the image.
+func finalStage(doc *parser.Documentation) []parser.Instruction {
+	if doc == nil {
+		return nil
+	}
+	for i := len(doc.Instructions) - 1; i >= 0; i-- {
+		if doc.Instructions[i].Command == "FROM" {
+			return doc.Instructions[i:]
+		}
+	}
+	return doc.Instructions
+}
+
+// lines returns the source lines of an instruction.
+func lines(in parser.Instruction) [2]int {
+	return [2]int{in.StartLine, in.EndLine}
+}
+
+// installLines locates the RUN instruction of the final stage that installed
+// pkg, falling back to the stage's FROM line for packages inherited from the
+// base image. The zero value means no location is known.
+func installLines(doc *parser.Documentation, pkg string) [2]int {
+	stage := finalStage(doc)
+	if len(stage) == 0 {
+		return [2]int{}
+	}
+	named := wordPattern(pkg, `[=@<>~:]`)
+	for _, in := range stage {
+		if in.Command == "RUN" && installs(in.Original) && named.MatchString(in.Original) {
+			return lines(in)
+		}
+	}
+	if stage[0].Command == "FROM" {
+		return lines(stage[0])
+	}
+	return [2]int{}
+}
+
+// driftLines locates the ENV, LABEL or EXPOSE instruction that documents a
+// drifted setting. Undocumented settings have no such instruction and are
+// reported at the final FROM, as they come from the base image or the build.
+func driftLines(doc *parser.Documentation, f types.DriftFinding) [2]int {
+	stage := finalStage(doc)
+	named := wordPattern(strings.SplitN(f.Name, "/", 2)[0], `[=/]`)
+	for _, in := range stage {
+		if in.Command == f.Instruction && named.MatchString(in.Original) {
+			return lines(in)
+		}
+	}
+	if len(stage) > 0 && stage[0].Command == "FROM" {
+		return lines(stage[0])
+	}
+	return [2]int{}
+}
+
+// installs reports whether a RUN command invokes a package manager install.
+func installs(cmd string) bool {
+	normalized := strings.Join(strings.Fields(cmd), " ") + " "
+	for _, c := range installCommands {
+		if strings.Contains(normalized, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordPattern matches name as a whole shell word, optionally followed by one
+// of the version or value separators in sep.
+func wordPattern(name, sep string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[\s'"])` + regexp.QuoteMeta(name) + `($|[\s'"\\]|` + sep + `)`)
+}
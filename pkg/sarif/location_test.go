@@ -0,0 +1,65 @@
+package sarif
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestInstallLines(t *testing.T) {
+	doc := parseDockerfile(t, dockerfile)
+	tests := []struct {
+		pkg  string
+		want [2]int
+	}{
+		{"curl", [2]int{5, 6}},
+		{"ca-certificates", [2]int{5, 6}},
+		{"cert", [2]int{4, 4}}, // substrings of other packages do not match
+		{"musl", [2]int{4, 4}},
+	}
+	for _, tt := range tests {
+		if got := installLines(doc, tt.pkg); got != tt.want {
+			t.Errorf("installLines(%q) = %v, want %v", tt.pkg, got, tt.want)
+		}
+	}
+	if got := installLines(nil, "curl"); got != [2]int{} {
+		t.Errorf("installLines(nil) = %v, want zero", got)
+	}
+}
+
+func TestDriftLines(t *testing.T) {
+	doc := parseDockerfile(t, dockerfile)
+	tests := []struct {
+		f    types.DriftFinding
+		want [2]int
+	}{
+		{types.DriftFinding{Instruction: "ENV", Name: "APP_HOME"}, [2]int{7, 7}},
+		{types.DriftFinding{Instruction: "EXPOSE", Name: "8080/tcp"}, [2]int{8, 8}},
+		{types.DriftFinding{Instruction: "ENV", Name: "APP"}, [2]int{4, 4}},
+	}
+	for _, tt := range tests {
+		if got := driftLines(doc, tt.f); got != tt.want {
+			t.Errorf("driftLines(%s %s) = %v, want %v", tt.f.Instruction, tt.f.Name, got, tt.want)
+		}
+	}
+}
+
+func TestArtifactURI(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"Dockerfile":            "Dockerfile",
+		"./build/../Dockerfile": "Dockerfile",
+		filepath.Join(cwd, "docker", "Dockerfile"):     "docker/Dockerfile",
+		filepath.Join(filepath.Dir(cwd), "Dockerfile"): filepath.ToSlash(filepath.Join(filepath.Dir(cwd), "Dockerfile")),
+	}
+	for in, want := range tests {
+		if got := artifactURI(in); got != want {
+			t.Errorf("artifactURI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Package sarif encodes vulnerabilities, Dockerfile drift and Dockerfile lint
+// warnings as a SARIF 2.1.0 log, so results can be uploaded to code-scanning
+// dashboards alongside other scanners.
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Version is the SARIF specification version of generated logs.
+const Version = "2.1.0"
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName  = "dock-docs"
+	toolURI   = "https://github.com/northcutted/dock-docs"
+
+	// fingerprintKey versions the partial fingerprint scheme so dashboards
+	// can track findings across runs.
+	fingerprintKey = "dockDocsFinding/v1"
+)
+
+type sarifLog struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []run  `json:"runs"`
+}
+
+type run struct {
+	Tool              tool               `json:"tool"`
+	AutomationDetails *automationDetails `json:"automationDetails,omitempty"`
+	Results           []result           `json:"results"`
+}
+
+type tool struct {
+	Driver driver `json:"driver"`
+}
+
+type driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []rule `json:"rules"`
+}
+
+type automationDetails struct {
+	ID string `json:"id"`
+}
+
+type rule struct {
+	ID                   string          `json:"id"`
+	Name                 string          `json:"name,omitempty"`
+	ShortDescription     message         `json:"shortDescription"`
+	FullDescription      *message        `json:"fullDescription,omitempty"`
+	HelpURI              string          `json:"helpUri,omitempty"`
+	Help                 *message        `json:"help,omitempty"`
+	DefaultConfiguration *configuration  `json:"defaultConfiguration,omitempty"`
+	Properties           *ruleProperties `json:"properties,omitempty"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+type configuration struct {
+	Level string `json:"level"`
+}
+
+type ruleProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+type result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             message           `json:"message"`
+	Locations           []location        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Suppressions        []suppression     `json:"suppressions,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type location struct {
+	PhysicalLocation physicalLocation `json:"physicalLocation"`
+}
+
+type physicalLocation struct {
+	ArtifactLocation artifactLocation `json:"artifactLocation"`
+	Region           *region          `json:"region,omitempty"`
+}
+
+type artifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type region struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type suppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// builder accumulates rules and results for a single run.
+type builder struct {
+	doc     *parser.Documentation
+	uri     string
+	image   string
+	rules   []rule
+	index   map[string]int
+	results []result
+}
+
+// Encode writes a SARIF log with one result per vulnerability (suppressed
+// findings are marked as accepted), per drift finding and per lint warning.
+// Each CVE becomes a rule carrying its severity and advisory link. Results are
+// located in the Dockerfile: vulnerabilities at the instruction that
+// installed the package, or the final FROM when the package was inherited
+// from the base image. Either argument may be nil.
+func Encode(doc *parser.Documentation, stats *types.ImageStats) ([]byte, error) {
+	if doc == nil && stats == nil {
+		return nil, fmt.Errorf("nothing to encode: no Dockerfile or analysis results")
+	}
+	b := &builder{doc: doc, index: make(map[string]int)}
+	if doc != nil && doc.Path != "" {
+		b.uri = artifactURI(doc.Path)
+	}
+
+	r := run{Tool: tool{Driver: driver{Name: toolName, InformationURI: toolURI}}}
+	if stats != nil {
+		b.image = stats.ImageTag
+		if b.image != "" {
+			r.AutomationDetails = &automationDetails{ID: toolName + "/" + b.image + "/"}
+		}
+		for _, v := range stats.Vulnerabilities {
+			b.addVulnerability(v, nil)
+		}
+		for _, s := range stats.Suppressed {
+			b.addVulnerability(s.Vulnerability, &suppression{Kind: "external", Status: "accepted", Justification: s.Justification})
+		}
+		for _, f := range stats.Drift {
+			b.addDrift(f)
+		}
+	}
+	if doc != nil {
+		for _, d := range doc.Diagnostics {
+			b.addLint(d)
+		}
+	}
+
+	r.Tool.Driver.Rules = b.rules
+	r.Results = b.results
+	if r.Tool.Driver.Rules == nil {
+		r.Tool.Driver.Rules = []rule{}
+	}
+	if r.Results == nil {
+		r.Results = []result{}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Schema: schemaURI, Version: Version, Runs: []run{r}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SARIF log: %w", err)
+	}
+	return data, nil
+}
+
+// rule registers r on first use and returns its index.
+func (b *builder) rule(r rule) int {
+	if i, ok := b.index[r.ID]; ok {
+		return i
+	}
+	b.rules = append(b.rules, r)
+	b.index[r.ID] = len(b.rules) - 1
+	return len(b.rules) - 1
+}
+
+// add appends a result for the rule at index, located at line when known.
+func (b *builder) add(ruleIndex int, level, text string, line [2]int, fingerprint ...string) *result {
+	res := result{
+		RuleID:              b.rules[ruleIndex].ID,
+		RuleIndex:           ruleIndex,
+		Level:               level,
+		Message:             message{Text: text},
+		PartialFingerprints: map[string]string{fingerprintKey: hash(append([]string{b.image, b.rules[ruleIndex].ID}, fingerprint...)...)},
+	}
+	if b.uri != "" {
+		loc := location{PhysicalLocation: physicalLocation{ArtifactLocation: artifactLocation{URI: b.uri}}}
+		if line[0] > 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: line[0], EndLine: line[1]}
+		}
+		res.Locations = []location{loc}
+	}
+	b.results = append(b.results, res)
+	return &b.results[len(b.results)-1]
+}
+
+func (b *builder) addVulnerability(v types.Vulnerability, sup *suppression) {
+	level := severityLevel(v.Severity)
+	r := rule{
+		ID:                   v.ID,
+		Name:                 strings.NewReplacer("-", "", "_", "").Replace(v.ID),
+		ShortDescription:     message{Text: fmt.Sprintf("%s: %s severity vulnerability", v.ID, v.Severity)},
+		HelpURI:              v.URL(),
+		Help:                 &message{Text: fmt.Sprintf("%s (%s severity).", v.ID, v.Severity)},
+		DefaultConfiguration: &configuration{Level: level},
+		Properties: &ruleProperties{
+			Tags:             []string{"security", "vulnerability"},
+			SecuritySeverity: securitySeverity(v),
+		},
+	}
+	if r.HelpURI != "" {
+		r.Help.Text += " See " + r.HelpURI
+	}
+	if v.Description != "" {
+		r.FullDescription = &message{Text: v.Description}
+	}
+
+	text := fmt.Sprintf("%s %s is affected by %s (%s severity).", v.Package, v.Version, v.ID, v.Severity)
+	if v.Fixable() {
+		text += fmt.Sprintf(" Fixed in %s.", strings.Join(v.FixedInVersions, ", "))
+	}
+	res := b.add(b.rule(r), level, text, installLines(b.doc, v.Package), v.Package, v.Version)
+	res.Properties = map[string]any{"package": v.Package, "installedVersion": v.Version}
+	if v.PackageType != "" {
+		res.Properties["packageType"] = v.PackageType
+	}
+	if len(v.FixedInVersions) > 0 {
+		res.Properties["fixedVersions"] = v.FixedInVersions
+	}
+	if sup != nil {
+		res.Suppressions = []suppression{*sup}
+	}
+}
+
+// driftDescriptions describes the drift rules, one per finding kind.
+var driftDescriptions = map[string]string{
+	types.DriftMissing:      "Documented setting is missing from the image",
+	types.DriftUndocumented: "Image setting is not documented in the Dockerfile",
+	types.DriftChanged:      "Documented value differs from the image",
+}
+
+func (b *builder) addDrift(f types.DriftFinding) {
+	i := b.rule(rule{
+		ID:                   "drift/" + f.Kind,
+		Name:                 "Drift" + strings.ToUpper(f.Kind[:1]) + f.Kind[1:],
+		ShortDescription:     message{Text: driftDescriptions[f.Kind]},
+		DefaultConfiguration: &configuration{Level: "warning"},
+		Properties:           &ruleProperties{Tags: []string{"drift"}},
+	})
+	b.add(i, "warning", f.Message(), driftLines(b.doc, f), f.Instruction, f.Name)
+}
+
+func (b *builder) addLint(d parser.Diagnostic) {
+	i := b.rule(rule{
+		ID:                   "lint/" + d.Rule,
+		Name:                 d.Rule,
+		ShortDescription:     message{Text: d.Description},
+		HelpURI:              d.URL,
+		DefaultConfiguration: &configuration{Level: "warning"},
+		Properties:           &ruleProperties{Tags: []string{"dockerfile", "lint"}},
+	})
+	b.add(i, "warning", d.Message, [2]int{d.StartLine, d.EndLine}, d.Message, strconv.Itoa(d.StartLine))
+}
+
+// severityLevel maps a vulnerability severity to a SARIF result level.
+func severityLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity returns the numeric severity code-scanning dashboards use
+// to rank security results: the CVSS score when known, otherwise a score
+// inside the band of the textual severity.
+func securitySeverity(v types.Vulnerability) string {
+	score := v.CVSSScore
+	if score <= 0 {
+		switch strings.ToLower(v.Severity) {
+		case "critical":
+			score = 9.5
+		case "high":
+			score = 8.0
+		case "medium":
+			score = 5.5
+		case "low":
+			score = 2.0
+		}
+	}
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+// hash returns a stable fingerprint of parts.
+func hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
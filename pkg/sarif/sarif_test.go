@@ -0,0 +1,173 @@
+package sarif
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/parser"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+const dockerfile = `FROM golang:1.22 AS build
+RUN go build -o /app .
+
+FROM alpine:3.19
+RUN apk add --no-cache \
+    curl=8.5.0-r0 ca-certificates
+ENV APP_HOME=/opt/app
+EXPOSE 8080
+CMD /app
+`
+
+// parseDockerfile writes content to a temporary Dockerfile and parses it.
+func parseDockerfile(t *testing.T, content string) *parser.Documentation {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write Dockerfile: %v", err)
+	}
+	doc, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return doc
+}
+
+func TestEncode(t *testing.T) {
+	doc := parseDockerfile(t, dockerfile)
+	stats := &types.ImageStats{
+		ImageTag: "app:1.0",
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-2024-0001", Severity: "Critical", Package: "curl", Version: "8.5.0-r0", FixState: types.FixStateFixed, FixedInVersions: []string{"8.5.0-r1"}, CVSSScore: 9.1, Description: "heap overflow"},
+			{ID: "CVE-2024-0002", Severity: "Medium", Package: "musl", Version: "1.2.4-r2"},
+			{ID: "CVE-2024-0001", Severity: "Critical", Package: "libcurl", Version: "8.5.0-r0"},
+		},
+		Suppressed: []types.SuppressedVulnerability{
+			{Vulnerability: types.Vulnerability{ID: "CVE-2024-0003", Severity: "Low", Package: "ca-certificates", Version: "1"}, Justification: "not reachable"},
+		},
+		Drift: []types.DriftFinding{
+			{Kind: types.DriftChanged, Instruction: "ENV", Name: "APP_HOME", Dockerfile: "/opt/app", Image: "/srv"},
+			{Kind: types.DriftUndocumented, Instruction: "EXPOSE", Name: "9090/tcp", Image: "9090/tcp"},
+		},
+	}
+
+	data, err := Encode(doc, stats)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: %+v", log)
+	}
+	r := log.Runs[0]
+	if r.AutomationDetails == nil || r.AutomationDetails.ID != "dock-docs/app:1.0/" {
+		t.Errorf("unexpected automation details: %+v", r.AutomationDetails)
+	}
+
+	wantRules := []string{"CVE-2024-0001", "CVE-2024-0002", "CVE-2024-0003", "drift/changed", "drift/undocumented", "lint/JSONArgsRecommended"}
+	if len(r.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("expected rules %v, got %+v", wantRules, r.Tool.Driver.Rules)
+	}
+	for i, id := range wantRules {
+		if r.Tool.Driver.Rules[i].ID != id {
+			t.Errorf("rules[%d] = %q, want %q", i, r.Tool.Driver.Rules[i].ID, id)
+		}
+	}
+	cve := r.Tool.Driver.Rules[0]
+	if cve.Properties.SecuritySeverity != "9.1" || cve.HelpURI != "https://nvd.nist.gov/vuln/detail/CVE-2024-0001" ||
+		cve.Help == nil || !strings.HasSuffix(cve.Help.Text, "See "+cve.HelpURI) ||
+		cve.FullDescription == nil || cve.FullDescription.Text != "heap overflow" || cve.DefaultConfiguration.Level != "error" {
+		t.Errorf("unexpected CVE rule: %+v", cve)
+	}
+
+	tests := []struct {
+		ruleID    string
+		ruleIndex int
+		level     string
+		startLine int
+	}{
+		{"CVE-2024-0001", 0, "error", 5},   // installed by the final stage's RUN
+		{"CVE-2024-0002", 1, "warning", 4}, // inherited from the base image
+		{"CVE-2024-0001", 0, "error", 4},
+		{"CVE-2024-0003", 2, "note", 5},
+		{"drift/changed", 3, "warning", 7},
+		{"drift/undocumented", 4, "warning", 4},
+		{"lint/JSONArgsRecommended", 5, "warning", 9},
+	}
+	if len(r.Results) != len(tests) {
+		t.Fatalf("expected %d results, got %d", len(tests), len(r.Results))
+	}
+	for i, tt := range tests {
+		res := r.Results[i]
+		if res.RuleID != tt.ruleID || res.RuleIndex != tt.ruleIndex || res.Level != tt.level {
+			t.Errorf("results[%d] = %s/%d/%s, want %s/%d/%s", i, res.RuleID, res.RuleIndex, res.Level, tt.ruleID, tt.ruleIndex, tt.level)
+		}
+		if len(res.Locations) != 1 || res.Locations[0].PhysicalLocation.Region == nil {
+			t.Fatalf("results[%d] has no region: %+v", i, res.Locations)
+		}
+		if got := res.Locations[0].PhysicalLocation.Region.StartLine; got != tt.startLine {
+			t.Errorf("results[%d] (%s) startLine = %d, want %d", i, tt.ruleID, got, tt.startLine)
+		}
+		if res.PartialFingerprints[fingerprintKey] == "" {
+			t.Errorf("results[%d] missing fingerprint", i)
+		}
+	}
+	if r.Results[0].Message.Text != "curl 8.5.0-r0 is affected by CVE-2024-0001 (Critical severity). Fixed in 8.5.0-r1." {
+		t.Errorf("unexpected message: %q", r.Results[0].Message.Text)
+	}
+	if r.Results[0].PartialFingerprints[fingerprintKey] == r.Results[2].PartialFingerprints[fingerprintKey] {
+		t.Error("results for different packages should have different fingerprints")
+	}
+	if s := r.Results[3].Suppressions; len(s) != 1 || s[0].Status != "accepted" || s[0].Justification != "not reachable" {
+		t.Errorf("unexpected suppressions: %+v", s)
+	}
+}
+
+func TestEncode_WithoutDockerfileOrStats(t *testing.T) {
+	data, err := Encode(nil, &types.ImageStats{Vulnerabilities: []types.Vulnerability{{ID: "CVE-1", Severity: "High", Package: "a", Version: "1"}}})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if res := log.Runs[0].Results; len(res) != 1 || res[0].Locations != nil {
+		t.Errorf("expected one result without locations, got %+v", res)
+	}
+
+	data, err = Encode(parseDockerfile(t, "FROM alpine\n"), nil)
+	if err != nil {
+		t.Fatalf("Encode(lint only) error = %v", err)
+	}
+	if err := json.Unmarshal(data, &log); err != nil || log.Runs[0].Results == nil || log.Runs[0].Tool.Driver.Rules == nil {
+		t.Errorf("expected empty (non-null) results and rules, got %s", data)
+	}
+
+	if _, err := Encode(nil, nil); err == nil {
+		t.Error("Encode(nil, nil): expected error")
+	}
+}
+
+func TestSecuritySeverity(t *testing.T) {
+	tests := []struct {
+		v    types.Vulnerability
+		want string
+	}{
+		{types.Vulnerability{Severity: "High", CVSSScore: 7.5}, "7.5"},
+		{types.Vulnerability{Severity: "Critical"}, "9.5"},
+		{types.Vulnerability{Severity: "Low"}, "2.0"},
+		{types.Vulnerability{Severity: "Unknown"}, "0.0"},
+	}
+	for _, tt := range tests {
+		if got := securitySeverity(tt.v); got != tt.want {
+			t.Errorf("securitySeverity(%+v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
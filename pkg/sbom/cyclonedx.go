@@ -0,0 +1,356 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/license"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// CycloneDXVersion is the CycloneDX specification version of generated BOMs.
+const CycloneDXVersion = "1.5"
+
+type cdxBOM struct {
+	BOMFormat       string      `json:"bomFormat"`
+	SpecVersion     string      `json:"specVersion"`
+	SerialNumber    string      `json:"serialNumber"`
+	Version         int         `json:"version"`
+	Metadata        cdxMetadata `json:"metadata"`
+	Components      []cdxComp   `json:"components"`
+	Dependencies    []cdxDep    `json:"dependencies,omitempty"`
+	Vulnerabilities []cdxVuln   `json:"vulnerabilities,omitzero"`
+}
+
+type cdxMetadata struct {
+	Timestamp string      `json:"timestamp"`
+	Tools     cdxToolList `json:"tools"`
+	Component cdxComp     `json:"component"`
+}
+
+type cdxToolList struct {
+	Components []cdxComp `json:"components"`
+}
+
+type cdxComp struct {
+	BOMRef     string       `json:"bom-ref,omitempty"`
+	Type       string       `json:"type"`
+	Name       string       `json:"name"`
+	Version    string       `json:"version,omitempty"`
+	Hashes     []cdxHash    `json:"hashes,omitempty"`
+	Licenses   []cdxLicense `json:"licenses,omitempty"`
+	PURL       string       `json:"purl,omitempty"`
+	CPE        string       `json:"cpe,omitempty"`
+	Properties []cdxProp    `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicense struct {
+	License    *cdxLicenseObject `json:"license,omitempty"`
+	Expression string            `json:"expression,omitempty"`
+}
+
+type cdxLicenseObject struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxProp struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDep struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+type cdxVuln struct {
+	BOMRef         string       `json:"bom-ref,omitempty"`
+	ID             string       `json:"id"`
+	Source         *cdxSource   `json:"source,omitempty"`
+	Ratings        []cdxRating  `json:"ratings,omitempty"`
+	Description    string       `json:"description,omitempty"`
+	Recommendation string       `json:"recommendation,omitempty"`
+	Analysis       *cdxAnalysis `json:"analysis,omitempty"`
+	Affects        []cdxAffect  `json:"affects,omitempty"`
+}
+
+type cdxSource struct {
+	URL string `json:"url,omitempty"`
+}
+
+type cdxRating struct {
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity"`
+	Method   string  `json:"method,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+type cdxAnalysis struct {
+	State         string   `json:"state,omitempty"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+}
+
+type cdxAffect struct {
+	Ref      string          `json:"ref"`
+	Versions []cdxAffectedAt `json:"versions,omitempty"`
+}
+
+type cdxAffectedAt struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+// CycloneDX encodes stats as a CycloneDX 1.5 JSON BOM. The image is the
+// metadata component, every package becomes a library (or operating-system)
+// component, and vulnerabilities reference the components they affect.
+// Suppressed findings are included with an analysis explaining why.
+func CycloneDX(stats *types.ImageStats) ([]byte, error) {
+	if stats == nil {
+		return nil, fmt.Errorf("no analysis results to encode")
+	}
+	created := now().UTC()
+
+	root := cdxComp{
+		BOMRef:  "image",
+		Type:    "container",
+		Name:    subject(stats),
+		Version: digest(stats),
+		PURL:    imagePURL(stats),
+	}
+	if alg, sum, ok := strings.Cut(digest(stats), ":"); ok && alg == "sha256" {
+		root.Hashes = []cdxHash{{Alg: "SHA-256", Content: sum}}
+	}
+	for _, p := range []cdxProp{
+		{"dock-docs:image:os", stats.OSDistro},
+		{"dock-docs:image:architecture", stats.Architecture},
+	} {
+		if p.Value != "" {
+			root.Properties = append(root.Properties, p)
+		}
+	}
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  CycloneDXVersion,
+		SerialNumber: "urn:uuid:" + uuid(stats, created),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: created.Format(time.RFC3339),
+			Tools:     cdxToolList{Components: []cdxComp{{Type: "application", Name: Tool}}},
+			Component: root,
+		},
+		Components: []cdxComp{},
+	}
+
+	refs := make(map[string]string, len(stats.Packages))
+	used := make(map[string]bool, len(stats.Packages))
+	deps := cdxDep{Ref: root.BOMRef, DependsOn: []string{}}
+	for _, p := range stats.Packages {
+		ref := uniqueRef(used, p.PURL, packageKey(p.Name, p.Version))
+		refs[packageKey(p.Name, p.Version)] = ref
+		deps.DependsOn = append(deps.DependsOn, ref)
+
+		c := cdxComp{
+			BOMRef:   ref,
+			Type:     "library",
+			Name:     p.Name,
+			Version:  p.Version,
+			Licenses: cdxLicenses(p.Licenses),
+			PURL:     p.PURL,
+		}
+		if p.Tier == types.TierOS {
+			c.Type = "operating-system"
+		}
+		if len(p.CPEs) > 0 {
+			c.CPE = p.CPEs[0]
+		}
+		for _, prop := range []cdxProp{
+			{"dock-docs:package:type", p.Type},
+			{"dock-docs:package:tier", p.Tier},
+			{"dock-docs:package:framework", p.Framework},
+		} {
+			if prop.Value != "" {
+				c.Properties = append(c.Properties, prop)
+			}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	bom.Dependencies = []cdxDep{deps}
+
+	// An empty section records that the image was scanned and is clean.
+	if stats.Has("grype") {
+		bom.Vulnerabilities = make([]cdxVuln, 0, len(stats.Vulnerabilities)+len(stats.Suppressed))
+	}
+	for _, v := range stats.Vulnerabilities {
+		bom.Vulnerabilities = append(bom.Vulnerabilities, cdxVulnerability(v, refs, used))
+	}
+	for _, s := range stats.Suppressed {
+		v := cdxVulnerability(s.Vulnerability, refs, used)
+		v.Analysis = cdxSuppression(s)
+		bom.Vulnerabilities = append(bom.Vulnerabilities, v)
+	}
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CycloneDX BOM: %w", err)
+	}
+	return data, nil
+}
+
+// cdxVulnerability converts a finding, pointing its affects entry at the
+// component of the vulnerable package when it is part of the BOM. Scanners
+// report a finding once per location, so its bom-ref is made unique.
+func cdxVulnerability(v types.Vulnerability, refs map[string]string, used map[string]bool) cdxVuln {
+	out := cdxVuln{
+		BOMRef:      uniqueRef(used, v.ID+"|"+packageKey(v.Package, v.Version)),
+		ID:          v.ID,
+		Ratings:     []cdxRating{{Score: v.CVSSScore, Severity: cdxSeverity(v.Severity), Method: cvssMethod(v.CVSSVector), Vector: v.CVSSVector}},
+		Description: v.Description,
+	}
+	if u := v.URL(); u != "" {
+		out.Source = &cdxSource{URL: u}
+	}
+	if v.Fixable() {
+		out.Recommendation = fmt.Sprintf("Upgrade %s to %s", v.Package, strings.Join(v.FixedInVersions, " or "))
+	}
+	if ref, ok := refs[packageKey(v.Package, v.Version)]; ok {
+		out.Affects = []cdxAffect{{Ref: ref, Versions: []cdxAffectedAt{{Version: v.Version, Status: "affected"}}}}
+	}
+	return out
+}
+
+// cdxLicenses converts package licenses: licenses on the SPDX list are
+// emitted by ID, valid compound SPDX expressions (and "or later" IDs such as
+// "GPL-2.0+") as expressions, and everything else as license names.
+func cdxLicenses(licenses []string) []cdxLicense {
+	var out []cdxLicense
+	for _, l := range licenses {
+		id, ok := license.SPDXID(l)
+		switch {
+		case ok && !strings.HasSuffix(id, "+"):
+			out = append(out, cdxLicense{License: &cdxLicenseObject{ID: id}})
+		case ok:
+			out = append(out, cdxLicense{Expression: id})
+		case isExpression(l):
+			out = append(out, cdxLicense{Expression: l})
+		default:
+			out = append(out, cdxLicense{License: &cdxLicenseObject{Name: l}})
+		}
+	}
+	return out
+}
+
+// cdxSeverity maps a severity to the CycloneDX enumeration.
+func cdxSeverity(severity string) string {
+	switch s := strings.ToLower(severity); s {
+	case "critical", "high", "medium", "low", "info", "none":
+		return s
+	case "negligible":
+		return "info"
+	default:
+		return "unknown"
+	}
+}
+
+// cvssMethod returns the CycloneDX rating method for a CVSS vector.
+func cvssMethod(vector string) string {
+	switch {
+	case strings.HasPrefix(vector, "CVSS:4"):
+		return "CVSSv4"
+	case strings.HasPrefix(vector, "CVSS:3.1"):
+		return "CVSSv31"
+	case strings.HasPrefix(vector, "CVSS:3"):
+		return "CVSSv3"
+	case strings.HasPrefix(vector, "AV:"):
+		return "CVSSv2"
+	case vector != "":
+		return "other"
+	default:
+		return ""
+	}
+}
+
+// uniqueRef returns the first non-empty candidate, suffixed if needed so
+// that every bom-ref in the document is unique.
+func uniqueRef(used map[string]bool, candidates ...string) string {
+	ref := ""
+	for _, c := range candidates {
+		if c != "" {
+			ref = c
+			break
+		}
+	}
+	base := ref
+	for i := 2; used[ref]; i++ {
+		ref = fmt.Sprintf("%s#%d", base, i)
+	}
+	used[ref] = true
+	return ref
+}
+
+// isExpression reports whether a license string is a valid compound SPDX
+// expression ("MIT OR Apache-2.0") rather than a single license name.
+func isExpression(license string) bool {
+	for _, f := range strings.Fields(license) {
+		switch f {
+		case "AND", "OR", "WITH":
+			return validExpression(license)
+		}
+	}
+	return false
+}
+
+// cdxVEXJustifications maps OpenVEX not_affected justifications to their
+// CycloneDX equivalents.
+var cdxVEXJustifications = map[string]string{
+	"component_not_present":                             "code_not_present",
+	"vulnerable_code_not_present":                       "code_not_present",
+	"vulnerable_code_not_in_execute_path":               "code_not_reachable",
+	"vulnerable_code_cannot_be_controlled_by_adversary": "requires_environment",
+	"inline_mitigations_already_exist":                  "protected_by_mitigating_control",
+}
+
+// vexCDXJustifications is the inverse of cdxVEXJustifications, used when
+// reading a CycloneDX analysis back.
+var vexCDXJustifications = map[string]string{
+	"code_not_present":                "vulnerable_code_not_present",
+	"code_not_reachable":              "vulnerable_code_not_in_execute_path",
+	"requires_environment":            "vulnerable_code_cannot_be_controlled_by_adversary",
+	"protected_by_mitigating_control": "inline_mitigations_already_exist",
+}
+
+// OpenVEXJustification returns the OpenVEX justification matching a CycloneDX
+// analysis justification (e.g., "code_not_reachable"), or "" if none does.
+func OpenVEXJustification(cdx string) string {
+	return vexCDXJustifications[cdx]
+}
+
+// cdxSuppression records how a suppressed finding was triaged. VEX statuses
+// map to the matching analysis state; a plain accepted risk is exploitable
+// and will not be fixed.
+func cdxSuppression(s types.SuppressedVulnerability) *cdxAnalysis {
+	a := &cdxAnalysis{Detail: s.Justification}
+	switch s.Status {
+	case "not_affected":
+		a.State = "not_affected"
+		a.Justification = cdxVEXJustifications[s.VEXJustification]
+	case "false_positive":
+		a.State = "false_positive"
+	case "fixed":
+		a.State = "resolved"
+	default:
+		a.State = "exploitable"
+		a.Response = []string{"will_not_fix"}
+	}
+	return a
+}
@@ -0,0 +1,207 @@
+package sbom
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestCycloneDX(t *testing.T) {
+	fixedNow(t)
+	data, err := CycloneDX(sampleStats())
+	if err != nil {
+		t.Fatalf("CycloneDX() error = %v", err)
+	}
+
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || bom.Metadata.Timestamp != "2024-05-01T12:00:00Z" {
+		t.Errorf("unexpected header: %+v", bom)
+	}
+	root := bom.Metadata.Component
+	if root.Type != "container" || root.Name != "ghcr.io/acme/app:1.0" || root.Version != "sha256:abc123" || len(root.Hashes) != 1 {
+		t.Errorf("unexpected metadata component: %+v", root)
+	}
+
+	if len(bom.Components) != 3 {
+		t.Fatalf("expected 3 components, got %d", len(bom.Components))
+	}
+	musl := bom.Components[0]
+	if musl.Type != "operating-system" || musl.BOMRef != "pkg:apk/alpine/musl@1.2.4-r2" || musl.CPE == "" ||
+		len(musl.Licenses) != 1 || musl.Licenses[0].License == nil || musl.Licenses[0].License.ID != "MIT" {
+		t.Errorf("unexpected musl component: %+v", musl)
+	}
+	req := bom.Components[1]
+	if req.BOMRef != "requests@2.31.0" || len(req.Licenses) != 2 ||
+		req.Licenses[0].Expression != "Apache-2.0 OR MIT" || req.Licenses[1].License == nil || req.Licenses[1].License.Name != "Custom License" {
+		t.Errorf("unexpected requests component: %+v", req)
+	}
+	if len(bom.Dependencies) != 1 || len(bom.Dependencies[0].DependsOn) != 3 {
+		t.Errorf("unexpected dependencies: %+v", bom.Dependencies)
+	}
+
+	if len(bom.Vulnerabilities) != 3 {
+		t.Fatalf("expected 3 vulnerabilities, got %d", len(bom.Vulnerabilities))
+	}
+	crit := bom.Vulnerabilities[0]
+	if crit.Ratings[0].Severity != "critical" || crit.Ratings[0].Method != "CVSSv31" || crit.Ratings[0].Score != 9.8 ||
+		crit.Recommendation != "Upgrade musl to 1.2.4-r3" || crit.Affects[0].Ref != musl.BOMRef ||
+		crit.Source.URL != "https://nvd.nist.gov/vuln/detail/CVE-2024-0001" {
+		t.Errorf("unexpected critical vulnerability: %+v", crit)
+	}
+	if got := bom.Vulnerabilities[1].Ratings[0].Severity; got != "info" {
+		t.Errorf("negligible severity = %q, want info", got)
+	}
+	suppressed := bom.Vulnerabilities[2]
+	if suppressed.Analysis == nil || suppressed.Analysis.Detail != "not reachable" || suppressed.Affects[0].Ref != "left-pad@1.3.0" {
+		t.Errorf("unexpected suppressed vulnerability: %+v", suppressed)
+	}
+	if a := suppressed.Analysis; a.State != "exploitable" || len(a.Response) != 1 || a.Response[0] != "will_not_fix" {
+		t.Errorf("accepted risk analysis = %+v, want exploitable/will_not_fix", a)
+	}
+
+	if _, err := CycloneDX(nil); err == nil {
+		t.Error("CycloneDX(nil): expected error")
+	}
+}
+
+func TestCdxSuppression(t *testing.T) {
+	tests := []struct {
+		name string
+		in   types.SuppressedVulnerability
+		want cdxAnalysis
+	}{
+		{
+			name: "vex not affected",
+			in:   types.SuppressedVulnerability{Justification: "not reachable", Status: "not_affected", VEXJustification: "vulnerable_code_not_in_execute_path"},
+			want: cdxAnalysis{State: "not_affected", Justification: "code_not_reachable", Detail: "not reachable"},
+		},
+		{
+			name: "unmapped justification",
+			in:   types.SuppressedVulnerability{Justification: "n/a", Status: "not_affected", VEXJustification: "custom"},
+			want: cdxAnalysis{State: "not_affected", Detail: "n/a"},
+		},
+		{
+			name: "false positive",
+			in:   types.SuppressedVulnerability{Justification: "wrong package", Status: "false_positive"},
+			want: cdxAnalysis{State: "false_positive", Detail: "wrong package"},
+		},
+		{
+			name: "fixed",
+			in:   types.SuppressedVulnerability{Justification: "patched", Status: "fixed"},
+			want: cdxAnalysis{State: "resolved", Detail: "patched"},
+		},
+		{
+			name: "accepted risk",
+			in:   types.SuppressedVulnerability{Justification: "behind the load balancer"},
+			want: cdxAnalysis{State: "exploitable", Response: []string{"will_not_fix"}, Detail: "behind the load balancer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cdxSuppression(tt.in); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("cdxSuppression() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCycloneDX_DuplicateFindings tests that a finding reported once per
+// location still gets unique bom-refs.
+func TestCycloneDX_DuplicateFindings(t *testing.T) {
+	stdlib := types.Vulnerability{ID: "CVE-2024-24790", Severity: "Critical", Package: "stdlib", Version: "go1.22.3"}
+	stats := &types.ImageStats{
+		ImageTag:        "app:1",
+		Packages:        []types.PackageSummary{{Name: "stdlib", Version: "go1.22.3"}},
+		Vulnerabilities: []types.Vulnerability{stdlib, stdlib},
+		Suppressed:      []types.SuppressedVulnerability{{Vulnerability: stdlib, Justification: "accepted"}},
+	}
+	data, err := CycloneDX(stats)
+	if err != nil {
+		t.Fatalf("CycloneDX() error = %v", err)
+	}
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	seen := map[string]bool{bom.Metadata.Component.BOMRef: true}
+	for _, c := range bom.Components {
+		seen[c.BOMRef] = true
+	}
+	for _, v := range bom.Vulnerabilities {
+		if seen[v.BOMRef] {
+			t.Errorf("duplicate bom-ref %q", v.BOMRef)
+		}
+		seen[v.BOMRef] = true
+	}
+}
+
+// TestCycloneDX_CleanScan tests that a scanned image without findings keeps
+// an empty vulnerabilities section, while an unscanned one has none.
+func TestCycloneDX_CleanScan(t *testing.T) {
+	for _, tc := range []struct {
+		status string
+		want   bool
+	}{
+		{types.RunnerOK, true},
+		{types.RunnerSkipped, false},
+	} {
+		data, err := CycloneDX(&types.ImageStats{
+			ImageTag: "app:1",
+			Runners:  []types.RunnerStatus{{Name: "grype", Status: tc.status}},
+		})
+		if err != nil {
+			t.Fatalf("CycloneDX() error: %v", err)
+		}
+		var bom map[string]json.RawMessage
+		if err := json.Unmarshal(data, &bom); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if _, ok := bom["vulnerabilities"]; ok != tc.want {
+			t.Errorf("grype %s: vulnerabilities section present = %v, want %v", tc.status, ok, tc.want)
+		}
+	}
+}
+
+func TestCdxLicenses(t *testing.T) {
+	got := cdxLicenses([]string{"mit", "GPL-2.0+", "Apache-2.0 OR MIT", "BSD", "Custom License"})
+	want := []cdxLicense{
+		{License: &cdxLicenseObject{ID: "MIT"}},
+		{Expression: "GPL-2.0+"},
+		{Expression: "Apache-2.0 OR MIT"},
+		{License: &cdxLicenseObject{Name: "BSD"}},
+		{License: &cdxLicenseObject{Name: "Custom License"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cdxLicenses() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUniqueRef(t *testing.T) {
+	used := map[string]bool{}
+	for _, want := range []string{"a@1", "a@1#2", "a@1#3"} {
+		if got := uniqueRef(used, "", "a@1"); got != want {
+			t.Errorf("uniqueRef() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCvssMethod(t *testing.T) {
+	tests := map[string]string{
+		"CVSS:4.0/AV:N":      "CVSSv4",
+		"CVSS:3.1/AV:N":      "CVSSv31",
+		"CVSS:3.0/AV:N":      "CVSSv3",
+		"AV:N/AC:L/Au:N":     "CVSSv2",
+		"":                   "",
+		"something-else/1.0": "other",
+	}
+	for vector, want := range tests {
+		if got := cvssMethod(vector); got != want {
+			t.Errorf("cvssMethod(%q) = %q, want %q", vector, got, want)
+		}
+	}
+}
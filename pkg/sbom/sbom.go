@@ -0,0 +1,87 @@
+// Package sbom encodes image analysis results as standard software bill of
+// materials documents (CycloneDX and SPDX), so documentation runs can also
+// produce compliance artifacts.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Tool is the tool name recorded as the creator of generated documents.
+const Tool = "dock-docs"
+
+// now returns the document creation time. It is swappable so tests produce
+// stable output.
+var now = time.Now
+
+// subject returns the display name of the analyzed image.
+func subject(stats *types.ImageStats) string {
+	if stats.ImageTag != "" {
+		return stats.ImageTag
+	}
+	if stats.RepoDigest != "" {
+		return stats.RepoDigest
+	}
+	return "image"
+}
+
+// digest returns the image digest ("sha256:..."), preferring the registry
+// digest over the local image ID, or "" when neither is known.
+func digest(stats *types.ImageStats) string {
+	if _, d, ok := strings.Cut(stats.RepoDigest, "@"); ok {
+		return d
+	}
+	return stats.ImageID
+}
+
+// imagePURL returns the OCI package URL of the image, or "" when the image
+// digest is unknown (the OCI purl type requires one).
+func imagePURL(stats *types.ImageStats) string {
+	d := digest(stats)
+	if d == "" {
+		return ""
+	}
+	repo := stats.ImageTag
+	if i := strings.LastIndex(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	if repo == "" {
+		repo, _, _ = strings.Cut(stats.RepoDigest, "@")
+	}
+	name := repo[strings.LastIndex(repo, "/")+1:]
+	if name == "" {
+		return ""
+	}
+	purl := "pkg:oci/" + url.PathEscape(strings.ToLower(name)) + "@" + url.PathEscape(d)
+	if repo != name {
+		purl += "?repository_url=" + url.QueryEscape(repo)
+	}
+	return purl
+}
+
+// uuid derives a version 4 formatted UUID from the image identity and the
+// document creation time, so each generated document gets a unique
+// identifier while tests remain deterministic.
+func uuid(stats *types.ImageStats, created time.Time) string {
+	sum := sha256.Sum256([]byte(subject(stats) + "\x00" + digest(stats) + "\x00" + created.Format(time.RFC3339Nano)))
+	b := sum[:16]
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+// packageKey identifies a package for matching vulnerabilities to it.
+func packageKey(name, version string) string {
+	return name + "@" + version
+}
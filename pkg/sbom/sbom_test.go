@@ -0,0 +1,72 @@
+package sbom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// fixedNow pins the document creation time for the duration of a test.
+func fixedNow(t *testing.T) time.Time {
+	t.Helper()
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orig := now
+	now = func() time.Time { return ts }
+	t.Cleanup(func() { now = orig })
+	return ts
+}
+
+// sampleStats returns analysis results covering packages, licenses,
+// vulnerabilities and suppressions.
+func sampleStats() *types.ImageStats {
+	return &types.ImageStats{
+		ImageTag:     "ghcr.io/acme/app:1.0",
+		RepoDigest:   "ghcr.io/acme/app@sha256:abc123",
+		Architecture: "amd64",
+		OSDistro:     "Alpine Linux 3.19",
+		Packages: []types.PackageSummary{
+			{Name: "musl", Version: "1.2.4-r2", Type: "apk", Tier: types.TierOS, Licenses: []string{"MIT"}, PURL: "pkg:apk/alpine/musl@1.2.4-r2", CPEs: []string{"cpe:2.3:a:musl-libc:musl:1.2.4:*:*:*:*:*:*:*"}},
+			{Name: "requests", Version: "2.31.0", Type: "python", Licenses: []string{"Apache-2.0 OR MIT", "Custom License"}},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+		},
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-2024-0001", Severity: "Critical", Package: "musl", Version: "1.2.4-r2", FixState: types.FixStateFixed, FixedInVersions: []string{"1.2.4-r3"}, CVSSScore: 9.8, CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Description: "overflow"},
+			{ID: "GHSA-xxxx-yyyy-zzzz", Severity: "Negligible", Package: "requests", Version: "2.31.0"},
+		},
+		Suppressed: []types.SuppressedVulnerability{
+			{Vulnerability: types.Vulnerability{ID: "CVE-2024-0002", Severity: "High", Package: "left-pad", Version: "1.3.0"}, Justification: "not reachable"},
+		},
+	}
+}
+
+func TestImagePURL(t *testing.T) {
+	tests := []struct {
+		stats types.ImageStats
+		want  string
+	}{
+		{types.ImageStats{ImageTag: "ghcr.io/acme/app:1.0", RepoDigest: "ghcr.io/acme/app@sha256:abc"}, "pkg:oci/app@sha256:abc?repository_url=ghcr.io%2Facme%2Fapp"},
+		{types.ImageStats{ImageTag: "nginx", ImageID: "sha256:def"}, "pkg:oci/nginx@sha256:def"},
+		{types.ImageStats{ImageTag: "localhost:5000/App:dev", ImageID: "sha256:def"}, "pkg:oci/app@sha256:def?repository_url=localhost%3A5000%2FApp"},
+		{types.ImageStats{ImageTag: "nginx:latest"}, ""},
+	}
+	for _, tt := range tests {
+		if got := imagePURL(&tt.stats); got != tt.want {
+			t.Errorf("imagePURL(%q) = %q, want %q", tt.stats.ImageTag, got, tt.want)
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	a := uuid(sampleStats(), ts)
+	if len(a) != 36 || a[14] != '4' {
+		t.Errorf("uuid() = %q, want a version 4 formatted UUID", a)
+	}
+	if a != uuid(sampleStats(), ts) {
+		t.Error("uuid() is not deterministic")
+	}
+	if a == uuid(sampleStats(), ts.Add(time.Second)) {
+		t.Error("uuid() should differ between generation times")
+	}
+}
@@ -0,0 +1,268 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/license"
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// SPDXVersion is the SPDX specification version of generated documents.
+const SPDXVersion = "SPDX-2.3"
+
+// noAssertion is the SPDX value for information that was not determined.
+const noAssertion = "NOASSERTION"
+
+// spdxIDChars matches characters not allowed in SPDX identifiers.
+var spdxIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxLicenseRef matches a license defined in the document itself.
+var spdxLicenseRef = regexp.MustCompile(`^LicenseRef-[A-Za-z0-9.-]+$`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	ExtractedLicenses []spdxExtracted    `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string         `json:"name"`
+	SPDXID                string         `json:"SPDXID"`
+	VersionInfo           string         `json:"versionInfo,omitempty"`
+	DownloadLocation      string         `json:"downloadLocation"`
+	FilesAnalyzed         bool           `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded      string         `json:"licenseConcluded"`
+	LicenseDeclared       string         `json:"licenseDeclared"`
+	CopyrightText         string         `json:"copyrightText"`
+	ExternalRefs          []spdxRef      `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string         `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type spdxExtracted struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+// SPDX encodes stats as an SPDX 2.3 JSON document. The image is the
+// described container package and contains one package per analyzed
+// package. SPDX 2.3 has no vulnerability model, so each finding is attached
+// to its package as a SECURITY advisory reference.
+func SPDX(stats *types.ImageStats) ([]byte, error) {
+	if stats == nil {
+		return nil, fmt.Errorf("no analysis results to encode")
+	}
+	created := now().UTC()
+	name := subject(stats)
+
+	doc := spdxDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + Tool + "/" + spdxIDChars.ReplaceAllString(name, "-") + "-" + uuid(stats, created),
+		CreationInfo: spdxCreationInfo{
+			Created:  created.Format(time.RFC3339),
+			Creators: []string{"Tool: " + Tool},
+		},
+	}
+
+	root := spdxPackage{
+		Name:                  name,
+		SPDXID:                "SPDXRef-Image",
+		VersionInfo:           digest(stats),
+		DownloadLocation:      noAssertion,
+		LicenseConcluded:      noAssertion,
+		LicenseDeclared:       noAssertion,
+		CopyrightText:         noAssertion,
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+	if alg, sum, ok := strings.Cut(digest(stats), ":"); ok && alg == "sha256" {
+		root.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: sum}}
+	}
+	if purl := imagePURL(stats); purl != "" {
+		root.ExternalRefs = []spdxRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: purl}}
+	}
+	doc.Packages = append(doc.Packages, root)
+	doc.Relationships = append(doc.Relationships, spdxRelationship{Element: doc.SPDXID, Type: "DESCRIBES", Related: root.SPDXID})
+
+	advisories := make(map[string][]spdxRef)
+	for _, v := range stats.Vulnerabilities {
+		// An advisory reference needs a locator URL.
+		if v.URL() == "" {
+			continue
+		}
+		key := packageKey(v.Package, v.Version)
+		advisories[key] = append(advisories[key], spdxRef{
+			Category: "SECURITY", Type: "advisory", Locator: v.URL(), Comment: v.ID + " (" + v.Severity + ")",
+		})
+	}
+
+	extracted := make(map[string]string)
+	for i, p := range stats.Packages {
+		pkg := spdxPackage{
+			Name:             p.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%s-%d", spdxIDChars.ReplaceAllString(p.Name, "-"), i+1),
+			VersionInfo:      p.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  spdxExpression(p.Licenses, extracted),
+			CopyrightText:    noAssertion,
+		}
+		if pkg.LicenseDeclared == "" {
+			pkg.LicenseDeclared = noAssertion
+		}
+		if p.PURL != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxRef{Category: "PACKAGE-MANAGER", Type: "purl", Locator: p.PURL})
+		}
+		for _, cpe := range p.CPEs {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxRef{Category: "SECURITY", Type: cpeRefType(cpe), Locator: cpe})
+		}
+		pkg.ExternalRefs = append(pkg.ExternalRefs, advisories[packageKey(p.Name, p.Version)]...)
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: root.SPDXID, Type: "CONTAINS", Related: pkg.SPDXID})
+	}
+
+	for id, text := range extracted {
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, spdxExtracted{LicenseID: id, ExtractedText: text, Name: text})
+	}
+	sort.Slice(doc.ExtractedLicenses, func(i, j int) bool {
+		return doc.ExtractedLicenses[i].LicenseID < doc.ExtractedLicenses[j].LicenseID
+	})
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SPDX document: %w", err)
+	}
+	return data, nil
+}
+
+// spdxExpression joins the licenses of a package into one SPDX expression.
+// License names that are not on the SPDX license list (e.g., "BSD" or
+// "GPL") are replaced with a LicenseRef, recorded in extracted so the
+// document can define it, as are LicenseRefs already used by a license.
+func spdxExpression(licenses []string, extracted map[string]string) string {
+	var terms []string
+	for _, l := range licenses {
+		l = strings.TrimSpace(l)
+		if l == "" || l == noAssertion || l == "NONE" {
+			continue
+		}
+		if isExpression(l) {
+			for _, tok := range expressionTokens(l) {
+				if spdxLicenseRef.MatchString(tok) {
+					defineRef(extracted, tok, tok)
+				}
+			}
+			if len(licenses) > 1 {
+				l = "(" + l + ")"
+			}
+		} else if id, ok := license.SPDXID(l); ok {
+			l = id
+		} else if spdxLicenseRef.MatchString(l) {
+			defineRef(extracted, l, l)
+		} else {
+			ref := "LicenseRef-" + strings.Trim(spdxIDChars.ReplaceAllString(l, "-"), "-")
+			defineRef(extracted, ref, l)
+			l = ref
+		}
+		terms = append(terms, l)
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// defineRef records the license text of ref, keeping the first text when
+// several license names map to the same LicenseRef.
+func defineRef(extracted map[string]string, ref, text string) {
+	if _, ok := extracted[ref]; !ok {
+		extracted[ref] = text
+	}
+}
+
+// expressionTokens splits a license expression into operators, parentheses
+// and identifiers.
+func expressionTokens(expr string) []string {
+	return strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr))
+}
+
+// validExpression reports whether expr is a well-formed SPDX license
+// expression: licenses on the SPDX license list or LicenseRefs, joined by
+// the (case-sensitive) AND and OR operators and optionally grouped with
+// parentheses, where a license may be followed by WITH and an exception on
+// the SPDX exception list.
+func validExpression(expr string) bool {
+	operand, exception, depth := true, false, 0
+	for _, tok := range expressionTokens(expr) {
+		switch {
+		case exception:
+			if _, ok := license.SPDXException(tok); !ok {
+				return false
+			}
+			exception, operand = false, false
+		case tok == "(" && operand:
+			depth++
+		case tok == ")" && !operand && depth > 0:
+			depth--
+		case (tok == "AND" || tok == "OR") && !operand:
+			operand = true
+		case tok == "WITH" && !operand:
+			exception = true
+		case operand && knownLicense(tok):
+			operand = false
+		default:
+			return false
+		}
+	}
+	return !operand && !exception && depth == 0
+}
+
+// knownLicense reports whether id may be used as a license in an SPDX
+// expression: it is on the SPDX license list or is a LicenseRef.
+func knownLicense(id string) bool {
+	_, ok := license.SPDXID(id)
+	return ok || spdxLicenseRef.MatchString(id)
+}
+
+// cpeRefType returns the SPDX external reference type for a CPE string.
+func cpeRefType(cpe string) string {
+	if strings.HasPrefix(cpe, "cpe:2.3:") {
+		return "cpe23Type"
+	}
+	return "cpe22Type"
+}
@@ -0,0 +1,116 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSPDX(t *testing.T) {
+	fixedNow(t)
+	data, err := SPDX(sampleStats())
+	if err != nil {
+		t.Fatalf("SPDX() error = %v", err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || doc.CreationInfo.Created != "2024-05-01T12:00:00Z" {
+		t.Errorf("unexpected header: %+v", doc)
+	}
+	if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/dock-docs/ghcr.io-acme-app-1.0-") {
+		t.Errorf("unexpected namespace %q", doc.DocumentNamespace)
+	}
+
+	if len(doc.Packages) != 4 {
+		t.Fatalf("expected 4 packages (image + 3), got %d", len(doc.Packages))
+	}
+	root := doc.Packages[0]
+	if root.PrimaryPackagePurpose != "CONTAINER" || root.Checksums[0].ChecksumValue != "abc123" || root.ExternalRefs[0].Type != "purl" {
+		t.Errorf("unexpected image package: %+v", root)
+	}
+
+	musl := doc.Packages[1]
+	if musl.SPDXID != "SPDXRef-Package-musl-1" || musl.LicenseDeclared != "MIT" {
+		t.Errorf("unexpected musl package: %+v", musl)
+	}
+	var types []string
+	for _, ref := range musl.ExternalRefs {
+		types = append(types, ref.Type)
+	}
+	if strings.Join(types, ",") != "purl,cpe23Type,advisory" {
+		t.Errorf("musl external refs = %v", types)
+	}
+
+	if got := doc.Packages[2].LicenseDeclared; got != "(Apache-2.0 OR MIT) AND LicenseRef-Custom-License" {
+		t.Errorf("requests licenseDeclared = %q", got)
+	}
+	if got := doc.Packages[3].LicenseDeclared; got != "NOASSERTION" {
+		t.Errorf("left-pad licenseDeclared = %q", got)
+	}
+	if len(doc.ExtractedLicenses) != 1 || doc.ExtractedLicenses[0].LicenseID != "LicenseRef-Custom-License" {
+		t.Errorf("unexpected extracted licenses: %+v", doc.ExtractedLicenses)
+	}
+
+	if len(doc.Relationships) != 4 || doc.Relationships[0].Type != "DESCRIBES" || doc.Relationships[3].Type != "CONTAINS" {
+		t.Errorf("unexpected relationships: %+v", doc.Relationships)
+	}
+
+	if _, err := SPDX(nil); err == nil {
+		t.Error("SPDX(nil): expected error")
+	}
+}
+
+func TestSpdxExpression(t *testing.T) {
+	tests := []struct {
+		licenses []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{"NOASSERTION"}, ""},
+		{[]string{"GPL-2.0-only WITH Classpath-exception-2.0"}, "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{[]string{"MIT", "BSD-3-Clause"}, "MIT AND BSD-3-Clause"},
+		{[]string{"MIT or (GPL v2)"}, "LicenseRef-MIT-or-GPL-v2"},
+		{[]string{"apache-2.0"}, "Apache-2.0"},
+		// Names that match the identifier syntax but are not on the SPDX
+		// license list are not identifiers.
+		{[]string{"BSD", "GPL"}, "LicenseRef-BSD AND LicenseRef-GPL"},
+		{[]string{"GPL OR MIT"}, "LicenseRef-GPL-OR-MIT"},
+		{[]string{"LicenseRef-Acme OR MIT"}, "LicenseRef-Acme OR MIT"},
+	}
+	for _, tt := range tests {
+		if got := spdxExpression(tt.licenses, map[string]string{}); got != tt.want {
+			t.Errorf("spdxExpression(%q) = %q, want %q", tt.licenses, got, tt.want)
+		}
+	}
+
+	// Every LicenseRef used is defined.
+	extracted := map[string]string{}
+	spdxExpression([]string{"BSD", "LicenseRef-Acme OR MIT"}, extracted)
+	if extracted["LicenseRef-BSD"] != "BSD" || extracted["LicenseRef-Acme"] != "LicenseRef-Acme" || len(extracted) != 2 {
+		t.Errorf("extracted licenses = %v, want LicenseRef-BSD and LicenseRef-Acme", extracted)
+	}
+}
+
+func TestValidExpression(t *testing.T) {
+	tests := map[string]bool{
+		"MIT":                          true,
+		"(MIT OR Apache-2.0) AND Zlib": true,
+		"MIT or Apache-2.0":            false,
+		"GPL OR Apache":                false,
+		"LicenseRef-Acme OR mit":       true,
+		"GPL-2.0-only WITH Classpath-exception-2.0": true,
+		"GPL-2.0-only WITH MIT":                     false,
+		"MIT WITH":                                  false,
+		"GPL v2":                                    false,
+		"(MIT":                                      false,
+		"MIT AND":                                   false,
+	}
+	for expr, want := range tests {
+		if got := validExpression(expr); got != want {
+			t.Errorf("validExpression(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
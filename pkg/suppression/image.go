@@ -0,0 +1,111 @@
+package suppression
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// imageRef is an image reference taken from a VEX product: a pkg:oci or
+// pkg:docker package URL, a plain reference ("ghcr.io/acme/app:1.0") or a
+// bare digest.
+type imageRef struct {
+	repo   string // repository without registry defaults, e.g. "acme/app"; empty for a bare digest
+	tag    string
+	digest string // "sha256:..."
+}
+
+// parseImageRef parses an image product identifier.
+func parseImageRef(id string) imageRef {
+	var ref imageRef
+	if strings.HasPrefix(id, "sha256:") {
+		ref.digest = id
+		return ref
+	}
+
+	if rest, ok := strings.CutPrefix(id, "pkg:"); ok {
+		var qualifiers url.Values
+		if i := strings.IndexAny(rest, "?#"); i >= 0 {
+			if rest[i] == '?' {
+				qualifiers, _ = url.ParseQuery(strings.SplitN(rest[i+1:], "#", 2)[0])
+			}
+			rest = rest[:i]
+		}
+		if i := strings.LastIndex(rest, "@"); i >= 0 {
+			version, err := url.PathUnescape(rest[i+1:])
+			if err != nil {
+				version = rest[i+1:]
+			}
+			if strings.HasPrefix(version, "sha256:") {
+				ref.digest = version
+			} else {
+				ref.tag = version
+			}
+			rest = rest[:i]
+		}
+		// Drop the purl type ("oci/" or "docker/").
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[i+1:]
+		}
+		repo, err := url.PathUnescape(rest)
+		if err != nil {
+			repo = rest
+		}
+		if u := qualifiers.Get("repository_url"); u != "" {
+			// pkg:oci carries the full repository; pkg:docker only the registry.
+			if strings.HasSuffix(strings.ToLower(u), "/"+strings.ToLower(repo)) || strings.EqualFold(u, repo) {
+				repo = u
+			} else {
+				repo = u + "/" + repo
+			}
+		}
+		ref.repo = normalizeRepo(repo)
+		return ref
+	}
+
+	if i := strings.Index(id, "@"); i >= 0 {
+		ref.digest = id[i+1:]
+		id = id[:i]
+	}
+	if i := strings.LastIndex(id, ":"); i > strings.LastIndex(id, "/") {
+		ref.tag = id[i+1:]
+		id = id[:i]
+	}
+	ref.repo = normalizeRepo(id)
+	return ref
+}
+
+// matches reports whether the reference identifies the analyzed image. A
+// digest is compared with the image's repo digest and ID; otherwise the
+// repository (and the tag, when both sides have one) must agree.
+func (ref imageRef) matches(stats *types.ImageStats) bool {
+	if ref.digest != "" {
+		_, repoDigest, _ := strings.Cut(stats.RepoDigest, "@")
+		return ref.digest == repoDigest || ref.digest == stats.ImageID
+	}
+
+	image := stats.ImageTag
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	var tag string
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	repo := normalizeRepo(image)
+	if ref.repo != repo && !strings.HasSuffix(repo, "/"+ref.repo) {
+		return false
+	}
+	return ref.tag == "" || tag == "" || ref.tag == tag
+}
+
+// normalizeRepo lower-cases a repository and drops the Docker Hub defaults,
+// so "docker.io/library/nginx" and "nginx" compare equal.
+func normalizeRepo(repo string) string {
+	repo = strings.ToLower(repo)
+	for _, prefix := range []string{"docker.io/", "index.docker.io/", "library/"} {
+		repo = strings.TrimPrefix(repo, prefix)
+	}
+	return repo
+}
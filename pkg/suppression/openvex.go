@@ -0,0 +1,192 @@
+package suppression
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// OpenVEX statuses that mean a finding does not need to be acted upon.
+var suppressingStatuses = map[string]bool{
+	StatusNotAffected: true,
+	StatusFixed:       true,
+}
+
+// openVEXDocument is the subset of an OpenVEX document that dock-docs reads.
+// Both the v0.0.x (string) and v0.2.x (object) forms of vulnerabilities,
+// products and subcomponents are accepted.
+type openVEXDocument struct {
+	Context    string `json:"@context"`
+	Statements []struct {
+		Vulnerability   json.RawMessage   `json:"vulnerability"`
+		Products        []json.RawMessage `json:"products"`
+		Subcomponents   []json.RawMessage `json:"subcomponents"`
+		Status          string            `json:"status"`
+		Justification   string            `json:"justification"`
+		ImpactStatement string            `json:"impact_statement"`
+	} `json:"statements"`
+}
+
+// LoadOpenVEX reads an OpenVEX document and converts every "not_affected" or
+// "fixed" statement into suppression rules. Statements scoped to package URLs
+// (as products or subcomponents) become package- and version-specific rules.
+// Image products (pkg:oci/pkg:docker URLs, references or digests) restrict
+// the rules to that image; a statement without products applies everywhere.
+func LoadOpenVEX(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VEX document %s: %w", path, err)
+	}
+	return parseOpenVEX(data, path)
+}
+
+func parseOpenVEX(data []byte, source string) ([]Rule, error) {
+	var doc openVEXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse VEX document %s: %w", source, err)
+	}
+	if !strings.Contains(doc.Context, "openvex") {
+		return nil, fmt.Errorf("%s is not an OpenVEX document (@context %q)", source, doc.Context)
+	}
+
+	var rules []Rule
+	for i, st := range doc.Statements {
+		if !suppressingStatuses[st.Status] {
+			continue
+		}
+		id := vexVulnerabilityName(st.Vulnerability)
+		if id == "" {
+			return nil, fmt.Errorf("%s: statement %d has no vulnerability name", source, i)
+		}
+
+		justification := st.ImpactStatement
+		if justification == "" && st.Justification != "" {
+			justification = strings.ReplaceAll(st.Justification, "_", " ")
+		}
+		if justification == "" {
+			justification = strings.ReplaceAll(st.Status, "_", " ")
+		}
+
+		base := Rule{
+			ID:               id,
+			Justification:    justification,
+			Status:           st.Status,
+			VEXJustification: st.Justification,
+			Source:           source,
+		}
+
+		// Products that are packages scope the statement to that package in
+		// any image. Other products are images: the statement applies only to
+		// them, restricted to their subcomponents (or the statement's) if any.
+		stmtPURLs := vexIDs(st.Subcomponents)
+		if len(st.Products) == 0 {
+			rules = append(rules, scopedRules(base, "", stmtPURLs)...)
+		}
+		for _, raw := range st.Products {
+			productID, subs := vexProduct(raw)
+			if isPackagePURL(productID) {
+				rules = append(rules, scopedRules(base, "", []string{productID})...)
+				continue
+			}
+			rules = append(rules, scopedRules(base, productID, append(subs, stmtPURLs...))...)
+		}
+	}
+	return rules, nil
+}
+
+// scopedRules copies base once per package URL (or once if there are none),
+// restricted to the given image.
+func scopedRules(base Rule, image string, purls []string) []Rule {
+	base.Image = image
+	if len(purls) == 0 {
+		return []Rule{base}
+	}
+	rules := make([]Rule, 0, len(purls))
+	for _, p := range purls {
+		r := base
+		r.Package, r.Versions = parsePURL(p)
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// vexVulnerabilityName extracts the vulnerability ID from either
+// "vulnerability": "CVE-..." or "vulnerability": {"name": "CVE-..."}.
+func vexVulnerabilityName(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var obj struct {
+		Name string `json:"name"`
+		ID   string `json:"@id"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		if obj.Name != "" {
+			return obj.Name
+		}
+		return obj.ID
+	}
+	return ""
+}
+
+// vexProduct returns a product's identifier and the identifiers of its
+// subcomponents, accepting both string and object forms.
+func vexProduct(raw json.RawMessage) (string, []string) {
+	var id string
+	if json.Unmarshal(raw, &id) == nil {
+		return id, nil
+	}
+	var obj struct {
+		ID            string            `json:"@id"`
+		Subcomponents []json.RawMessage `json:"subcomponents"`
+	}
+	if json.Unmarshal(raw, &obj) != nil {
+		return "", nil
+	}
+	return obj.ID, vexIDs(obj.Subcomponents)
+}
+
+func vexIDs(raws []json.RawMessage) []string {
+	ids := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		if id, _ := vexProduct(raw); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// isPackagePURL reports whether id is a package URL for an installed package
+// (as opposed to an image reference such as pkg:oci/... or pkg:docker/...).
+func isPackagePURL(id string) bool {
+	if !strings.HasPrefix(id, "pkg:") {
+		return false
+	}
+	return !strings.HasPrefix(id, "pkg:oci/") && !strings.HasPrefix(id, "pkg:docker/")
+}
+
+// parsePURL extracts the package name and an exact-version constraint from a
+// package URL such as "pkg:apk/alpine/openssl@3.0.2-r0?arch=x86_64".
+func parsePURL(purl string) (name, versions string) {
+	p := strings.TrimPrefix(purl, "pkg:")
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	if i := strings.LastIndex(p, "@"); i >= 0 {
+		if v, err := url.PathUnescape(p[i+1:]); err == nil && v != "" {
+			versions = "=" + v
+		}
+		p = p[:i]
+	}
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		p = p[i+1:]
+	}
+	name, err := url.PathUnescape(p)
+	if err != nil {
+		name = p
+	}
+	return name, versions
+}
@@ -0,0 +1,162 @@
+// Package suppression filters accepted vulnerability risks out of analysis
+// results. Rules come from the dock-docs config file or from OpenVEX
+// documents and match findings by vulnerability ID, package and version range.
+package suppression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+// Triage statuses a rule can record for the findings it suppresses.
+const (
+	StatusNotAffected   = "not_affected"
+	StatusFalsePositive = "false_positive"
+	StatusFixed         = "fixed"
+)
+
+// Rule accepts the risk of a single vulnerability, optionally restricted to a
+// package and a range of installed versions.
+type Rule struct {
+	// ID is the vulnerability identifier (e.g., "CVE-2023-1234"). Required.
+	ID string
+	// Package restricts the rule to a package name. Empty matches any package.
+	Package string
+	// Versions restricts the rule to installed versions matching a constraint
+	// such as ">=1.0, <1.2.3". Empty matches any version.
+	Versions string
+	// Image restricts the rule to one image, given as a pkg:oci or pkg:docker
+	// package URL, an image reference or a digest. Empty matches any image.
+	Image string
+	// Justification explains why the risk is accepted.
+	Justification string
+	// Status is how the finding was triaged: "not_affected", "false_positive"
+	// or "fixed". Empty accepts the risk without claiming the image is unaffected.
+	Status string
+	// VEXJustification is the OpenVEX justification for a not_affected status.
+	VEXJustification string
+	// Expires is the last day the rule applies; it stops applying once that
+	// day is over. Zero never expires.
+	Expires time.Time
+	// Source describes where the rule came from (e.g., "dock-docs.yaml" or a VEX file path).
+	Source string
+}
+
+// Expired reports whether the rule's expiry date has passed at the given time.
+// The rule still applies for the whole of its expiry day.
+func (r Rule) Expired(now time.Time) bool {
+	return !r.Expires.IsZero() && !now.Before(r.Expires.AddDate(0, 0, 1))
+}
+
+// AppliesTo reports whether the rule covers the given image.
+func (r Rule) AppliesTo(stats *types.ImageStats) bool {
+	return r.Image == "" || parseImageRef(r.Image).matches(stats)
+}
+
+// Matches reports whether the rule applies to the given vulnerability.
+func (r Rule) Matches(v types.Vulnerability) bool {
+	if !strings.EqualFold(r.ID, v.ID) {
+		return false
+	}
+	if r.Package != "" && r.Package != v.Package {
+		return false
+	}
+	if r.Versions != "" {
+		ok, err := MatchVersion(v.Version, r.Versions)
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks a rule for structural errors.
+func (r Rule) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("vulnerability id is required")
+	}
+	if r.Justification == "" {
+		return fmt.Errorf("%s: justification is required", r.ID)
+	}
+	switch r.Status {
+	case "", StatusNotAffected, StatusFalsePositive, StatusFixed:
+	default:
+		return fmt.Errorf("%s: unknown status %q (want not_affected, false_positive or fixed)", r.ID, r.Status)
+	}
+	if r.Versions != "" {
+		if _, err := parseConstraint(r.Versions); err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// Partition splits rules into those still in effect and those whose expiry
+// date has passed. Expired rules are not applied so that the findings they
+// covered resurface, and callers should warn about them.
+func Partition(rules []Rule, now time.Time) (active, expired []Rule) {
+	for _, r := range rules {
+		if r.Expired(now) {
+			expired = append(expired, r)
+		} else {
+			active = append(active, r)
+		}
+	}
+	return active, expired
+}
+
+// Apply moves every vulnerability matched by a rule from stats.Vulnerabilities
+// to stats.Suppressed and removes it from stats.VulnSummary, so suppressed
+// findings no longer count towards summaries and badges. Rules scoped to a
+// different image are ignored. It returns the number of suppressed findings.
+func Apply(stats *types.ImageStats, rules []Rule) int {
+	if stats == nil {
+		return 0
+	}
+	var scoped []Rule
+	for _, r := range rules {
+		if r.AppliesTo(stats) {
+			scoped = append(scoped, r)
+		}
+	}
+	if len(scoped) == 0 {
+		return 0
+	}
+	rules = scoped
+
+	kept := make([]types.Vulnerability, 0, len(stats.Vulnerabilities))
+	count := 0
+	for _, v := range stats.Vulnerabilities {
+		rule, ok := firstMatch(rules, v)
+		if !ok {
+			kept = append(kept, v)
+			continue
+		}
+		stats.Suppressed = append(stats.Suppressed, types.SuppressedVulnerability{
+			Vulnerability:    v,
+			Justification:    rule.Justification,
+			Status:           rule.Status,
+			VEXJustification: rule.VEXJustification,
+			Expires:          rule.Expires,
+			Source:           rule.Source,
+		})
+		if stats.VulnSummary[v.Severity] > 0 {
+			stats.VulnSummary[v.Severity]--
+		}
+		count++
+	}
+	stats.Vulnerabilities = kept
+	return count
+}
+
+func firstMatch(rules []Rule, v types.Vulnerability) (Rule, bool) {
+	for _, r := range rules {
+		if r.Matches(v) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,342 @@
+package suppression
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/northcutted/dock-docs/pkg/types"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"1.10", "1.9", 1},
+		{"3.0.2-r0", "3.0.2-r1", -1},
+		{"1.2", "1.2.1", -1},
+		{"1.0.0", "1.0.rc1", 1},
+		{"1:2.3", "1:2.3", 0},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc1", 1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.1", "1.0.0-rc.2", -1},
+		{"1.0.0-rc1", "0.9.9", 1},
+		{"1.2~beta", "1.2", -1},
+		{"1.2~beta1", "1.2~beta2", -1},
+		{"1.2~rc1-1", "1.2-1", -1},
+		{"3.0.2-r0", "3.0.2", 1},
+		{"2.36-9+deb12u4", "2.36", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+		wantErr             bool
+	}{
+		{"1.2.3", "1.2.3", true, false},
+		{"1.2.3", "=1.2.4", false, false},
+		{"1.2.3", ">=1.0, <1.2.4", true, false},
+		{"1.2.4", ">=1.0, <1.2.4", false, false},
+		{"2.0", "!=1.0", true, false},
+		{"0.9", ">1.0", false, false},
+		{"1.0", "<=1.0", true, false},
+		{"anything", "*", true, false},
+		{"1.0.0-rc1", "<1.0.0", true, false},
+		{"1:1.2~beta-1", "<1:1.2-1", true, false},
+		{"1.0", ">=", false, true},
+		{"1.0", " , ", false, true},
+	}
+	for _, tt := range tests {
+		got, err := MatchVersion(tt.version, tt.constraint)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MatchVersion(%q, %q) error = %v, wantErr %v", tt.version, tt.constraint, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MatchVersion(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	vuln := types.Vulnerability{ID: "CVE-2024-0001", Package: "openssl", Version: "3.0.1"}
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"id only", Rule{ID: "CVE-2024-0001"}, true},
+		{"id case insensitive", Rule{ID: "cve-2024-0001"}, true},
+		{"different id", Rule{ID: "CVE-2024-9999"}, false},
+		{"matching package", Rule{ID: "CVE-2024-0001", Package: "openssl"}, true},
+		{"different package", Rule{ID: "CVE-2024-0001", Package: "zlib"}, false},
+		{"version in range", Rule{ID: "CVE-2024-0001", Versions: "<3.0.2"}, true},
+		{"version out of range", Rule{ID: "CVE-2024-0001", Versions: ">=3.0.2"}, false},
+		{"invalid constraint", Rule{ID: "CVE-2024-0001", Versions: ">="}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(vuln); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"valid", Rule{ID: "CVE-1", Justification: "ok", Versions: "<2"}, false},
+		{"missing id", Rule{Justification: "ok"}, true},
+		{"missing justification", Rule{ID: "CVE-1"}, true},
+		{"bad constraint", Rule{ID: "CVE-1", Justification: "ok", Versions: "<"}, true},
+		{"false positive", Rule{ID: "CVE-1", Justification: "ok", Status: StatusFalsePositive}, false},
+		{"unknown status", Rule{ID: "CVE-1", Justification: "ok", Status: "affected"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPartition(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rules := []Rule{
+		{ID: "never"},
+		{ID: "future", Expires: now.AddDate(0, 1, 0)},
+		{ID: "past", Expires: now.AddDate(0, -1, 0)},
+	}
+	active, expired := Partition(rules, now)
+	if len(active) != 2 || active[0].ID != "never" || active[1].ID != "future" {
+		t.Errorf("active = %+v, want [never future]", active)
+	}
+	if len(expired) != 1 || expired[0].ID != "past" {
+		t.Errorf("expired = %+v, want [past]", expired)
+	}
+}
+
+func TestRule_Expired(t *testing.T) {
+	r := Rule{ID: "CVE-1", Expires: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2024, 6, 29, 23, 59, 59, 0, time.UTC), false},
+		{time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC), false},
+		{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := r.Expired(tt.now); got != tt.want {
+			t.Errorf("Expired(%s) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+	if (Rule{ID: "CVE-1"}).Expired(time.Now()) {
+		t.Error("rule without expiry reported as expired")
+	}
+}
+
+func TestApply(t *testing.T) {
+	stats := &types.ImageStats{
+		Vulnerabilities: []types.Vulnerability{
+			{ID: "CVE-1", Severity: "Critical", Package: "openssl", Version: "3.0.1"},
+			{ID: "CVE-2", Severity: "High", Package: "zlib", Version: "1.3"},
+			{ID: "CVE-3", Severity: "High", Package: "curl", Version: "8.0"},
+		},
+		VulnSummary: map[string]int{"Critical": 1, "High": 2},
+	}
+	rules := []Rule{
+		{ID: "CVE-1", Package: "openssl", Justification: "not reachable", Source: "dock-docs.yaml"},
+		{ID: "CVE-3", Justification: "disputed"},
+	}
+
+	if n := Apply(stats, rules); n != 2 {
+		t.Fatalf("Apply() = %d, want 2", n)
+	}
+	if len(stats.Vulnerabilities) != 1 || stats.Vulnerabilities[0].ID != "CVE-2" {
+		t.Errorf("Vulnerabilities = %+v, want only CVE-2", stats.Vulnerabilities)
+	}
+	if stats.VulnSummary["Critical"] != 0 || stats.VulnSummary["High"] != 1 {
+		t.Errorf("VulnSummary = %v, want Critical=0 High=1", stats.VulnSummary)
+	}
+	if len(stats.Suppressed) != 2 {
+		t.Fatalf("Suppressed = %+v, want 2 entries", stats.Suppressed)
+	}
+	if s := stats.Suppressed[0]; s.ID != "CVE-1" || s.Justification != "not reachable" || s.Source != "dock-docs.yaml" {
+		t.Errorf("Suppressed[0] = %+v", s)
+	}
+
+	if n := Apply(nil, rules); n != 0 {
+		t.Errorf("Apply(nil) = %d, want 0", n)
+	}
+}
+
+func TestLoadOpenVEX(t *testing.T) {
+	doc := `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex/1",
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2024-0001"},
+      "products": [
+        {
+          "@id": "pkg:oci/app@sha256:abc",
+          "subcomponents": [{"@id": "pkg:apk/alpine/openssl@3.0.1-r0?arch=x86_64"}]
+        }
+      ],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path"
+    },
+    {
+      "vulnerability": "CVE-2024-0002",
+      "products": ["pkg:oci/app"],
+      "status": "fixed",
+      "impact_statement": "Patched in our fork"
+    },
+    {
+      "vulnerability": {"name": "CVE-2024-0003"},
+      "products": ["pkg:npm/%40scope/left-pad@1.0.0"],
+      "status": "not_affected",
+      "justification": "component_not_present"
+    },
+    {
+      "vulnerability": {"name": "CVE-2024-0004"},
+      "products": ["pkg:oci/app"],
+      "status": "affected"
+    }
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "app.openvex.json")
+	if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadOpenVEX(path)
+	if err != nil {
+		t.Fatalf("LoadOpenVEX() error = %v", err)
+	}
+	want := []Rule{
+		{ID: "CVE-2024-0001", Package: "openssl", Versions: "=3.0.1-r0", Image: "pkg:oci/app@sha256:abc", Justification: "vulnerable code not in execute path", Status: "not_affected", VEXJustification: "vulnerable_code_not_in_execute_path", Source: path},
+		{ID: "CVE-2024-0002", Image: "pkg:oci/app", Justification: "Patched in our fork", Status: "fixed", Source: path},
+		{ID: "CVE-2024-0003", Package: "left-pad", Versions: "=1.0.0", Justification: "component not present", Status: "not_affected", VEXJustification: "component_not_present", Source: path},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d: %+v", len(rules), len(want), rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+}
+
+func TestApply_ImageScope(t *testing.T) {
+	doc := `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2024-0001"},
+      "products": [{"@id": "pkg:oci/app-a@sha256%3Aaaa?repository_url=ghcr.io/acme/app-a"}],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_present"
+    }
+  ]
+}`
+	rules, err := parseOpenVEX([]byte(doc), "vex.json")
+	if err != nil {
+		t.Fatalf("parseOpenVEX() error = %v", err)
+	}
+
+	newStats := func(tag, digest string) *types.ImageStats {
+		return &types.ImageStats{
+			ImageTag:        tag,
+			RepoDigest:      digest,
+			Vulnerabilities: []types.Vulnerability{{ID: "CVE-2024-0001", Severity: "High", Package: "openssl"}},
+			VulnSummary:     map[string]int{"High": 1},
+		}
+	}
+	appA := newStats("ghcr.io/acme/app-a:1.0", "ghcr.io/acme/app-a@sha256:aaa")
+	appB := newStats("ghcr.io/acme/app-b:1.0", "ghcr.io/acme/app-b@sha256:bbb")
+
+	if n := Apply(appA, rules); n != 1 {
+		t.Errorf("Apply(app-a) = %d, want 1", n)
+	}
+	if n := Apply(appB, rules); n != 0 || len(appB.Vulnerabilities) != 1 {
+		t.Errorf("Apply(app-b) = %d, want 0 (vulns %+v)", n, appB.Vulnerabilities)
+	}
+}
+
+func TestImageRef_Matches(t *testing.T) {
+	stats := &types.ImageStats{
+		ImageTag:   "docker.io/library/nginx:1.25",
+		RepoDigest: "nginx@sha256:abc",
+		ImageID:    "sha256:def",
+	}
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"pkg:oci/nginx@sha256%3Aabc", true},
+		{"pkg:oci/nginx@sha256:def", true},
+		{"pkg:oci/nginx@sha256:000", false},
+		{"pkg:docker/library/nginx@1.25", true},
+		{"pkg:docker/library/nginx@1.24", false},
+		{"pkg:oci/nginx?repository_url=docker.io/library/nginx", true},
+		{"pkg:oci/redis", false},
+		{"nginx:1.25", true},
+		{"nginx", true},
+		{"ghcr.io/acme/nginx", false},
+		{"sha256:abc", true},
+	}
+	for _, tt := range tests {
+		if got := parseImageRef(tt.ref).matches(stats); got != tt.want {
+			t.Errorf("parseImageRef(%q).matches() = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestLoadOpenVEX_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid json", "{"},
+		{"not openvex", `{"@context": "https://cyclonedx.org", "statements": []}`},
+		{"missing vulnerability", `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"status": "fixed"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "vex.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadOpenVEX(path); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := LoadOpenVEX(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,186 @@
+package suppression
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// clause is a single comparison in a version constraint (e.g., ">=1.2").
+type clause struct {
+	op      string
+	version string
+}
+
+// MatchVersion reports whether version satisfies every clause of constraint.
+// A constraint is a comma-separated list of clauses using the operators
+// =, ==, !=, <, <=, > and >=; a bare version means an exact match and "*"
+// matches anything.
+func MatchVersion(version, constraint string) (bool, error) {
+	clauses, err := parseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range clauses {
+		cmp := CompareVersions(version, c.version)
+		var ok bool
+		switch c.op {
+		case "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "*":
+			ok = true
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseConstraint splits a constraint string into clauses.
+func parseConstraint(constraint string) ([]clause, error) {
+	var clauses []clause
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "*" {
+			clauses = append(clauses, clause{op: "*"})
+			continue
+		}
+		op := "="
+		for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		if part == "" {
+			return nil, fmt.Errorf("invalid version constraint %q: missing version after %q", constraint, op)
+		}
+		clauses = append(clauses, clause{op: op, version: part})
+	}
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("invalid version constraint %q: no clauses", constraint)
+	}
+	return clauses, nil
+}
+
+// CompareVersions compares two package version strings and returns -1, 0 or 1.
+// Versions are split into runs of digits and non-digits; digit runs compare
+// numerically and everything else lexically. This is deliberately ecosystem
+// agnostic so it works for semver, Debian/Alpine revisions ("1.2.3-r4") and
+// similar schemes. A version that is a prefix of another sorts first, except
+// that prereleases sort before the release: a semver "-<alpha>" suffix
+// ("1.0.0-rc1" < "1.0.0") and a Debian/RPM "~" ("1.2~beta" < "1.2"). Alpine
+// package revisions ("-r4") are not prereleases.
+func CompareVersions(a, b string) int {
+	ta, tb := tokenize(a), tokenize(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		if c := compareToken(ta[i], tb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(ta) < len(tb):
+		if tb[len(ta)] == preRelease {
+			return 1
+		}
+		return -1
+	case len(ta) > len(tb):
+		if ta[len(tb)] == preRelease {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// preRelease is the token that marks the start of a prerelease suffix. It
+// sorts before every other token and before the end of the version.
+const preRelease = "~"
+
+// alpineRevision matches an Alpine package revision such as "r4".
+var alpineRevision = regexp.MustCompile(`^r[0-9]+$`)
+
+func tokenize(v string) []string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	var tokens []string
+	var cur strings.Builder
+	curDigit := false
+	for i, r := range v {
+		isDigit := unicode.IsDigit(r)
+		if r == '.' || r == '-' || r == '_' || r == '+' || r == '~' || r == ':' {
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+			if r == '~' || (r == '-' && isPreRelease(v[i+1:])) {
+				tokens = append(tokens, preRelease)
+			}
+			continue
+		}
+		if cur.Len() > 0 && isDigit != curDigit {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+		curDigit = isDigit
+		cur.WriteRune(r)
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens
+}
+
+// isPreRelease reports whether the text after a "-" starts a semver
+// prerelease: it begins with a letter and is not an Alpine revision.
+func isPreRelease(rest string) bool {
+	if rest == "" || !unicode.IsLetter(rune(rest[0])) {
+		return false
+	}
+	return !alpineRevision.MatchString(rest)
+}
+
+func compareToken(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == preRelease:
+		return -1
+	case b == preRelease:
+		return 1
+	}
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	case errA == nil:
+		// Numeric segments sort after textual ones (1.0.0 > 1.0.rc).
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
@@ -74,6 +74,7 @@ var emojiMap = map[string]string{
 	"medium":   "\U0001F7E1",
 	"low":      "\U0001F535",
 	"clean":    "\U0001F7E2",
+	"warning":  "\u26A0\uFE0F",
 }
 
 var noMojiMap = map[string]string{
@@ -84,6 +85,7 @@ var noMojiMap = map[string]string{
 	"medium":   "[MED]",
 	"low":      "[LOW]",
 	"clean":    "[OK]",
+	"warning":  "[WARN]",
 }
 
 // GetEmoji returns an emoji or text alternative based on the noMoji flag.
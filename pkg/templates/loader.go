@@ -38,8 +38,20 @@ var builtinTemplates = []BuiltinInfo{
 	{Name: "compact", Format: "markdown", Description: "Single-line condensed format for quick reference"},
 	{Name: "html", Format: "html", Description: "Interactive HTML dashboard with styled tables"},
 	{Name: "json", Format: "json", Description: "Machine-readable JSON output for CI/CD integration"},
+	{Name: "cyclonedx", Format: FormatCycloneDX, Description: "CycloneDX 1.5 SBOM with vulnerabilities (JSON)"},
+	{Name: "spdx", Format: FormatSPDX, Description: "SPDX 2.3 SBOM document (JSON)"},
+	{Name: "sarif", Format: FormatSARIF, Description: "SARIF 2.1.0 log of vulnerabilities, drift and lint findings"},
 }
 
+// Formats of built-ins produced by document encoders (pkg/sbom, pkg/sarif)
+// instead of text templates. They have no template source and only render
+// images.
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+	FormatSARIF     = "sarif"
+)
+
 // templatePaths maps template name + type to embedded file path.
 var templatePaths = map[string]string{
 	// Image templates
@@ -102,9 +114,16 @@ func FormatForTemplate(name string) string {
 }
 
 // OutputExtension returns the file extension (with dot) for a given template format.
-// Returns ".md" for markdown, ".html" for html, ".json" for json.
+// Returns ".md" for markdown, ".html" for html, ".json" for json, and
+// ".cdx.json" or ".spdx.json" for the SBOM formats, and ".sarif" for SARIF.
 func OutputExtension(format string) string {
 	switch format {
+	case FormatSARIF:
+		return ".sarif"
+	case FormatCycloneDX:
+		return ".cdx.json"
+	case FormatSPDX:
+		return ".spdx.json"
 	case "html":
 		return ".html"
 	case "json":
@@ -117,7 +136,13 @@ func OutputExtension(format string) string {
 // IsDirectWriteFormat returns true if the format produces a standalone document
 // that should be written directly to a file instead of injected into an existing file.
 func IsDirectWriteFormat(format string) bool {
-	return format == "html" || format == "json"
+	return format == "html" || format == "json" || IsEncodedFormat(format)
+}
+
+// IsEncodedFormat returns true if the format is generated by a document
+// encoder rather than a text template.
+func IsEncodedFormat(format string) bool {
+	return format == FormatCycloneDX || format == FormatSPDX || format == FormatSARIF
 }
 
 // LoadBuiltin loads a built-in template by name and type.
@@ -209,6 +234,9 @@ func (l *Loader) LoadFile(path string) (*template.Template, error) {
 
 // ExportBuiltin returns the raw content of a built-in template.
 func ExportBuiltin(name string, tmplType TemplateType) (string, error) {
+	if IsEncodedFormat(FormatForTemplate(name)) {
+		return "", fmt.Errorf("built-in %s is generated by an encoder and has no template source", name)
+	}
 	cacheKey := name + ":" + string(tmplType)
 	path, ok := templatePaths[cacheKey]
 	if !ok {
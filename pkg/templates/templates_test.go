@@ -13,11 +13,11 @@ import (
 
 func TestListBuiltin(t *testing.T) {
 	builtins := ListBuiltin()
-	if len(builtins) != 6 {
-		t.Fatalf("expected 6 built-in templates, got %d", len(builtins))
+	if len(builtins) != 9 {
+		t.Fatalf("expected 9 built-in templates, got %d", len(builtins))
 	}
 
-	expectedNames := []string{"default", "minimal", "detailed", "compact", "html", "json"}
+	expectedNames := []string{"default", "minimal", "detailed", "compact", "html", "json", "cyclonedx", "spdx", "sarif"}
 	for i, b := range builtins {
 		if b.Name != expectedNames[i] {
 			t.Errorf("builtins[%d].Name = %q, want %q", i, b.Name, expectedNames[i])
@@ -42,6 +42,9 @@ func TestIsBuiltin(t *testing.T) {
 		{"compact", true},
 		{"html", true},
 		{"json", true},
+		{"cyclonedx", true},
+		{"spdx", true},
+		{"sarif", true},
 		{"nonexistent", false},
 		{"", false},
 		{"Default", false}, // case-sensitive
@@ -68,6 +71,9 @@ func TestFormatForTemplate(t *testing.T) {
 		{"compact", "markdown"},
 		{"html", "html"},
 		{"json", "json"},
+		{"cyclonedx", "cyclonedx"},
+		{"spdx", "spdx"},
+		{"sarif", "sarif"},
 		{"nonexistent", "markdown"}, // default fallback
 	}
 
@@ -253,6 +259,13 @@ func TestExportBuiltin_Unknown(t *testing.T) {
 	}
 }
 
+func TestExportBuiltin_EncodedFormat(t *testing.T) {
+	_, err := ExportBuiltin("cyclonedx", TemplateTypeImage)
+	if err == nil || !strings.Contains(err.Error(), "no template source") {
+		t.Fatalf("ExportBuiltin(cyclonedx) error = %v, want no template source error", err)
+	}
+}
+
 func TestValidate_ValidTemplate(t *testing.T) {
 	tmpDir := t.TempDir()
 	tmplPath := filepath.Join(tmpDir, "valid.tmpl")
@@ -546,6 +559,9 @@ func TestOutputExtension(t *testing.T) {
 		{"markdown", ".md"},
 		{"html", ".html"},
 		{"json", ".json"},
+		{"cyclonedx", ".cdx.json"},
+		{"spdx", ".spdx.json"},
+		{"sarif", ".sarif"},
 		{"", ".md"},
 		{"unknown", ".md"},
 	}
@@ -566,6 +582,9 @@ func TestIsDirectWriteFormat(t *testing.T) {
 	}{
 		{"html", true},
 		{"json", true},
+		{"cyclonedx", true},
+		{"spdx", true},
+		{"sarif", true},
 		{"markdown", false},
 		{"", false},
 		{"md", false},
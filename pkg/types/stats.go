@@ -3,42 +3,573 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 )
 
 // PackageSummary represents a simplified view of a package
 type PackageSummary struct {
-	Name    string
-	Version string
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Type     string   `json:"type,omitempty"`     // e.g., "apk", "deb", "npm", "go-module"
+	Licenses []string `json:"licenses,omitempty"` // SPDX expressions or license names
+	PURL     string   `json:"purl,omitempty"`     // Package URL (e.g., "pkg:apk/alpine/musl@1.2.4-r2")
+	CPEs     []string `json:"cpes,omitempty"`
+	// Tier classifies the package as a runtime, framework, library or OS package.
+	Tier string `json:"tier,omitempty"`
+	// Framework is the catalogue name of the runtime or framework the package
+	// belongs to (e.g., "Spring Boot" for spring-boot-starter-web).
+	Framework string `json:"framework,omitempty"`
 }
 
+// Package tiers assigned by the framework catalogue.
+const (
+	TierRuntime   = "runtime"
+	TierFramework = "framework"
+	TierLibrary   = "library"
+	TierOS        = "os"
+)
+
+// TechStackEntry is a runtime or framework detected in an image.
+type TechStackEntry struct {
+	Name    string `json:"name"`    // e.g., "OpenJDK", "Django"
+	Version string `json:"version"` // version of the first matching package
+	Tier    string `json:"tier"`    // TierRuntime or TierFramework
+}
+
+// LicenseCount is the number of packages that declare a license.
+type LicenseCount struct {
+	License  string `json:"license"`
+	Packages int    `json:"packages"`
+	Denied   bool   `json:"denied,omitempty"` // matches the configured license denylist
+}
+
+// Fix states reported by vulnerability scanners.
+const (
+	FixStateFixed    = "fixed"
+	FixStateNotFixed = "not-fixed"
+	FixStateWontFix  = "wont-fix"
+	FixStateUnknown  = "unknown"
+)
+
 // Vulnerability represents a security issue
 type Vulnerability struct {
-	ID       string // e.g., "CVE-2023-1234"
-	Severity string // "Critical", "High", "Medium", "Low"
-	Package  string // Package name
-	Version  string // Installed version
+	ID              string   `json:"id"`                        // e.g., "CVE-2023-1234"
+	Severity        string   `json:"severity"`                  // "Critical", "High", "Medium", "Low"
+	Package         string   `json:"package"`                   // Package name
+	Version         string   `json:"version"`                   // Installed version
+	FixState        string   `json:"fixState,omitempty"`        // "fixed", "not-fixed", "wont-fix", "unknown"
+	FixedInVersions []string `json:"fixedInVersions,omitempty"` // Versions that contain the fix
+	CVSSScore       float64  `json:"cvssScore,omitempty"`       // Base score of the preferred CVSS entry (0-10)
+	CVSSVector      string   `json:"cvssVector,omitempty"`      // e.g., "CVSS:3.1/AV:N/AC:L/..."
+	DataSource      string   `json:"dataSource,omitempty"`      // Advisory URL from the scanner's data source
+	Description     string   `json:"description,omitempty"`
+	PackageType     string   `json:"packageType,omitempty"` // e.g., "apk", "deb", "npm", "go-module"
+	Location        string   `json:"location,omitempty"`    // Path of the affected file inside the image
+}
+
+// Fixable reports whether a fixed version of the affected package is available.
+func (v Vulnerability) Fixable() bool {
+	return v.FixState == FixStateFixed && len(v.FixedInVersions) > 0
+}
+
+// URL returns a link to the advisory for this vulnerability. The scanner's
+// data source is preferred; otherwise CVE and GHSA identifiers are linked to
+// NVD and the GitHub Advisory Database respectively. Other identifiers (e.g.,
+// distribution advisories such as ALAS or RHSA) have no known link, so URL
+// returns "".
+func (v Vulnerability) URL() string {
+	switch {
+	case v.DataSource != "":
+		return v.DataSource
+	case strings.HasPrefix(v.ID, "GHSA-"):
+		return "https://github.com/advisories/" + v.ID
+	case strings.HasPrefix(v.ID, "CVE-"):
+		return "https://nvd.nist.gov/vuln/detail/" + v.ID
+	}
+	return ""
+}
+
+// SuppressedVulnerability is a finding whose risk has been accepted through a
+// suppression rule or VEX statement. It is reported separately from active
+// vulnerabilities and does not count towards summaries or badges.
+type SuppressedVulnerability struct {
+	Vulnerability
+	Justification    string    `json:"justification,omitempty"`
+	Status           string    `json:"status,omitempty"`           // "not_affected", "false_positive", "fixed"; empty for an accepted risk
+	VEXJustification string    `json:"vexJustification,omitempty"` // OpenVEX justification, e.g. "vulnerable_code_not_in_execute_path"
+	Expires          time.Time `json:"expires,omitzero"`           // zero means the suppression never expires
+	Source           string    `json:"source,omitempty"`           // config file or VEX document the rule came from
+}
+
+// ExpiresDate returns the expiry date as YYYY-MM-DD, or "never".
+func (s SuppressedVulnerability) ExpiresDate() string {
+	if s.Expires.IsZero() {
+		return "never"
+	}
+	return s.Expires.Format("2006-01-02")
+}
+
+// PolicyCheck is the outcome of a single security gate rule.
+type PolicyCheck struct {
+	Rule    string `json:"rule"`   // e.g., "maxCritical"
+	Passed  bool   `json:"passed"` // true when the rule is satisfied or could not be evaluated
+	Limit   string `json:"limit"`  // configured threshold, formatted for display
+	Actual  string `json:"actual"` // measured value, formatted for display
+	Message string `json:"message,omitempty"`
+}
+
+// PolicyReport is the structured pass/fail result of evaluating a security
+// policy against an image.
+type PolicyReport struct {
+	Passed bool          `json:"passed"`
+	Checks []PolicyCheck `json:"checks"`
+}
+
+// Status returns "PASS" or "FAIL".
+func (r *PolicyReport) Status() string {
+	if r.Passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// Violations returns the checks that failed.
+func (r *PolicyReport) Violations() []PolicyCheck {
+	var failed []PolicyCheck
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Healthcheck is the HEALTHCHECK configured in an image.
+type Healthcheck struct {
+	// Test is the check as stored in the image config, e.g.
+	// ["CMD-SHELL", "curl -f http://localhost/"] or ["NONE"].
+	Test        []string      `json:"test,omitempty"`
+	Interval    time.Duration `json:"interval,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	StartPeriod time.Duration `json:"startPeriod,omitempty"`
+	Retries     int           `json:"retries,omitempty"`
+}
+
+// Command returns the health check command as it would be written in a
+// Dockerfile, or "" when the check is disabled.
+func (h *Healthcheck) Command() string {
+	if h == nil || len(h.Test) == 0 {
+		return ""
+	}
+	switch h.Test[0] {
+	case "CMD", "CMD-SHELL":
+		return strings.Join(h.Test[1:], " ")
+	case "NONE":
+		return ""
+	default:
+		return strings.Join(h.Test, " ")
+	}
+}
+
+// ImageConfig is the runtime configuration recorded in an image, as reported
+// by the container runtime's inspect command.
+type ImageConfig struct {
+	User         string            `json:"user,omitempty"`
+	WorkingDir   string            `json:"workingDir,omitempty"`
+	Entrypoint   []string          `json:"entrypoint,omitempty"`
+	Cmd          []string          `json:"cmd,omitempty"`
+	Env          []string          `json:"env,omitempty"`          // "KEY=value" pairs, in image order
+	ExposedPorts []string          `json:"exposedPorts,omitempty"` // e.g., "8080/tcp", sorted
+	Volumes      []string          `json:"volumes,omitempty"`      // sorted
+	Labels       map[string]string `json:"labels,omitempty"`
+	Healthcheck  *Healthcheck      `json:"healthcheck,omitempty"`
+}
+
+// KeyValue is a single environment variable or label from the image config.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// EnvVars splits Env into key/value pairs, preserving image order.
+func (c *ImageConfig) EnvVars() []KeyValue {
+	if c == nil {
+		return nil
+	}
+	vars := make([]KeyValue, 0, len(c.Env))
+	for _, kv := range c.Env {
+		key, value, _ := strings.Cut(kv, "=")
+		vars = append(vars, KeyValue{Key: key, Value: value})
+	}
+	return vars
+}
+
+// LabelList returns the labels sorted by key.
+func (c *ImageConfig) LabelList() []KeyValue {
+	if c == nil {
+		return nil
+	}
+	labels := make([]KeyValue, 0, len(c.Labels))
+	for k, v := range c.Labels {
+		labels = append(labels, KeyValue{Key: k, Value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+	return labels
+}
+
+// RunUser returns the configured user, or "root" when none is set.
+func (c *ImageConfig) RunUser() string {
+	if c == nil || c.User == "" {
+		return "root"
+	}
+	return c.User
+}
+
+// Drift kinds reported when the Dockerfile documentation and the built image
+// disagree.
+const (
+	DriftMissing      = "missing"      // documented in the Dockerfile, absent from the image
+	DriftUndocumented = "undocumented" // present in the image, absent from the Dockerfile
+	DriftChanged      = "changed"      // present in both with different values
+)
+
+// DriftFinding is a single mismatch between the Dockerfile and the image config.
+type DriftFinding struct {
+	Kind        string `json:"kind"`                 // DriftMissing, DriftUndocumented or DriftChanged
+	Instruction string `json:"instruction"`          // "ENV", "EXPOSE" or "LABEL"
+	Name        string `json:"name"`                 // variable name, label key or port (e.g., "8080/tcp")
+	Dockerfile  string `json:"dockerfile,omitempty"` // value declared in the Dockerfile
+	Image       string `json:"image,omitempty"`      // value found in the image
+}
+
+// Message describes the finding in one sentence.
+func (f DriftFinding) Message() string {
+	switch f.Kind {
+	case DriftMissing:
+		return fmt.Sprintf("%s %s is declared in the Dockerfile but not set in the image", f.Instruction, f.Name)
+	case DriftUndocumented:
+		return fmt.Sprintf("%s %s is set in the image but not declared in the Dockerfile", f.Instruction, f.Name)
+	default:
+		return fmt.Sprintf("%s %s is %q in the Dockerfile but %q in the image", f.Instruction, f.Name, f.Dockerfile, f.Image)
+	}
+}
+
+// Lifecycle kinds and statuses recorded in LifecycleEntry.
+const (
+	LifecycleDistro  = "distro"
+	LifecycleRuntime = "runtime"
+
+	LifecycleSupported = "supported" // supported beyond the warning period
+	LifecycleEOLSoon   = "eol-soon"  // reaches end of life within the warning period
+	LifecycleEOL       = "eol"       // past end of life
+)
+
+// LifecycleEntry is the support status of the distribution or of a runtime
+// in an image.
+type LifecycleEntry struct {
+	Kind    string    `json:"kind"`    // LifecycleDistro or LifecycleRuntime
+	Name    string    `json:"name"`    // product name (e.g., "Alpine Linux")
+	Cycle   string    `json:"cycle"`   // release cycle (e.g., "3.15")
+	Version string    `json:"version"` // detected version (e.g., "3.15.4")
+	EOL     time.Time `json:"eol"`     // end of security support
+	Status  string    `json:"status"`  // LifecycleSupported, LifecycleEOLSoon or LifecycleEOL
+}
+
+// Message describes the entry, e.g. "Alpine Linux 3.15 — EOL since 2023-11-01".
+func (e LifecycleEntry) Message() string {
+	date := e.EOL.Format("2006-01-02")
+	switch e.Status {
+	case LifecycleEOL:
+		return fmt.Sprintf("%s %s — EOL since %s", e.Name, e.Cycle, date)
+	case LifecycleEOLSoon:
+		return fmt.Sprintf("%s %s — EOL on %s", e.Name, e.Cycle, date)
+	default:
+		return fmt.Sprintf("%s %s — supported until %s", e.Name, e.Cycle, date)
+	}
+}
+
+// Runner statuses recorded in RunnerStatus.Status.
+const (
+	RunnerOK          = "ok"          // ran and produced results
+	RunnerSkipped     = "skipped"     // disabled by configuration, or not covered by any imported report
+	RunnerUnavailable = "unavailable" // tool not installed or no container runtime
+	RunnerFailed      = "failed"      // ran and failed; Message holds the error
+)
+
+// RunnerStatus records the outcome of one analysis runner, so templates can
+// tell a genuine zero (e.g., no vulnerabilities) from a missing result.
+type RunnerStatus struct {
+	Name     string        `json:"name"`               // runner name ("runtime", "syft", "grype", "dive", ...)
+	Status   string        `json:"status"`             // RunnerOK, RunnerSkipped, RunnerUnavailable or RunnerFailed
+	Message  string        `json:"message,omitempty"`  // error or skip reason
+	Duration time.Duration `json:"duration,omitempty"` // wall-clock time, including waiting for a worker slot
+}
+
+// Summary returns the first non-blank line of Message, dropping the tool
+// stderr that follows it, so the reason fits on one line of a document.
+func (r RunnerStatus) Summary() string {
+	for _, line := range strings.Split(r.Message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
 }
 
-// ImageStats holds the dynamic analysis results
+// ImageStats holds the dynamic analysis results.
+// The JSON field names double as the wire format of the plugin protocol,
+// so plugins can return any subset of these fields.
 type ImageStats struct {
-	ImageTag               string
-	Architecture           string
-	SupportedArchitectures []string // from Manifest Inspect
-	OS                     string
-	OSDistro               string // from Syft (e.g., "Alpine Linux 3.18")
-	SizeBytes              int64
-	TotalLayers            int
-	Efficiency             float64 // from Dive (0-100)
-	WastedBytes            int64   // from Dive (raw bytes wasted by inefficient layers)
-	TotalPackages          int
-	Packages               []PackageSummary // from Syft (Key Frameworks only)
-	Vulnerabilities        []Vulnerability  // from Grype (Sorted by severity)
-	VulnSummary            map[string]int   // from Grype (Severity -> Count)
-	VulnScanTime           time.Time        // from Grype (When vulnerability scan was performed)
+	ImageTag               string           `json:"imageTag,omitempty"`
+	RepoDigest             string           `json:"repoDigest,omitempty"` // Pinned reference (e.g., "nginx@sha256:...")
+	ImageID                string           `json:"imageId,omitempty"`    // Local image ID / config digest ("sha256:...")
+	Created                time.Time        `json:"created,omitzero"`     // Image creation time
+	Architecture           string           `json:"architecture,omitempty"`
+	SupportedArchitectures []string         `json:"supportedArchitectures,omitempty"` // from Manifest Inspect
+	OS                     string           `json:"os,omitempty"`
+	OSDistro               string           `json:"osDistro,omitempty"` // from Syft (e.g., "Alpine Linux 3.18")
+	SizeBytes              int64            `json:"sizeBytes,omitempty"`
+	TotalLayers            int              `json:"totalLayers,omitempty"`
+	Efficiency             float64          `json:"efficiency,omitempty"`  // from Dive (0-100)
+	WastedBytes            int64            `json:"wastedBytes,omitempty"` // from Dive (raw bytes wasted by inefficient layers)
+	TotalPackages          int              `json:"totalPackages,omitempty"`
+	Packages               []PackageSummary `json:"packages,omitempty"`        // from Syft (all packages, deduplicated by name@version)
+	TechStack              []TechStackEntry `json:"techStack,omitempty"`       // Runtimes and frameworks detected from Packages
+	Vulnerabilities        []Vulnerability  `json:"vulnerabilities,omitempty"` // from Grype (Sorted by severity)
+	VulnSummary            map[string]int   `json:"vulnSummary,omitempty"`     // from Grype (Severity -> Count)
+	VulnScanTime           time.Time        `json:"vulnScanTime,omitzero"`     // from Grype (When vulnerability scan was performed)
+	LicenseSummary         []LicenseCount   `json:"licenseSummary,omitempty"`  // Aggregated from Packages (most common first)
+
+	// Lifecycle holds the support status of the distribution and runtimes in
+	// the image, from the lifecycle dataset.
+	Lifecycle []LifecycleEntry `json:"lifecycle,omitempty"`
+
+	// Runners records the outcome of every runner of the analysis, in runner
+	// order. For imported reports it records which analyses the reports
+	// cover. It is empty for results saved before provenance was recorded.
+	Runners []RunnerStatus `json:"runners,omitempty"`
+
+	// Suppressed holds findings removed from Vulnerabilities by suppression
+	// rules or VEX statements ("accepted risks").
+	Suppressed []SuppressedVulnerability `json:"suppressed,omitempty"`
+
+	// Policy is the security gate result, or nil when no policy is configured.
+	Policy *PolicyReport `json:"policy,omitempty"`
+
+	// Config is the runtime configuration recorded in the image (user,
+	// entrypoint, env, ports, ...), or nil when the image was not inspected.
+	Config *ImageConfig `json:"config,omitempty"`
+
+	// Drift lists mismatches between the Dockerfile documentation and Config.
+	// It is only populated when drift detection is configured.
+	Drift []DriftFinding `json:"drift,omitempty"`
+
+	// Custom holds arbitrary plugin-provided fields, keyed by plugin name.
+	// Templates can read them with {{ .Stats.CustomField "plugin" "key" }}.
+	Custom map[string]map[string]any `json:"custom,omitempty"`
+
+	// Platforms holds a separate analysis of every platform in a multi-arch
+	// manifest list, keyed by "os/arch[/variant]". It is only populated when
+	// per-platform analysis is enabled.
+	Platforms map[string]*ImageStats `json:"platforms,omitempty"`
+
+	// Base compares the image with the base image it is built from. It is
+	// only populated when base image comparison is enabled.
+	Base *BaseImageReport `json:"base,omitempty"`
+}
+
+// BaseImageReport splits an image's content into what it inherits from its
+// base image and what its own layers introduce.
+type BaseImageReport struct {
+	Image      string    `json:"image"`                // base image reference (e.g., "alpine:3.19")
+	RepoDigest string    `json:"repoDigest,omitempty"` // pinned reference of the analyzed base image
+	Created    time.Time `json:"created,omitzero"`     // base image creation time
+	// Stale reports that the image was not built from the current base
+	// image, or that the base image itself is old.
+	Stale      bool           `json:"stale,omitempty"`
+	Inherited  ContentSummary `json:"inherited"`
+	Introduced ContentSummary `json:"introduced"`
+	// IntroducedPackages lists the packages that are not in the base image.
+	IntroducedPackages []PackageSummary `json:"introducedPackages,omitempty"`
+	// Recommendations suggest how to update the base image, most important
+	// first.
+	Recommendations []string `json:"recommendations,omitempty"`
+}
+
+// ContentSummary sums up part of an image's content.
+type ContentSummary struct {
+	SizeBytes   int64          `json:"sizeBytes"`
+	Layers      int            `json:"layers"`
+	Packages    int            `json:"packages"`
+	VulnSummary map[string]int `json:"vulnSummary,omitempty"` // Severity -> Count
+}
+
+// SizeMB returns the size formatted as a human-readable string (e.g., "5.00 MB").
+func (c ContentSummary) SizeMB() string {
+	return fmt.Sprintf("%.2f MB", float64(c.SizeBytes)/1024/1024)
+}
+
+// TotalVulns returns the number of vulnerabilities across all severities.
+func (c ContentSummary) TotalVulns() int {
+	total := 0
+	for _, n := range c.VulnSummary {
+		total += n
+	}
+	return total
+}
+
+// PlatformEntry pairs a platform name with its analysis results.
+type PlatformEntry struct {
+	Platform string
+	Stats    *ImageStats
+}
+
+// PlatformList returns the per-platform results sorted by platform name.
+func (s *ImageStats) PlatformList() []PlatformEntry {
+	list := make([]PlatformEntry, 0, len(s.Platforms))
+	for p, stats := range s.Platforms {
+		list = append(list, PlatformEntry{Platform: p, Stats: stats})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Platform < list[j].Platform })
+	return list
+}
+
+// RunnerStatus returns the recorded status of the named runner, or nil.
+func (s *ImageStats) RunnerStatus(name string) *RunnerStatus {
+	for i := range s.Runners {
+		if s.Runners[i].Name == name {
+			return &s.Runners[i]
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of s, which callers may modify (e.g., apply
+// suppressions) without affecting other holders of s. The copy is made
+// through the JSON encoding, the same one used by saved results.
+func (s *ImageStats) Clone() (*ImageStats, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy analysis of %s: %w", s.ImageTag, err)
+	}
+	var c ImageStats
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to copy analysis of %s: %w", s.ImageTag, err)
+	}
+	return &c, nil
+}
+
+// Has reports whether the named runner's results are available. Results
+// without provenance (saved by older versions) are assumed to be complete.
+func (s *ImageStats) Has(runner string) bool {
+	if len(s.Runners) == 0 {
+		return true
+	}
+	st := s.RunnerStatus(runner)
+	return st != nil && st.Status == RunnerOK
+}
+
+// NA returns the placeholder shown instead of the values of a runner whose
+// results are missing (e.g., "n/a — dive unavailable").
+func (s *ImageStats) NA(runner string) string {
+	status := "not run"
+	if st := s.RunnerStatus(runner); st != nil {
+		status = st.Status
+	}
+	return "n/a — " + runner + " " + status
+}
+
+// MissingRunners returns the runners that did not produce results, in runner
+// order.
+func (s *ImageStats) MissingRunners() []RunnerStatus {
+	var missing []RunnerStatus
+	for _, r := range s.Runners {
+		if r.Status != RunnerOK {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// EOLWarnings returns the lifecycle entries that are past or near their end
+// of life.
+func (s *ImageStats) EOLWarnings() []LifecycleEntry {
+	var warnings []LifecycleEntry
+	for _, e := range s.Lifecycle {
+		if e.Status != LifecycleSupported {
+			warnings = append(warnings, e)
+		}
+	}
+	return warnings
+}
+
+// ShortImageID returns the first 12 hex characters of ImageID, as shown by
+// 'docker images'.
+func (s *ImageStats) ShortImageID() string {
+	id := strings.TrimPrefix(s.ImageID, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// CustomField returns a custom field reported by the named plugin,
+// or nil if the plugin did not report it.
+func (s *ImageStats) CustomField(plugin, key string) any {
+	return s.Custom[plugin][key]
+}
+
+// PackagesByTier returns the packages classified in the given tier.
+func (s *ImageStats) PackagesByTier(tier string) []PackageSummary {
+	var pkgs []PackageSummary
+	for _, p := range s.Packages {
+		if p.Tier == tier {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+// TierCounts returns the number of packages in each tier.
+func (s *ImageStats) TierCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, p := range s.Packages {
+		if p.Tier != "" {
+			counts[p.Tier]++
+		}
+	}
+	return counts
+}
+
+// DeniedLicenses returns the license summary entries flagged by the denylist.
+func (s *ImageStats) DeniedLicenses() []LicenseCount {
+	var denied []LicenseCount
+	for _, l := range s.LicenseSummary {
+		if l.Denied {
+			denied = append(denied, l)
+		}
+	}
+	return denied
+}
+
+// PackagesWithLicense returns the packages that declare the given license.
+func (s *ImageStats) PackagesWithLicense(license string) []PackageSummary {
+	var pkgs []PackageSummary
+	for _, p := range s.Packages {
+		for _, l := range p.Licenses {
+			if l == license {
+				pkgs = append(pkgs, p)
+				break
+			}
+		}
+	}
+	return pkgs
 }
 
 // SizeMB returns the image size formatted as a human-readable string (e.g., "7.60 MB").
@@ -72,6 +603,31 @@ func (s *ImageStats) SizeBadge(baseURL string) string {
 	return fmt.Sprintf("%s?label=Size&message=%s&color=blue", baseURL, url.QueryEscape(sizeMB))
 }
 
+// LifecycleBadge returns a shields.io badge URL for the support status of the
+// image's distribution and runtimes: the first end-of-life entry in red, else
+// the first entry nearing end of life in orange, else "supported" in green.
+// It returns "" when no lifecycle data is available.
+func (s *ImageStats) LifecycleBadge(baseURL string) string {
+	if len(s.Lifecycle) == 0 {
+		return ""
+	}
+	msg, color := "supported", "green"
+	for _, status := range []string{LifecycleEOL, LifecycleEOLSoon} {
+		i := slices.IndexFunc(s.Lifecycle, func(e LifecycleEntry) bool { return e.Status == status })
+		if i < 0 {
+			continue
+		}
+		e := s.Lifecycle[i]
+		if status == LifecycleEOL {
+			msg, color = fmt.Sprintf("%s %s EOL", e.Name, e.Cycle), "red"
+		} else {
+			msg, color = fmt.Sprintf("%s %s EOL %s", e.Name, e.Cycle, e.EOL.Format("2006-01-02")), "orange"
+		}
+		break
+	}
+	return fmt.Sprintf("%s?label=Lifecycle&message=%s&color=%s", baseURL, url.QueryEscape(msg), color)
+}
+
 // LayersBadge returns a shields.io badge URL for the layer count.
 func (s *ImageStats) LayersBadge(baseURL string) string {
 	if s.TotalLayers == 0 {
@@ -82,6 +638,9 @@ func (s *ImageStats) LayersBadge(baseURL string) string {
 
 // EfficiencyBadge returns a shields.io badge URL for the image efficiency score.
 func (s *ImageStats) EfficiencyBadge(baseURL string) string {
+	if !s.Has("dive") {
+		return fmt.Sprintf("%s?label=Efficiency&message=n%%2Fa&color=lightgrey", baseURL)
+	}
 	if s.Efficiency == 0 {
 		return ""
 	}
@@ -99,6 +658,9 @@ func (s *ImageStats) EfficiencyBadge(baseURL string) string {
 // The total uses TotalVulns() (which includes all severities, including Unknown)
 // for consistency with the Vulnerabilities slice.
 func (s *ImageStats) VulnBadge(baseURL string) string {
+	if !s.Has("grype") {
+		return fmt.Sprintf("%s?label=Security&message=n%%2Fa&color=lightgrey", baseURL)
+	}
 	critical := s.VulnSummary["Critical"]
 	high := s.VulnSummary["High"]
 	total := s.TotalVulns()
@@ -119,6 +681,30 @@ func (s *ImageStats) TotalVulns() int {
 	return len(s.Vulnerabilities)
 }
 
+// FixableVulns returns the number of vulnerabilities that can be fixed now
+// by upgrading the affected package.
+func (s *ImageStats) FixableVulns() int {
+	count := 0
+	for _, v := range s.Vulnerabilities {
+		if v.Fixable() {
+			count++
+		}
+	}
+	return count
+}
+
+// FixableBySeverity returns the number of fixable vulnerabilities with the
+// given severity (e.g., "Critical").
+func (s *ImageStats) FixableBySeverity(severity string) int {
+	count := 0
+	for _, v := range s.Vulnerabilities {
+		if v.Severity == severity && v.Fixable() {
+			count++
+		}
+	}
+	return count
+}
+
 // severityRank maps vulnerability severity strings to numeric ranks for sorting.
 // Higher values indicate more severe vulnerabilities.
 var severityRank = map[string]int{
@@ -129,8 +715,9 @@ var severityRank = map[string]int{
 	"Unknown":  0,
 }
 
-// SortBySeverity sorts a slice of vulnerabilities by severity (Critical first)
-// with a secondary sort by ID for deterministic ordering.
+// SortBySeverity sorts a slice of vulnerabilities by severity (Critical first),
+// then by CVSS score (highest first) within a severity, with a final sort by ID
+// for deterministic ordering.
 func SortBySeverity(vulns []Vulnerability) {
 	sort.Slice(vulns, func(i, j int) bool {
 		rankI := severityRank[vulns[i].Severity]
@@ -138,6 +725,9 @@ func SortBySeverity(vulns []Vulnerability) {
 		if rankI != rankJ {
 			return rankI > rankJ
 		}
+		if vulns[i].CVSSScore != vulns[j].CVSSScore {
+			return vulns[i].CVSSScore > vulns[j].CVSSScore
+		}
 		return vulns[i].ID < vulns[j].ID
 	})
 }
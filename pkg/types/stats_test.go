@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestImageStats_SizeBadge(t *testing.T) {
@@ -152,6 +153,12 @@ func TestImageStats_EfficiencyBadge(t *testing.T) {
 			stats:   &ImageStats{Efficiency: 0},
 			wantURL: false,
 		},
+		{
+			name:     "dive unavailable",
+			stats:    &ImageStats{Runners: []RunnerStatus{{Name: "dive", Status: RunnerUnavailable}}},
+			wantURL:  true,
+			contains: []string{"label=Efficiency", "message=n%2Fa", "color=lightgrey"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -475,6 +482,16 @@ func TestSortBySeverity(t *testing.T) {
 			},
 			wantIDs: []string{"CVE-CRIT-1", "CVE-LOW-1", "CVE-UNK-1"},
 		},
+		{
+			name: "same severity sorted by CVSS score before ID",
+			vulns: []Vulnerability{
+				{ID: "CVE-A", Severity: "High", CVSSScore: 7.1},
+				{ID: "CVE-B", Severity: "High", CVSSScore: 8.8},
+				{ID: "CVE-C", Severity: "High"},
+				{ID: "CVE-D", Severity: "Critical", CVSSScore: 9.1},
+			},
+			wantIDs: []string{"CVE-D", "CVE-B", "CVE-A", "CVE-C"},
+		},
 		{
 			name:    "empty slice",
 			vulns:   []Vulnerability{},
@@ -588,3 +605,345 @@ func TestImageStats_VulnBadge_IncludesUnknown(t *testing.T) {
 		t.Errorf("VulnBadge() should be green (no Critical/High), got: %v", badge)
 	}
 }
+
+func TestImageStats_CustomField(t *testing.T) {
+	s := &ImageStats{
+		Custom: map[string]map[string]any{
+			"licenses": {"approved": true},
+		},
+	}
+	if got := s.CustomField("licenses", "approved"); got != true {
+		t.Errorf("CustomField(licenses, approved) = %v, want true", got)
+	}
+	if got := s.CustomField("licenses", "missing"); got != nil {
+		t.Errorf("CustomField(licenses, missing) = %v, want nil", got)
+	}
+	if got := s.CustomField("other", "approved"); got != nil {
+		t.Errorf("CustomField(other, approved) = %v, want nil", got)
+	}
+	if got := (&ImageStats{}).CustomField("licenses", "approved"); got != nil {
+		t.Errorf("CustomField on empty stats = %v, want nil", got)
+	}
+}
+
+func TestVulnerability_Fixable(t *testing.T) {
+	tests := []struct {
+		name string
+		vuln Vulnerability
+		want bool
+	}{
+		{name: "fixed with version", vuln: Vulnerability{FixState: FixStateFixed, FixedInVersions: []string{"1.2.3"}}, want: true},
+		{name: "fixed without version", vuln: Vulnerability{FixState: FixStateFixed}, want: false},
+		{name: "not fixed", vuln: Vulnerability{FixState: FixStateNotFixed}, want: false},
+		{name: "wont fix", vuln: Vulnerability{FixState: FixStateWontFix, FixedInVersions: []string{"9.9"}}, want: false},
+		{name: "no fix data", vuln: Vulnerability{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.vuln.Fixable(); got != tt.want {
+				t.Errorf("Fixable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVulnerability_URL(t *testing.T) {
+	tests := []struct {
+		name string
+		vuln Vulnerability
+		want string
+	}{
+		{name: "data source wins", vuln: Vulnerability{ID: "CVE-2024-1", DataSource: "https://example.com/CVE-2024-1"}, want: "https://example.com/CVE-2024-1"},
+		{name: "CVE falls back to NVD", vuln: Vulnerability{ID: "CVE-2024-1"}, want: "https://nvd.nist.gov/vuln/detail/CVE-2024-1"},
+		{name: "GHSA falls back to GitHub", vuln: Vulnerability{ID: "GHSA-xxxx-yyyy-zzzz"}, want: "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz"},
+		{name: "other IDs have no link", vuln: Vulnerability{ID: "ALAS2-2024-001"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.vuln.URL(); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageStats_FixableVulns(t *testing.T) {
+	s := &ImageStats{
+		Vulnerabilities: []Vulnerability{
+			{ID: "1", Severity: "Critical", FixState: FixStateFixed, FixedInVersions: []string{"2.0"}},
+			{ID: "2", Severity: "Critical", FixState: FixStateNotFixed},
+			{ID: "3", Severity: "High", FixState: FixStateFixed, FixedInVersions: []string{"1.1"}},
+			{ID: "4", Severity: "Low"},
+		},
+	}
+	if got := s.FixableVulns(); got != 2 {
+		t.Errorf("FixableVulns() = %d, want 2", got)
+	}
+	if got := s.FixableBySeverity("Critical"); got != 1 {
+		t.Errorf("FixableBySeverity(Critical) = %d, want 1", got)
+	}
+	if got := s.FixableBySeverity("Low"); got != 0 {
+		t.Errorf("FixableBySeverity(Low) = %d, want 0", got)
+	}
+}
+
+func TestSuppressedVulnerability_ExpiresDate(t *testing.T) {
+	if got := (SuppressedVulnerability{}).ExpiresDate(); got != "never" {
+		t.Errorf("ExpiresDate() = %q, want \"never\"", got)
+	}
+	s := SuppressedVulnerability{Expires: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)}
+	if got := s.ExpiresDate(); got != "2025-12-31" {
+		t.Errorf("ExpiresDate() = %q, want \"2025-12-31\"", got)
+	}
+}
+
+func TestPolicyReport(t *testing.T) {
+	r := &PolicyReport{
+		Passed: false,
+		Checks: []PolicyCheck{
+			{Rule: "maxCritical", Passed: false},
+			{Rule: "maxHigh", Passed: true},
+		},
+	}
+	if r.Status() != "FAIL" {
+		t.Errorf("Status() = %q, want FAIL", r.Status())
+	}
+	if v := r.Violations(); len(v) != 1 || v[0].Rule != "maxCritical" {
+		t.Errorf("Violations() = %+v, want [maxCritical]", v)
+	}
+	if (&PolicyReport{Passed: true}).Status() != "PASS" {
+		t.Error("expected PASS for passed report")
+	}
+}
+
+func TestImageStats_Licenses(t *testing.T) {
+	s := &ImageStats{
+		Packages: []PackageSummary{
+			{Name: "a", Licenses: []string{"MIT"}},
+			{Name: "b", Licenses: []string{"AGPL-3.0-only", "MIT"}},
+			{Name: "c"},
+		},
+		LicenseSummary: []LicenseCount{
+			{License: "MIT", Packages: 2},
+			{License: "AGPL-3.0-only", Packages: 1, Denied: true},
+		},
+	}
+	if d := s.DeniedLicenses(); len(d) != 1 || d[0].License != "AGPL-3.0-only" {
+		t.Errorf("DeniedLicenses() = %+v, want [AGPL-3.0-only]", d)
+	}
+	if p := s.PackagesWithLicense("MIT"); len(p) != 2 || p[0].Name != "a" || p[1].Name != "b" {
+		t.Errorf("PackagesWithLicense(MIT) = %+v, want [a b]", p)
+	}
+	if p := s.PackagesWithLicense("BSD-3-Clause"); len(p) != 0 {
+		t.Errorf("PackagesWithLicense(BSD-3-Clause) = %+v, want none", p)
+	}
+}
+
+func TestImageStats_Tiers(t *testing.T) {
+	s := &ImageStats{
+		Packages: []PackageSummary{
+			{Name: "musl", Tier: TierOS},
+			{Name: "openjdk17-jre", Tier: TierRuntime},
+			{Name: "busybox", Tier: TierOS},
+			{Name: "unclassified"},
+		},
+	}
+	if p := s.PackagesByTier(TierOS); len(p) != 2 || p[0].Name != "musl" || p[1].Name != "busybox" {
+		t.Errorf("PackagesByTier(os) = %+v, want [musl busybox]", p)
+	}
+	counts := s.TierCounts()
+	if counts[TierOS] != 2 || counts[TierRuntime] != 1 || len(counts) != 2 {
+		t.Errorf("TierCounts() = %v, want os=2 runtime=1", counts)
+	}
+}
+
+func TestImageConfig(t *testing.T) {
+	var nilCfg *ImageConfig
+	if nilCfg.RunUser() != "root" || nilCfg.EnvVars() != nil || nilCfg.LabelList() != nil {
+		t.Error("nil config should report root user and no env or labels")
+	}
+
+	c := &ImageConfig{
+		User:   "1000:1000",
+		Env:    []string{"PATH=/usr/bin", "EMPTY=", "OPTS=a=b"},
+		Labels: map[string]string{"version": "1.0", "maintainer": "me"},
+	}
+	if c.RunUser() != "1000:1000" {
+		t.Errorf("RunUser() = %q", c.RunUser())
+	}
+	env := c.EnvVars()
+	if len(env) != 3 || env[2] != (KeyValue{Key: "OPTS", Value: "a=b"}) || env[1].Value != "" {
+		t.Errorf("EnvVars() = %+v", env)
+	}
+	if labels := c.LabelList(); len(labels) != 2 || labels[0].Key != "maintainer" {
+		t.Errorf("LabelList() = %+v, want sorted by key", labels)
+	}
+}
+
+func TestHealthcheck_Command(t *testing.T) {
+	tests := []struct {
+		test []string
+		want string
+	}{
+		{[]string{"CMD-SHELL", "curl -f http://localhost/ || exit 1"}, "curl -f http://localhost/ || exit 1"},
+		{[]string{"CMD", "/bin/check", "--quick"}, "/bin/check --quick"},
+		{[]string{"NONE"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		h := &Healthcheck{Test: tt.test}
+		if got := h.Command(); got != tt.want {
+			t.Errorf("Command(%v) = %q, want %q", tt.test, got, tt.want)
+		}
+	}
+}
+
+func TestImageStats_ShortImageID(t *testing.T) {
+	tests := map[string]string{
+		"sha256:0123456789abcdef": "0123456789ab",
+		"0123456789abcdef":        "0123456789ab",
+		"sha256:abc":              "abc",
+		"":                        "",
+	}
+	for id, want := range tests {
+		s := &ImageStats{ImageID: id}
+		if got := s.ShortImageID(); got != want {
+			t.Errorf("ShortImageID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestDriftFinding_Message(t *testing.T) {
+	tests := []struct {
+		f    DriftFinding
+		want string
+	}{
+		{DriftFinding{Kind: DriftMissing, Instruction: "ENV", Name: "A"}, "ENV A is declared in the Dockerfile but not set in the image"},
+		{DriftFinding{Kind: DriftUndocumented, Instruction: "EXPOSE", Name: "9090/tcp"}, "EXPOSE 9090/tcp is set in the image but not declared in the Dockerfile"},
+		{DriftFinding{Kind: DriftChanged, Instruction: "LABEL", Name: "v", Dockerfile: "1", Image: "2"}, `LABEL v is "1" in the Dockerfile but "2" in the image`},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Message(); got != tt.want {
+			t.Errorf("Message() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestImageStats_Runners(t *testing.T) {
+	stats := &ImageStats{Runners: []RunnerStatus{
+		{Name: "syft", Status: RunnerOK},
+		{Name: "grype", Status: RunnerFailed, Message: "db update failed"},
+		{Name: "dive", Status: RunnerUnavailable, Message: "not installed"},
+	}}
+
+	tests := []struct {
+		runner string
+		has    bool
+		na     string
+	}{
+		{"syft", true, "n/a — syft ok"},
+		{"grype", false, "n/a — grype failed"},
+		{"dive", false, "n/a — dive unavailable"},
+		{"trivy", false, "n/a — trivy not run"},
+	}
+	for _, tt := range tests {
+		if got := stats.Has(tt.runner); got != tt.has {
+			t.Errorf("Has(%q) = %v, want %v", tt.runner, got, tt.has)
+		}
+		if got := stats.NA(tt.runner); got != tt.na {
+			t.Errorf("NA(%q) = %q, want %q", tt.runner, got, tt.na)
+		}
+	}
+
+	missing := stats.MissingRunners()
+	if len(missing) != 2 || missing[0].Name != "grype" || missing[1].Name != "dive" {
+		t.Errorf("MissingRunners() = %+v, want grype and dive", missing)
+	}
+	if !strings.Contains(stats.VulnBadge("https://img.shields.io/static/v1"), "message=n%2Fa") {
+		t.Error("VulnBadge() should be n/a when grype failed")
+	}
+
+	failed := RunnerStatus{Message: "\ncommand failed: exit status 1\nStderr: boom\n"}
+	if got := failed.Summary(); got != "command failed: exit status 1" {
+		t.Errorf("Summary() = %q, want the first line of the message", got)
+	}
+
+	// Results without provenance are assumed to be complete.
+	legacy := &ImageStats{}
+	if !legacy.Has("dive") || legacy.MissingRunners() != nil {
+		t.Error("results without runner statuses should report every runner as available")
+	}
+}
+
+func TestImageStats_Lifecycle(t *testing.T) {
+	baseURL := "https://img.shields.io/static/v1"
+	alpine := LifecycleEntry{Kind: LifecycleDistro, Name: "Alpine Linux", Cycle: "3.15",
+		EOL: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), Status: LifecycleEOL}
+	node := LifecycleEntry{Kind: LifecycleRuntime, Name: "Node.js", Cycle: "20",
+		EOL: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), Status: LifecycleEOLSoon}
+	python := LifecycleEntry{Kind: LifecycleRuntime, Name: "Python", Cycle: "3.12",
+		EOL: time.Date(2028, 10, 31, 0, 0, 0, 0, time.UTC), Status: LifecycleSupported}
+
+	messages := map[LifecycleEntry]string{
+		alpine: "Alpine Linux 3.15 — EOL since 2023-11-01",
+		node:   "Node.js 20 — EOL on 2026-04-30",
+		python: "Python 3.12 — supported until 2028-10-31",
+	}
+	for e, want := range messages {
+		if got := e.Message(); got != want {
+			t.Errorf("Message() = %q, want %q", got, want)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		entries   []LifecycleEntry
+		warnings  int
+		wantBadge string
+	}{
+		{"no data", nil, 0, ""},
+		{"supported", []LifecycleEntry{python}, 0, "message=supported&color=green"},
+		{"eol soon", []LifecycleEntry{python, node}, 1, "message=Node.js+20+EOL+2026-04-30&color=orange"},
+		{"eol first", []LifecycleEntry{node, python, alpine}, 2, "message=Alpine+Linux+3.15+EOL&color=red"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &ImageStats{Lifecycle: tt.entries}
+			if got := len(stats.EOLWarnings()); got != tt.warnings {
+				t.Errorf("EOLWarnings() = %d entries, want %d", got, tt.warnings)
+			}
+			got := stats.LifecycleBadge(baseURL)
+			if tt.wantBadge == "" {
+				if got != "" {
+					t.Errorf("LifecycleBadge() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, "label=Lifecycle&"+tt.wantBadge) {
+				t.Errorf("LifecycleBadge() = %q, want %q", got, tt.wantBadge)
+			}
+		})
+	}
+}
+
+func TestImageStats_Clone(t *testing.T) {
+	stats := &ImageStats{
+		ImageTag:        "app:1",
+		VulnSummary:     map[string]int{"High": 1},
+		Vulnerabilities: []Vulnerability{{ID: "CVE-1", Severity: "High"}},
+		Runners:         []RunnerStatus{{Name: "grype", Status: RunnerOK}},
+	}
+	c, err := stats.Clone()
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if c.ImageTag != "app:1" || c.VulnSummary["High"] != 1 || len(c.Vulnerabilities) != 1 || !c.Has("grype") {
+		t.Fatalf("Clone() = %+v, want a copy of every field", c)
+	}
+
+	c.VulnSummary["High"] = 0
+	c.Vulnerabilities[0].ID = "CVE-2"
+	if stats.VulnSummary["High"] != 1 || stats.Vulnerabilities[0].ID != "CVE-1" {
+		t.Error("modifying the clone should not affect the original")
+	}
+}